@@ -17,7 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
@@ -26,19 +28,78 @@ import (
 // MigControllerSpec defines the desired state of MigController.
 type MigControllerSpec struct {
 	// PriorityClass of the control plane
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Priority Class",xDescriptors={"urn:alm:descriptor:text"}
 	PriorityClass *MigControllerPriorityClass `json:"priorityClass,omitempty"`
 	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
 	// PullPolicy describes a policy for if/when to pull a container image
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image Pull Policy",xDescriptors={"urn:alm:descriptor:io.kubernetes:imagePullPolicy"}
 	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty" valid:"required"`
 	// Rules on which nodes infrastructure pods will be scheduled
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Infrastructure Placement",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
 	Infra sdkapi.NodePlacement `json:"infra,omitempty"`
 	// TLSSecurityProfile is used by operators to apply cluster-wide TLS security settings to operands.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="TLS Security Profile",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
 	TLSSecurityProfile *TLSSecurityProfile `json:"tlsSecurityProfile,omitempty"`
+	// UpgradeStrategy configures how operand upgrades are supervised
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Upgrade Strategy",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	UpgradeStrategy *MigControllerUpgradeStrategy `json:"upgradeStrategy,omitempty"`
+	// RolloutStrategy configures how changes to the controller pods are rolled out
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Rollout Strategy",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	RolloutStrategy *MigControllerRolloutStrategy `json:"rolloutStrategy,omitempty"`
+	// Paused stops the operator from creating, updating and deleting operand resources while status keeps being reported
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Paused",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Paused bool `json:"paused,omitempty"`
+	// WatchNamespaces restricts the controller to the listed namespaces, together with the ones matching NamespaceSelector
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Watch Namespaces",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+	// NamespaceSelector restricts the controller to the matching namespaces, together with the ones in WatchNamespaces
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespace Selector",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:selector:core:v1:Namespace","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// RBAC configures which tenants may run storage migrations
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="RBAC",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	RBAC *MigControllerRBAC `json:"rbac,omitempty"`
+	// PlanPolicies constrains the storage migration plans tenants can create, enforced with ValidatingAdmissionPolicies
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Plan Policies",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	PlanPolicies *MigControllerPlanPolicies `json:"planPolicies,omitempty"`
+	// Quota limits how many storage migrations, single and multi namespace, may run at the same time, enforced by the operator webhook
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Quota",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	Quota *MigControllerQuota `json:"quota,omitempty"`
+	// PlanDefaults are filled into storage migration plans that leave them unset, unless their namespace
+	// sets its own defaults with the DefaultStorageClassAnnotation annotation
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Plan Defaults",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	PlanDefaults *MigControllerPlanDefaults `json:"planDefaults,omitempty"`
+	// History configures the garbage collection of finished storage migrations, they are kept forever when unset
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="History",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced"}
+	History *MigControllerHistory `json:"history,omitempty"`
 }
 
 // MigControllerStatus defines the observed state of MigController.
 type MigControllerStatus struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status,path=phase,displayName="Phase",description="The deployment phase.",xDescriptors={"urn:alm:descriptor:io.kubernetes.phase"}
+	// +operator-sdk:csv:customresourcedefinitions:type=status,path=conditions,displayName="Conditions",description="Explanation for the current status of the kubevirt migration controller deployment.",xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	// +operator-sdk:csv:customresourcedefinitions:type=status,path=operatorVersion,displayName="kubevirt migration controller Operator Version",description="The version of the kubevirt migration controller Operator",xDescriptors={"urn:alm:descriptor:text"}
+	// +operator-sdk:csv:customresourcedefinitions:type=status,path=targetVersion,displayName="Target kubevirt migration controller Version",description="The targeted version of the kubevirt migration controller deployment.",xDescriptors={"urn:alm:descriptor:text"}
+	// +operator-sdk:csv:customresourcedefinitions:type=status,path=observedVersion,displayName="Observed kubevirt migration controller Version",description="The observed version of the kubevirt migration controller deployment.",xDescriptors={"urn:alm:descriptor:text"}
 	sdkapi.Status `json:",inline"`
+	// UpgradeHistory records the most recent operand upgrades, newest first
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Upgrade History"
+	UpgradeHistory []MigControllerUpgradeHistory `json:"upgradeHistory,omitempty"`
+	// UnmanagedResources lists the operand resources that are fully or partially excluded from reconciliation
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Unmanaged Resources"
+	UnmanagedResources []MigControllerUnmanagedResource `json:"unmanagedResources,omitempty"`
+	// Drift lists the changes reconciliation would make to the operand, reported while reconciliation is paused
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Drift"
+	Drift []MigControllerResourceDrift `json:"drift,omitempty"`
+	// WatchedNamespaces lists the namespaces the controller is restricted to, empty when it watches all namespaces
+	// or, with the NoWatchedNamespaces condition, none
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Watched Namespaces"
+	WatchedNamespaces []string `json:"watchedNamespaces,omitempty"`
+	// ActivePolicies lists the ValidatingAdmissionPolicies enforcing the plan policies
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Active Policies"
+	ActivePolicies []string `json:"activePolicies,omitempty"`
+	// QuotaUsage reports the running storage migrations counted against the quota
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Quota Usage"
+	QuotaUsage *MigControllerQuotaUsage `json:"quotaUsage,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -66,6 +127,215 @@ type MigControllerList struct {
 // MigControllerPriorityClass defines the priority class of the control plane.
 type MigControllerPriorityClass string
 
+// MigControllerUpgradeStrategy defines how operand upgrades are supervised.
+type MigControllerUpgradeStrategy struct {
+	// Timeout is how long an operand upgrade may take before it is considered failed, no limit when unset
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// AutoRollback restores the previously applied operand when an upgrade fails
+	AutoRollback bool `json:"autoRollback,omitempty"`
+}
+
+// MigControllerRBAC defines which tenants may run storage migrations.
+type MigControllerRBAC struct {
+	// AggregateToNamespaceRoles lets namespace admins and editors run single namespace storage migrations through the aggregated admin and edit roles
+	AggregateToNamespaceRoles bool `json:"aggregateToNamespaceRoles,omitempty"`
+	// StorageMigrators are bound cluster-wide to the role allowing single namespace storage migrations
+	StorageMigrators []rbacv1.Subject `json:"storageMigrators,omitempty"`
+	// MultiNamespaceStorageMigrators are bound cluster-wide to the role allowing multi namespace storage migrations
+	MultiNamespaceStorageMigrators []rbacv1.Subject `json:"multiNamespaceStorageMigrators,omitempty"`
+}
+
+// MigControllerPlanPolicies defines the constraints enforced on storage migration plans when they are created.
+type MigControllerPlanPolicies struct {
+	// StorageClasses restricts the target storage classes of plans migrating in the namespaces selected by each entry
+	StorageClasses []MigControllerStorageClassPolicy `json:"storageClasses,omitempty"`
+	// RestrictDeleteSource forbids the deleteSource retention policy in plans unless their namespaces are labeled with AllowDeleteSourceLabel set to "true"
+	RestrictDeleteSource bool `json:"restrictDeleteSource,omitempty"`
+	// MaxVirtualMachinesPerPlan caps the number of virtual machines a single plan can migrate
+	// +kubebuilder:validation:Minimum=1
+	MaxVirtualMachinesPerPlan *int32 `json:"maxVirtualMachinesPerPlan,omitempty"`
+}
+
+// MigControllerStorageClassPolicy restricts the target storage classes of plans in the selected namespaces.
+// The destination PVCs of these plans have to name their storage class, the default one is not resolved.
+type MigControllerStorageClassPolicy struct {
+	// Name identifies the policy, it is part of the name of the rendered ValidatingAdmissionPolicy
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+	// NamespaceSelector selects the namespaces the policy applies to, all namespaces when unset
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Allowed lists the only storage classes plans may target, any storage class when empty
+	Allowed []string `json:"allowed,omitempty"`
+	// Denied lists the storage classes plans may not target
+	Denied []string `json:"denied,omitempty"`
+}
+
+// MigControllerHistoryArchive defines where the final status of collected storage migrations is archived.
+type MigControllerHistoryArchive string
+
+const (
+	// MigControllerHistoryArchiveLog writes the final status to the operator log
+	MigControllerHistoryArchiveLog MigControllerHistoryArchive = "Log"
+	// MigControllerHistoryArchiveConfigMap stores the final status in the kubevirt-migration-history ConfigMap
+	// in the operator namespace, the oldest entries are dropped when it grows too large
+	MigControllerHistoryArchiveConfigMap MigControllerHistoryArchive = "ConfigMap"
+)
+
+// MigControllerHistory defines how long finished storage migrations are kept.
+type MigControllerHistory struct {
+	// TTLAfterFinished is how long finished storage migrations are kept, no limit when unset
+	TTLAfterFinished *metav1.Duration `json:"ttlAfterFinished,omitempty"`
+	// LimitPerPlan is how many finished storage migrations are kept per plan, no limit when unset
+	// +kubebuilder:validation:Minimum=0
+	LimitPerPlan *int32 `json:"limitPerPlan,omitempty"`
+	// Archive records the final status of the storage migrations before they are deleted, not recorded when unset
+	// +kubebuilder:validation:Enum=Log;ConfigMap
+	Archive MigControllerHistoryArchive `json:"archive,omitempty"`
+}
+
+// MigControllerPlanDefaults defines the values filled into storage migration plans that leave them unset.
+// The retention policy is not defaulted, the plan schemas already default it to keepSource.
+type MigControllerPlanDefaults struct {
+	// StorageClassName is the target storage class of the destination PVCs without one
+	StorageClassName string `json:"storageClassName,omitempty"`
+}
+
+// MigControllerQuota defines how many storage migrations may run at the same time. Both the single and the
+// multi namespace storage migrations count, a multi namespace one once in the namespace it is created in.
+type MigControllerQuota struct {
+	// MaxRunningPerNamespace caps the storage migrations running at the same time in a single namespace
+	// +kubebuilder:validation:Minimum=1
+	MaxRunningPerNamespace *int32 `json:"maxRunningPerNamespace,omitempty"`
+	// MaxRunning caps the storage migrations running at the same time in the cluster
+	// +kubebuilder:validation:Minimum=1
+	MaxRunning *int32 `json:"maxRunning,omitempty"`
+}
+
+// MigControllerQuotaUsage defines the running storage migrations counted against the quota.
+type MigControllerQuotaUsage struct {
+	// Running is the number of storage migrations running in the cluster
+	Running int32 `json:"running"`
+	// Namespaces lists the number of running storage migrations per namespace, namespaces without any are omitted
+	Namespaces []MigControllerNamespaceQuotaUsage `json:"namespaces,omitempty"`
+}
+
+// MigControllerNamespaceQuotaUsage defines the running storage migrations of a namespace.
+type MigControllerNamespaceQuotaUsage struct {
+	// Namespace is the name of the namespace
+	Namespace string `json:"namespace"`
+	// Running is the number of storage migrations running in the namespace
+	Running int32 `json:"running"`
+}
+
+// MigControllerRolloutStrategy defines how changes to the controller pods are rolled out.
+type MigControllerRolloutStrategy struct {
+	// MaxDeferral is how long a controller rollout may be held back while storage migrations are running, 6h when unset and zero disables deferral
+	MaxDeferral *metav1.Duration `json:"maxDeferral,omitempty"`
+}
+
+const (
+	// ConditionRolloutPending is true while changes to the controller pods are held back
+	ConditionRolloutPending conditionsv1.ConditionType = "RolloutPending"
+	// ConditionReconciliationPaused is true while changes to the operand are not applied
+	ConditionReconciliationPaused conditionsv1.ConditionType = "ReconciliationPaused"
+	// ConditionClusterResourcesVerified reports whether the pre-installed cluster resources match the expected ones,
+	// it is only set when cluster resources are not deployed by the operator
+	ConditionClusterResourcesVerified conditionsv1.ConditionType = "ClusterResourcesVerified"
+	// ConditionNoWatchedNamespaces is true while the namespaces the controller is restricted to match none,
+	// the controller is not run then
+	ConditionNoWatchedNamespaces conditionsv1.ConditionType = "NoWatchedNamespaces"
+
+	// ForceRolloutAnnotation on the MigController set to "true" rolls out held back changes right away
+	ForceRolloutAnnotation = "migrations.kubevirt.io/force-rollout"
+
+	// PauseAnnotation on the MigController set to "true" pauses reconciliation like spec.paused does,
+	// the changes that would be made are reported in the drift status instead
+	PauseAnnotation = "migrations.kubevirt.io/paused"
+
+	// AllowDeleteSourceLabel on a namespace set to "true" opts it in to plans deleting the source volumes
+	// when spec.planPolicies.restrictDeleteSource is set
+	AllowDeleteSourceLabel = "migrations.kubevirt.io/allow-delete-source"
+
+	// DefaultStorageClassAnnotation on a namespace sets the target storage class of the destination PVCs
+	// without one in the plans created there, overriding spec.planDefaults.storageClassName
+	DefaultStorageClassAnnotation = "migrations.kubevirt.io/default-storage-class"
+	// AppliedDefaultsAnnotation is set on plans the webhook filled defaults into, to the JSON array
+	// of the JSON pointers (RFC 6901) of the defaulted fields
+	AppliedDefaultsAnnotation = "migrations.kubevirt.io/applied-defaults"
+	// PreflightDryRunAnnotation on a plan set to "true" makes the operator evaluate the plan against the cluster
+	// state and reject it, returning the findings of the pre-flight report as warnings, so the plan never runs
+	PreflightDryRunAnnotation = "migrations.kubevirt.io/preflight-dry-run"
+)
+
+// MigControllerUnmanagedResource is an operand resource excluded from reconciliation by annotation.
+type MigControllerUnmanagedResource struct {
+	// Kind of the resource
+	Kind string `json:"kind"`
+	// Namespace of the resource, empty for cluster scoped resources
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the resource
+	Name string `json:"name"`
+	// Unmanaged is true when the resource is not reconciled at all
+	Unmanaged bool `json:"unmanaged,omitempty"`
+	// IgnoredFields are JSON pointers to the fields of the resource that are not reconciled
+	IgnoredFields []string `json:"ignoredFields,omitempty"`
+}
+
+// MigControllerDriftOperation is the change reconciliation would make to an operand resource.
+// +kubebuilder:validation:Enum=Create;Update;Delete
+type MigControllerDriftOperation string
+
+const (
+	// DriftCreate means the resource is missing and would be created
+	DriftCreate MigControllerDriftOperation = "Create"
+	// DriftUpdate means the resource differs from its desired state and would be updated
+	DriftUpdate MigControllerDriftOperation = "Update"
+	// DriftDelete means the resource is no longer desired and would be deleted on upgrade
+	DriftDelete MigControllerDriftOperation = "Delete"
+)
+
+// MigControllerResourceDrift is a change reconciliation would make to an operand resource.
+type MigControllerResourceDrift struct {
+	// Kind of the resource
+	Kind string `json:"kind"`
+	// Namespace of the resource, empty for cluster scoped resources
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the resource
+	Name string `json:"name"`
+	// Operation is the change that would be made
+	Operation MigControllerDriftOperation `json:"operation"`
+	// ChangedPaths are JSON pointers to the fields that would change on update
+	ChangedPaths []string `json:"changedPaths,omitempty"`
+}
+
+// MigControllerUpgradeResult is the outcome of an operand upgrade.
+// +kubebuilder:validation:Enum=Succeeded;Failed;RolledBack
+type MigControllerUpgradeResult string
+
+const (
+	// UpgradeSucceeded means the new operand version became ready
+	UpgradeSucceeded MigControllerUpgradeResult = "Succeeded"
+	// UpgradeFailed means the new operand version did not become ready in time
+	UpgradeFailed MigControllerUpgradeResult = "Failed"
+	// UpgradeRolledBack means the new operand version did not become ready in time and the previous one was restored
+	UpgradeRolledBack MigControllerUpgradeResult = "RolledBack"
+)
+
+// MigControllerUpgradeHistory records a single operand upgrade.
+type MigControllerUpgradeHistory struct {
+	// FromVersion is the operator version the upgrade started from
+	FromVersion string `json:"fromVersion,omitempty"`
+	// ToVersion is the operator version the upgrade targeted
+	ToVersion string `json:"toVersion"`
+	// Result is the outcome of the upgrade
+	Result MigControllerUpgradeResult `json:"result"`
+	// Time is when the outcome was recorded
+	Time metav1.Time `json:"time"`
+	// Revision is the ControllerRevision holding the operand spec that is running after the upgrade
+	Revision string `json:"revision,omitempty"`
+}
+
 func init() {
 	SchemeBuilder.Register(&MigController{}, &MigControllerList{})
 }
@@ -21,6 +21,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -82,6 +84,31 @@ func (in *MigController) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerHistory) DeepCopyInto(out *MigControllerHistory) {
+	*out = *in
+	if in.TTLAfterFinished != nil {
+		in, out := &in.TTLAfterFinished, &out.TTLAfterFinished
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.LimitPerPlan != nil {
+		in, out := &in.LimitPerPlan, &out.LimitPerPlan
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerHistory.
+func (in *MigControllerHistory) DeepCopy() *MigControllerHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MigControllerList) DeepCopyInto(out *MigControllerList) {
 	*out = *in
@@ -114,6 +141,173 @@ func (in *MigControllerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerNamespaceQuotaUsage) DeepCopyInto(out *MigControllerNamespaceQuotaUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerNamespaceQuotaUsage.
+func (in *MigControllerNamespaceQuotaUsage) DeepCopy() *MigControllerNamespaceQuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerNamespaceQuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerPlanDefaults) DeepCopyInto(out *MigControllerPlanDefaults) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerPlanDefaults.
+func (in *MigControllerPlanDefaults) DeepCopy() *MigControllerPlanDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerPlanDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerPlanPolicies) DeepCopyInto(out *MigControllerPlanPolicies) {
+	*out = *in
+	if in.StorageClasses != nil {
+		in, out := &in.StorageClasses, &out.StorageClasses
+		*out = make([]MigControllerStorageClassPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MaxVirtualMachinesPerPlan != nil {
+		in, out := &in.MaxVirtualMachinesPerPlan, &out.MaxVirtualMachinesPerPlan
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerPlanPolicies.
+func (in *MigControllerPlanPolicies) DeepCopy() *MigControllerPlanPolicies {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerPlanPolicies)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerQuota) DeepCopyInto(out *MigControllerQuota) {
+	*out = *in
+	if in.MaxRunningPerNamespace != nil {
+		in, out := &in.MaxRunningPerNamespace, &out.MaxRunningPerNamespace
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxRunning != nil {
+		in, out := &in.MaxRunning, &out.MaxRunning
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerQuota.
+func (in *MigControllerQuota) DeepCopy() *MigControllerQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerQuotaUsage) DeepCopyInto(out *MigControllerQuotaUsage) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]MigControllerNamespaceQuotaUsage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerQuotaUsage.
+func (in *MigControllerQuotaUsage) DeepCopy() *MigControllerQuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerQuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerRBAC) DeepCopyInto(out *MigControllerRBAC) {
+	*out = *in
+	if in.StorageMigrators != nil {
+		in, out := &in.StorageMigrators, &out.StorageMigrators
+		*out = make([]v1.Subject, len(*in))
+		copy(*out, *in)
+	}
+	if in.MultiNamespaceStorageMigrators != nil {
+		in, out := &in.MultiNamespaceStorageMigrators, &out.MultiNamespaceStorageMigrators
+		*out = make([]v1.Subject, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerRBAC.
+func (in *MigControllerRBAC) DeepCopy() *MigControllerRBAC {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerRBAC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerResourceDrift) DeepCopyInto(out *MigControllerResourceDrift) {
+	*out = *in
+	if in.ChangedPaths != nil {
+		in, out := &in.ChangedPaths, &out.ChangedPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerResourceDrift.
+func (in *MigControllerResourceDrift) DeepCopy() *MigControllerResourceDrift {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerResourceDrift)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerRolloutStrategy) DeepCopyInto(out *MigControllerRolloutStrategy) {
+	*out = *in
+	if in.MaxDeferral != nil {
+		in, out := &in.MaxDeferral, &out.MaxDeferral
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerRolloutStrategy.
+func (in *MigControllerRolloutStrategy) DeepCopy() *MigControllerRolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerRolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MigControllerSpec) DeepCopyInto(out *MigControllerSpec) {
 	*out = *in
@@ -128,6 +322,51 @@ func (in *MigControllerSpec) DeepCopyInto(out *MigControllerSpec) {
 		*out = new(TLSSecurityProfile)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.UpgradeStrategy != nil {
+		in, out := &in.UpgradeStrategy, &out.UpgradeStrategy
+		*out = new(MigControllerUpgradeStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(MigControllerRolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WatchNamespaces != nil {
+		in, out := &in.WatchNamespaces, &out.WatchNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RBAC != nil {
+		in, out := &in.RBAC, &out.RBAC
+		*out = new(MigControllerRBAC)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PlanPolicies != nil {
+		in, out := &in.PlanPolicies, &out.PlanPolicies
+		*out = new(MigControllerPlanPolicies)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(MigControllerQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PlanDefaults != nil {
+		in, out := &in.PlanDefaults, &out.PlanDefaults
+		*out = new(MigControllerPlanDefaults)
+		**out = **in
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = new(MigControllerHistory)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerSpec.
@@ -144,6 +383,42 @@ func (in *MigControllerSpec) DeepCopy() *MigControllerSpec {
 func (in *MigControllerStatus) DeepCopyInto(out *MigControllerStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.UpgradeHistory != nil {
+		in, out := &in.UpgradeHistory, &out.UpgradeHistory
+		*out = make([]MigControllerUpgradeHistory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UnmanagedResources != nil {
+		in, out := &in.UnmanagedResources, &out.UnmanagedResources
+		*out = make([]MigControllerUnmanagedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]MigControllerResourceDrift, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.WatchedNamespaces != nil {
+		in, out := &in.WatchedNamespaces, &out.WatchedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ActivePolicies != nil {
+		in, out := &in.ActivePolicies, &out.ActivePolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.QuotaUsage != nil {
+		in, out := &in.QuotaUsage, &out.QuotaUsage
+		*out = new(MigControllerQuotaUsage)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerStatus.
@@ -156,6 +431,92 @@ func (in *MigControllerStatus) DeepCopy() *MigControllerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerStorageClassPolicy) DeepCopyInto(out *MigControllerStorageClassPolicy) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Allowed != nil {
+		in, out := &in.Allowed, &out.Allowed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Denied != nil {
+		in, out := &in.Denied, &out.Denied
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerStorageClassPolicy.
+func (in *MigControllerStorageClassPolicy) DeepCopy() *MigControllerStorageClassPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerStorageClassPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerUnmanagedResource) DeepCopyInto(out *MigControllerUnmanagedResource) {
+	*out = *in
+	if in.IgnoredFields != nil {
+		in, out := &in.IgnoredFields, &out.IgnoredFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerUnmanagedResource.
+func (in *MigControllerUnmanagedResource) DeepCopy() *MigControllerUnmanagedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerUnmanagedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerUpgradeHistory) DeepCopyInto(out *MigControllerUpgradeHistory) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerUpgradeHistory.
+func (in *MigControllerUpgradeHistory) DeepCopy() *MigControllerUpgradeHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerUpgradeHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigControllerUpgradeStrategy) DeepCopyInto(out *MigControllerUpgradeStrategy) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigControllerUpgradeStrategy.
+func (in *MigControllerUpgradeStrategy) DeepCopy() *MigControllerUpgradeStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(MigControllerUpgradeStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModernTLSProfile) DeepCopyInto(out *ModernTLSProfile) {
 	*out = *in
@@ -0,0 +1,421 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/internal/controller"
+	"kubevirt.io/kubevirt-migration-operator/pkg/gather"
+)
+
+const (
+	gatherCommand = "gather"
+
+	// mustGatherDir is where must-gather collects the files from the gather image
+	mustGatherDir = "/must-gather"
+	gatherTopDir  = "kubevirt-migration-operator"
+	tarballSuffix = ".tar.gz"
+
+	kubeVirtCAConfigMap = "kubevirt-ca"
+	operatorNameLabel   = "app.kubernetes.io/name"
+	operatorName        = "kubevirt-migration-operator"
+	operatorLabel       = "operator.migrations.kubevirt.io"
+
+	maxLogBytes = 10 * 1024 * 1024
+)
+
+// migrationKinds are the storage migration kinds collected from all namespaces
+var migrationKinds = []string{
+	"VirtualMachineStorageMigrationPlan",
+	"MultiNamespaceVirtualMachineStorageMigrationPlan",
+	"VirtualMachineStorageMigration",
+	"MultiNamespaceVirtualMachineStorageMigration",
+}
+
+// kubeVirtFields are the fields of the KubeVirt CR relevant to storage migrations, the rest is left out
+var kubeVirtFields = [][]string{
+	{"spec", "configuration", "developerConfiguration", "featureGates"},
+	{"spec", "configuration", "migrations"},
+	{"spec", "workloadUpdateStrategy"},
+	{"status", "phase"},
+	{"status", "conditions"},
+	{"status", "observedKubeVirtVersion"},
+}
+
+// gatherer collects the diagnostic bundle, failures to collect a part are recorded in the bundle
+// instead of aborting the collection
+type gatherer struct {
+	client    client.Client
+	clientset kubernetes.Interface
+	bundle    gather.Bundle
+	since     time.Duration
+	errors    []string
+}
+
+// runGather collects the MigControllers, the operand resources the operator manages, the operator and
+// operand pods and logs, events, the storage migration resources, the relevant KubeVirt CR fields and the
+// kubevirt-ca ConfigMap metadata into a directory tree or a tarball, with secret values redacted. Run as
+// /usr/bin/gather by the must-gather image it is the entrypoint of must-gather.
+func runGather(args []string) error {
+	flags := flag.NewFlagSet(gatherCommand, flag.ContinueOnError)
+	namespace := flags.String("namespace", "", "The namespace the operator runs in, the namespaces of the MigControllers when empty.")
+	output := flags.String("output", "", "The directory to write the files to, below a "+gatherTopDir+" directory, or the tarball "+
+		"to write when it ends with "+tarballSuffix+". "+mustGatherDir+" when it exists, else "+gatherTopDir+"-must-gather"+tarballSuffix+".")
+	since := flags.Duration("since", 0, "Only collect the logs of this long ago, e.g. 24h, all logs when 0.")
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = gatherTopDir + "-must-gather" + tarballSuffix
+		if info, err := os.Stat(mustGatherDir); err == nil && info.IsDir() {
+			*output = mustGatherDir
+		}
+	}
+
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return err
+	}
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	g := &gatherer{client: c, clientset: clientset, since: *since}
+	if !strings.HasSuffix(*output, tarballSuffix) {
+		g.bundle = gather.NewDirectory(*output, gatherTopDir)
+		if err := g.gather(context.Background(), *namespace); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "wrote", filepath.Join(*output, gatherTopDir))
+		return nil
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	g.bundle = gather.NewArchive(f, gatherTopDir)
+	err = g.gather(context.Background(), *namespace)
+	if closeErr := g.bundle.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "wrote", *output)
+	return nil
+}
+
+// getGatherArgs returns the arguments of the gather command, when the binary runs it
+func getGatherArgs(args []string) ([]string, bool) {
+	if filepath.Base(args[0]) == gatherCommand {
+		return args[1:], true
+	}
+	if len(args) > 1 && args[1] == gatherCommand {
+		return args[2:], true
+	}
+	return nil, false
+}
+
+func (g *gatherer) gather(ctx context.Context, namespace string) error {
+	crs := &migrationsv1alpha1.MigControllerList{}
+	if err := g.client.List(ctx, crs); err != nil {
+		return fmt.Errorf("unable to list the MigControllers; %w", err)
+	}
+	namespaces := map[string]bool{}
+	if namespace != "" {
+		namespaces[namespace] = true
+	}
+	for i := range crs.Items {
+		g.addObject("", &crs.Items[i])
+		if namespace == "" {
+			namespaces[crs.Items[i].Namespace] = true
+		}
+	}
+
+	managed, err := controller.ListManagedResources(ctx, g.client)
+	if err != nil {
+		g.recordError("unable to list the managed resources", err)
+	}
+	var deployments []*appsv1.Deployment
+	for _, obj := range managed {
+		g.addObject("managed", obj)
+		if deployment, ok := obj.(*appsv1.Deployment); ok && namespaces[deployment.Namespace] {
+			deployments = append(deployments, deployment)
+		}
+	}
+
+	for ns := range namespaces {
+		deployments = append(deployments, g.getOperatorDeployments(ctx, ns)...)
+		g.gatherConfigMapMetadata(ctx, ns, kubeVirtCAConfigMap)
+		g.gatherEvents(ctx, ns, nil)
+	}
+	for _, deployment := range deployments {
+		g.gatherPods(ctx, deployment)
+	}
+
+	migrationNamespaces := map[string]bool{}
+	for _, kind := range migrationKinds {
+		for _, obj := range g.listUnstructured(ctx, migrationsv1alpha1.GroupVersion.WithKind(kind+"List")) {
+			g.addUnstructured(path.Join("migrations", strings.ToLower(kind)), &obj)
+			migrationNamespaces[obj.GetNamespace()] = true
+		}
+	}
+	for ns := range migrationNamespaces {
+		if !namespaces[ns] {
+			g.gatherEvents(ctx, ns, migrationKinds)
+		}
+	}
+
+	g.gatherKubeVirt(ctx)
+
+	if len(g.errors) > 0 {
+		return g.bundle.AddFile("errors.log", []byte(strings.Join(g.errors, "\n")+"\n"))
+	}
+	return nil
+}
+
+// getOperatorDeployments returns the operator deployments of the namespace, as labeled by the CSV or the
+// kustomize manifests
+func (g *gatherer) getOperatorDeployments(ctx context.Context, namespace string) []*appsv1.Deployment {
+	list := &appsv1.DeploymentList{}
+	if err := g.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		g.recordError("unable to list the deployments of namespace "+namespace, err)
+		return nil
+	}
+	var deployments []*appsv1.Deployment
+	for i := range list.Items {
+		deployment := &list.Items[i]
+		if _, ok := deployment.Labels[operatorLabel]; ok || deployment.Labels[operatorNameLabel] == operatorName {
+			g.addObject("operator", deployment)
+			deployments = append(deployments, deployment)
+		}
+	}
+	return deployments
+}
+
+// gatherPods collects the pods of the deployment along with the logs of their containers
+func (g *gatherer) gatherPods(ctx context.Context, deployment *appsv1.Deployment) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil || selector.Empty() {
+		selector = labels.Nothing()
+	}
+	pods := &corev1.PodList{}
+	if err := g.client.List(ctx, pods, client.InNamespace(deployment.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		g.recordError("unable to list the pods of deployment "+deployment.Namespace+"/"+deployment.Name, err)
+		return
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		g.addObject("", pod)
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			g.gatherLogs(ctx, pod, status.Name, false)
+			if status.RestartCount > 0 {
+				g.gatherLogs(ctx, pod, status.Name, true)
+			}
+		}
+	}
+}
+
+func (g *gatherer) gatherLogs(ctx context.Context, pod *corev1.Pod, container string, previous bool) {
+	opts := &corev1.PodLogOptions{Container: container, Previous: previous, LimitBytes: ptr.To[int64](maxLogBytes)}
+	if g.since > 0 {
+		opts.SinceSeconds = ptr.To(int64(g.since.Seconds()))
+	}
+	logs, err := g.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).DoRaw(ctx)
+	if err != nil {
+		g.recordError(fmt.Sprintf("unable to read the logs of container %s of pod %s/%s", container, pod.Namespace, pod.Name), err)
+		return
+	}
+	name := container + ".log"
+	if previous {
+		name = container + ".previous.log"
+	}
+	if err := g.bundle.AddFile(path.Join("logs", pod.Namespace, pod.Name, name), logs); err != nil {
+		g.recordError("unable to archive the logs of pod "+pod.Namespace+"/"+pod.Name, err)
+	}
+}
+
+// gatherEvents collects the events of the namespace, only the ones of kinds when set
+func (g *gatherer) gatherEvents(ctx context.Context, namespace string, kinds []string) {
+	list := &corev1.EventList{}
+	if err := g.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		g.recordError("unable to list the events of namespace "+namespace, err)
+		return
+	}
+	events := &corev1.EventList{}
+	for _, event := range list.Items {
+		if kinds == nil || slices.Contains(kinds, event.InvolvedObject.Kind) {
+			events.Items = append(events.Items, event)
+		}
+	}
+	if len(events.Items) > 0 {
+		g.addList(path.Join("events", namespace+".yaml"), events)
+	}
+}
+
+// gatherConfigMapMetadata collects the metadata and the data keys of the ConfigMap, not its data
+func (g *gatherer) gatherConfigMapMetadata(ctx context.Context, namespace, name string) {
+	cm := &corev1.ConfigMap{}
+	if err := g.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			g.recordError("unable to read ConfigMap "+namespace+"/"+name, err)
+		}
+		return
+	}
+	var keys []string
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	for key := range cm.BinaryData {
+		keys = append(keys, key)
+	}
+	obj := &corev1.ConfigMap{ObjectMeta: cm.ObjectMeta}
+	obj.Annotations = map[string]string{}
+	for k, v := range cm.Annotations {
+		obj.Annotations[k] = v
+	}
+	sort.Strings(keys)
+	obj.Annotations["must-gather.migrations.kubevirt.io/data-keys"] = strings.Join(keys, ",")
+	g.addObject("", obj)
+}
+
+// gatherKubeVirt collects the fields of the KubeVirt CRs relevant to storage migrations
+func (g *gatherer) gatherKubeVirt(ctx context.Context) {
+	for _, kv := range g.listUnstructured(ctx, schema.GroupVersionKind{Group: "kubevirt.io", Version: "v1", Kind: "KubeVirtList"}) {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetAPIVersion(kv.GetAPIVersion())
+		obj.SetKind(kv.GetKind())
+		obj.SetNamespace(kv.GetNamespace())
+		obj.SetName(kv.GetName())
+		obj.SetGeneration(kv.GetGeneration())
+		for _, field := range kubeVirtFields {
+			if value, found, _ := unstructured.NestedFieldCopy(kv.Object, field...); found {
+				_ = unstructured.SetNestedField(obj.Object, value, field...)
+			}
+		}
+		g.addUnstructured("kubevirt", obj)
+	}
+}
+
+func (g *gatherer) listUnstructured(ctx context.Context, gvk schema.GroupVersionKind) []unstructured.Unstructured {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk)
+	if err := g.client.List(ctx, list); err != nil {
+		if !meta.IsNoMatchError(err) {
+			g.recordError("unable to list "+gvk.Kind, err)
+		}
+		return nil
+	}
+	return list.Items
+}
+
+// addObject adds a typed object below dir, in a directory per kind
+func (g *gatherer) addObject(dir string, obj client.Object) {
+	u, err := toUnstructured(obj)
+	if err != nil {
+		g.recordError(fmt.Sprintf("unable to convert %T %s/%s", obj, obj.GetNamespace(), obj.GetName()), err)
+		return
+	}
+	g.addUnstructured(path.Join(dir, strings.ToLower(u.GetKind())), u)
+}
+
+func (g *gatherer) addUnstructured(dir string, obj *unstructured.Unstructured) {
+	name := obj.GetName() + ".yaml"
+	if obj.GetNamespace() != "" {
+		name = obj.GetNamespace() + "_" + name
+	}
+	// cluster role names contain colons
+	name = strings.ReplaceAll(name, ":", "-")
+	if err := g.bundle.AddObject(path.Join(dir, name), obj.Object); err != nil {
+		g.recordError("unable to archive "+path.Join(dir, name), err)
+	}
+}
+
+func (g *gatherer) addList(name string, list client.ObjectList) {
+	u, err := toUnstructured(list)
+	if err == nil {
+		for _, item := range u.Object["items"].([]interface{}) {
+			if obj, ok := item.(map[string]interface{}); ok {
+				gather.Redact(obj)
+			}
+		}
+		err = g.bundle.AddObject(name, u.Object)
+	}
+	if err != nil {
+		g.recordError("unable to archive "+name, err)
+	}
+}
+
+func (g *gatherer) recordError(message string, err error) {
+	fmt.Fprintf(os.Stderr, "warning: %s; %v\n", message, err)
+	g.errors = append(g.errors, message+"; "+err.Error())
+}
+
+// toUnstructured converts the object, the type meta typed objects lack after a list is set from the scheme
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return nil, err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	if u.Object["items"] == nil && strings.HasSuffix(gvk.Kind, "List") {
+		u.Object["items"] = []interface{}{}
+	}
+	return u, nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+// clientFlags are the kubeconfig flags every command connecting to a cluster has
+type clientFlags struct {
+	kubeconfig *string
+	context    *string
+	namespace  *string
+}
+
+func addClientFlags(flags *flag.FlagSet) *clientFlags {
+	return &clientFlags{
+		kubeconfig: flags.String("kubeconfig", "", "Path to the kubeconfig file, the KUBECONFIG environment variable or ~/.kube/config when empty."),
+		context:    flags.String("context", "", "The kubeconfig context to use."),
+		namespace:  flags.String("namespace", "", "Comma separated namespaces, the namespace of the kubeconfig context when empty."),
+	}
+}
+
+// newClient returns a client for the cluster of the kubeconfig and the namespaces to work in
+func (f *clientFlags) newClient() (client.Client, []string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = *f.kubeconfig
+	config := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: *f.context})
+
+	restConfig, err := config.ClientConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, nil, err
+	}
+	if err := migrationsv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, nil, err
+	}
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	namespaces := splitList(*f.namespace)
+	if len(namespaces) == 0 {
+		namespace, _, err := config.Namespace()
+		if err != nil {
+			return nil, nil, err
+		}
+		namespaces = []string{namespace}
+	}
+	return c, namespaces, nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
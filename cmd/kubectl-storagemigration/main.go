@@ -0,0 +1,67 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-storagemigration is a kubectl plugin to prepare and follow virtual machine storage migrations
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command is a subcommand of the plugin
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+var commands = []command{
+	{name: planCommand, description: "Build storage migration plans for the virtual machines matching a selector", run: runPlan},
+	{name: preflightCommand, description: "Check storage migration plans against the cluster state", run: runPreflight},
+	{name: reportCommand, description: "Report the outcome of the storage migrations of plans as JSON, CSV or HTML", run: runReport},
+	{name: watchCommand, description: "Watch the progress of the storage migrations of plans until they finish", run: runWatch},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+		if err := cmd.run(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if os.Args[1] != "-h" && os.Args[1] != "--help" && os.Args[1] != "help" {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+	}
+	usage()
+	os.Exit(1)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: kubectl storagemigration <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+}
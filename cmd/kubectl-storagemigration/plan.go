@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"kubevirt.io/kubevirt-migration-operator/pkg/planbuilder"
+)
+
+const planCommand = "plan"
+
+// runPlan prints or creates the storage migration plans for the virtual machines matching a selector
+func runPlan(args []string) error {
+	flags := flag.NewFlagSet(planCommand, flag.ContinueOnError)
+	clientFlags := addClientFlags(flags)
+	name := flags.String("name", "", "The name of the plan, plans of more than one batch are suffixed with the batch number.")
+	selector := flags.String("selector", "", "The label selector the virtual machines have to match, all virtual machines when empty.")
+	sourceStorageClass := flags.String("source-storage-class", "",
+		"Only migrate the volumes on this storage class, all volumes when empty.")
+	targetStorageClass := flags.String("target-storage-class", "", "The storage class the volumes are migrated to.")
+	retentionPolicy := flags.String("retention-policy", "", "The retention policy of the plans, keepSource or deleteSource.")
+	batchSize := flags.Int("batch-size", 0, "The number of virtual machines per plan, one plan when 0.")
+	multiNamespace := flags.Bool("multi-namespace", false,
+		"Build multi namespace plans in --plan-namespace instead of a plan per namespace.")
+	planNamespace := flags.String("plan-namespace", "", "The namespace of the multi namespace plans.")
+	create := flags.Bool("create", false, "Create the plans instead of printing them.")
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	labelSelector, err := labels.Parse(*selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector; %w", err)
+	}
+	c, namespaces, err := clientFlags.newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	plans, warnings, err := planbuilder.Build(ctx, c, planbuilder.Options{
+		Name:               *name,
+		Namespaces:         namespaces,
+		Selector:           labelSelector,
+		SourceStorageClass: *sourceStorageClass,
+		TargetStorageClass: *targetStorageClass,
+		RetentionPolicy:    *retentionPolicy,
+		BatchSize:          *batchSize,
+		MultiNamespace:     *multiNamespace,
+		PlanNamespace:      *planNamespace,
+	})
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+	if len(plans) == 0 {
+		return fmt.Errorf("no virtual machine has volumes to migrate")
+	}
+
+	for _, plan := range plans {
+		if *create {
+			if err := c.Create(ctx, plan); err != nil {
+				return fmt.Errorf("unable to create %s %s/%s; %w", plan.GetKind(), plan.GetNamespace(), plan.GetName(), err)
+			}
+			fmt.Printf("%s %s/%s created\n", plan.GetKind(), plan.GetNamespace(), plan.GetName())
+			continue
+		}
+		bytes, err := yaml.Marshal(plan.Object)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("---\n%s", bytes)
+	}
+	return nil
+}
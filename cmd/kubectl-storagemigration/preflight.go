@@ -0,0 +1,176 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/planbuilder"
+	"kubevirt.io/kubevirt-migration-operator/pkg/preflight"
+)
+
+const (
+	preflightCommand = "preflight"
+
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// runPreflight evaluates storage migration plans, from files or the cluster, against the cluster state
+func runPreflight(args []string) error {
+	flags := flag.NewFlagSet(preflightCommand, flag.ContinueOnError)
+	clientFlags := addClientFlags(flags)
+	file := flags.String("f", "", "A file of plans as printed by the plan command, - for stdin.")
+	plan := flags.String("plan", "", "The name of a plan in the first namespace.")
+	multiNamespace := flags.Bool("multi-namespace", false, "--plan names a multi namespace plan.")
+	output := flags.String("o", outputTable, "The output format, table or json.")
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if (*file == "") == (*plan == "") {
+		return errors.New("exactly one of -f and --plan is required")
+	}
+	if *output != outputTable && *output != outputJSON {
+		return fmt.Errorf("invalid output format %q, expected %s or %s", *output, outputTable, outputJSON)
+	}
+
+	c, namespaces, err := clientFlags.newClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var plans []*unstructured.Unstructured
+	if *file != "" {
+		if plans, err = readPlans(*file, namespaces[0]); err != nil {
+			return err
+		}
+	} else {
+		obj := &unstructured.Unstructured{}
+		kind := planbuilder.PlanKind
+		if *multiNamespace {
+			kind = planbuilder.MultiNamespacePlanKind
+		}
+		obj.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind(kind))
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespaces[0], Name: *plan}, obj); err != nil {
+			return fmt.Errorf("unable to read %s %s/%s; %w", kind, namespaces[0], *plan, err)
+		}
+		plans = append(plans, obj)
+	}
+
+	var reports []*preflight.PlanReport
+	failed := false
+	for _, plan := range plans {
+		report, err := preflight.Analyze(ctx, c, plan)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, report)
+		failed = failed || report.Result == preflight.ResultFail
+	}
+
+	if *output == outputJSON {
+		bytes, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+	} else if err := printReports(os.Stdout, reports); err != nil {
+		return err
+	}
+	if failed {
+		return errors.New("pre-flight checks failed")
+	}
+	return nil
+}
+
+// readPlans reads the plans of a YAML or JSON stream, plans without a namespace are put in namespace
+func readPlans(file, namespace string) ([]*unstructured.Unstructured, error) {
+	var reader io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	var plans []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("unable to read the plans of %s; %w", file, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if kind := obj.GetKind(); kind != planbuilder.PlanKind && kind != planbuilder.MultiNamespacePlanKind {
+			return nil, fmt.Errorf("%s is not a storage migration plan", kind)
+		}
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		}
+		plans = append(plans, obj)
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("%s holds no plans", file)
+	}
+	return plans, nil
+}
+
+// printReports prints a line per check, the passing virtual machine checks are summarized in a single line
+func printReports(out io.Writer, reports []*preflight.PlanReport) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PLAN\tVIRTUAL MACHINE\tRESULT\tCHECK\tMESSAGE")
+	for _, report := range reports {
+		plan := report.Namespace + "/" + report.Name
+		for _, check := range report.Checks {
+			fmt.Fprintf(w, "%s\t\t%s\t%s\t%s\n", plan, check.Result, check.Name, check.Message)
+		}
+		for _, vm := range report.VirtualMachines {
+			name := vm.Namespace + "/" + vm.Name
+			if vm.Result == preflight.ResultPass {
+				fmt.Fprintf(w, "%s\t%s\t%s\t\t\n", plan, name, vm.Result)
+				continue
+			}
+			for _, check := range vm.Checks {
+				if check.Result != preflight.ResultPass {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", plan, name, check.Result, check.Name, check.Message)
+				}
+			}
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"kubevirt.io/kubevirt-migration-operator/pkg/report"
+)
+
+const reportCommand = "report"
+
+// runReport writes a report of the storage migrations of the plans in the namespaces
+func runReport(args []string) error {
+	flags := flag.NewFlagSet(reportCommand, flag.ContinueOnError)
+	clientFlags := addClientFlags(flags)
+	plan := flags.String("plan", "", "The name of the plan to report, all plans when empty.")
+	allNamespaces := flags.Bool("all-namespaces", false, "Report the plans of all namespaces.")
+	since := flags.String("since", "",
+		"Only report the storage migrations created since this RFC 3339 time, or this long ago like 24h.")
+	until := flags.String("until", "",
+		"Only report the storage migrations created before this RFC 3339 time, or this long ago like 24h.")
+	formats := make([]string, 0, len(report.Formats))
+	for _, format := range report.Formats {
+		formats = append(formats, string(format))
+	}
+	output := flags.String("o", string(report.FormatJSON), "The output format, one of "+strings.Join(formats, ", ")+".")
+	file := flags.String("output-file", "", "The file to write the report to, stdout when empty.")
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !slices.Contains(formats, *output) {
+		return fmt.Errorf("invalid output format %q, expected one of %s", *output, strings.Join(formats, ", "))
+	}
+
+	now := time.Now()
+	opts := report.Options{Plan: *plan}
+	var err error
+	if opts.Since, err = parseTime(*since, now); err != nil {
+		return fmt.Errorf("invalid --since; %w", err)
+	}
+	if opts.Until, err = parseTime(*until, now); err != nil {
+		return fmt.Errorf("invalid --until; %w", err)
+	}
+
+	c, namespaces, err := clientFlags.newClient()
+	if err != nil {
+		return err
+	}
+	if !*allNamespaces {
+		opts.Namespaces = namespaces
+	}
+	migrationReport, err := report.Build(context.Background(), c, opts)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	return report.Write(out, migrationReport, report.Format(*output))
+}
+
+// parseTime parses an RFC 3339 time or a duration before now, the zero time when value is empty
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/kubevirt-migration-operator/pkg/report"
+)
+
+const (
+	watchCommand = "watch"
+
+	clearScreen = "\033[H\033[2J"
+)
+
+// watchedKinds are the kinds of the objects whose events are shown
+var watchedKinds = []string{
+	"VirtualMachineStorageMigrationPlan",
+	"MultiNamespaceVirtualMachineStorageMigrationPlan",
+	"VirtualMachineStorageMigration",
+	"MultiNamespaceVirtualMachineStorageMigration",
+	"VirtualMachine",
+	"VirtualMachineInstance",
+}
+
+// watcher refreshes the state of the watched plans
+type watcher struct {
+	client     client.Client
+	namespaces []string
+	plans      []string
+	events     int
+}
+
+// runWatch shows the progress of the storage migrations of plans until the last storage migration of
+// each plan is finished. It fails when a virtual machine failed or the plans do not show up in time,
+// so it can gate scripts
+func runWatch(args []string) error {
+	flags := flag.NewFlagSet(watchCommand, flag.ContinueOnError)
+	clientFlags := addClientFlags(flags)
+	plans := flags.String("plan", "", "Comma separated names of the plans to watch, all plans of the namespaces when empty.")
+	interval := flags.Duration("interval", 2*time.Second, "How often to refresh.")
+	events := flags.Int("events", 10, "The number of recent events to show.")
+	failFast := flags.Bool("fail-fast", false, "Stop at the first failure instead of waiting for the storage migrations to finish.")
+	planTimeout := flags.Duration("plan-timeout", time.Minute, "How long to wait for the plans to be created.")
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c, namespaces, err := clientFlags.newClient()
+	if err != nil {
+		return err
+	}
+	w := &watcher{client: c, namespaces: namespaces, plans: splitList(*plans), events: *events}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	terminal := isTerminal(os.Stdout)
+	var last string
+	start := time.Now()
+	for {
+		now := time.Now()
+		plans, err := w.getPlans(ctx)
+		if err != nil {
+			return err
+		}
+		events, err := w.getEvents(ctx, plans)
+		if err != nil {
+			return err
+		}
+
+		frame := &bytes.Buffer{}
+		if err := render(frame, plans, events, now); err != nil {
+			return err
+		}
+		// without a terminal only the changes are printed, so the output can be logged
+		if terminal {
+			fmt.Print(clearScreen + frame.String())
+		} else if state := getState(plans, events); state != last {
+			fmt.Println(frame.String())
+			last = state
+		}
+
+		finished, failed := summarize(plans)
+		if failed && (finished || *failFast) {
+			return errors.New("storage migrations failed")
+		}
+		missing := w.checkPlans(plans)
+		if finished && missing == nil {
+			return nil
+		}
+		if missing != nil && now.Sub(start) >= *planTimeout {
+			return fmt.Errorf("%w after waiting %s", missing, *planTimeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.New("interrupted before the storage migrations finished")
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// getPlans reports the watched plans
+func (w *watcher) getPlans(ctx context.Context) ([]report.Plan, error) {
+	migrationReport, err := report.Build(ctx, w.client, report.Options{Namespaces: w.namespaces})
+	if err != nil {
+		return nil, err
+	}
+	if len(w.plans) == 0 {
+		return migrationReport.Plans, nil
+	}
+	var plans []report.Plan
+	for _, plan := range migrationReport.Plans {
+		if slices.Contains(w.plans, plan.Name) {
+			plans = append(plans, plan)
+		}
+	}
+	return plans, nil
+}
+
+// checkPlans returns an error naming the watched plans that do not exist, or telling that the namespaces
+// have no plans at all when no plan is named
+func (w *watcher) checkPlans(plans []report.Plan) error {
+	if len(w.plans) == 0 {
+		if len(plans) == 0 {
+			return fmt.Errorf("no plans found in namespaces %s", strings.Join(w.namespaces, ", "))
+		}
+		return nil
+	}
+	var missing []string
+	for _, name := range w.plans {
+		if !slices.ContainsFunc(plans, func(plan report.Plan) bool { return plan.Name == name }) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("plans %s not found in namespaces %s", strings.Join(missing, ", "), strings.Join(w.namespaces, ", "))
+	}
+	return nil
+}
+
+// getEvents returns the most recent events of the plans, their storage migrations and virtual machines, newest first
+func (w *watcher) getEvents(ctx context.Context, plans []report.Plan) ([]corev1.Event, error) {
+	names := map[string]bool{}
+	namespaces := map[string]bool{}
+	for _, plan := range plans {
+		names[plan.Namespace+"/"+plan.Name] = true
+		namespaces[plan.Namespace] = true
+		for _, migration := range plan.Migrations {
+			names[plan.Namespace+"/"+migration.Name] = true
+		}
+		for _, vm := range plan.VirtualMachines {
+			names[vm.Namespace+"/"+vm.Name] = true
+			namespaces[vm.Namespace] = true
+		}
+	}
+
+	var events []corev1.Event
+	for namespace := range namespaces {
+		list := &corev1.EventList{}
+		if err := w.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("unable to list the events of namespace %s; %w", namespace, err)
+		}
+		for _, event := range list.Items {
+			object := event.InvolvedObject
+			if slices.Contains(watchedKinds, object.Kind) && names[object.Namespace+"/"+object.Name] {
+				events = append(events, event)
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return eventTime(events[i]).After(eventTime(events[j])) })
+	if len(events) > w.events {
+		events = events[:w.events]
+	}
+	return events, nil
+}
+
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// summarize tells whether all plans are finished and whether any failed
+func summarize(plans []report.Plan) (bool, bool) {
+	finished, failed := len(plans) > 0, false
+	for i := range plans {
+		finished = finished && plans[i].Finished()
+		failed = failed || plans[i].Failed()
+	}
+	return finished, failed
+}
+
+// render writes a frame showing the plans, the state of their virtual machines and the recent events
+func render(out io.Writer, plans []report.Plan, events []corev1.Event, now time.Time) error {
+	fmt.Fprintf(out, "Storage migrations at %s\n", now.Format(time.DateTime))
+	if len(plans) == 0 {
+		fmt.Fprintln(out, "\nWaiting for plans...")
+	}
+
+	for _, plan := range plans {
+		fmt.Fprintf(out, "\n%s %s/%s", plan.Kind, plan.Namespace, plan.Name)
+		if n := len(plan.Migrations); n > 0 {
+			migration := plan.Migrations[n-1]
+			fmt.Fprintf(out, "  migration %s %s  elapsed %s", migration.Name, migration.Phase,
+				elapsed(&migration.StartedAt, migration.Duration, now))
+		}
+		fmt.Fprintf(out, "\n  running %d  completed %d  failed %d  cancelled %d  pending %d\n\n",
+			plan.Outcomes[report.OutcomeRunning], plan.Outcomes[report.OutcomeCompleted],
+			plan.Outcomes[report.OutcomeFailed]+plan.Outcomes[report.OutcomeInvalid],
+			plan.Outcomes[report.OutcomeCancelled], plan.Outcomes[report.OutcomePending])
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  VIRTUAL MACHINE\tPHASE\tPROGRESS\tELAPSED\tERRORS")
+		for _, vm := range plan.VirtualMachines {
+			fmt.Fprintf(w, "  %s/%s\t%s\t%s\t%s\t%s\n", vm.Namespace, vm.Name, vm.Outcome, vm.Progress,
+				elapsed(vm.StartedAt, vm.Duration, now), strings.Join(vm.Errors, "; "))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(events) > 0 {
+		fmt.Fprintln(out, "\nRecent events")
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, event := range events {
+			fmt.Fprintf(w, "  %s\t%s\t%s/%s\t%s\t%s\n", eventTime(event).Local().Format(time.TimeOnly), event.Type,
+				event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Reason, strings.TrimSpace(event.Message))
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+// elapsed returns the duration of a finished storage migration, or the time since it started
+func elapsed(startedAt *metav1.Time, duration *metav1.Duration, now time.Time) string {
+	switch {
+	case duration != nil:
+		return duration.Duration.Round(time.Second).String()
+	case startedAt != nil && !startedAt.IsZero():
+		return now.Sub(startedAt.Time).Round(time.Second).String()
+	default:
+		return ""
+	}
+}
+
+// getState returns what a frame shows besides the times, to tell whether anything changed
+func getState(plans []report.Plan, events []corev1.Event) string {
+	state := &strings.Builder{}
+	for _, plan := range plans {
+		fmt.Fprintf(state, "%s/%s", plan.Namespace, plan.Name)
+		for _, migration := range plan.Migrations {
+			fmt.Fprintf(state, " %s:%s", migration.Name, migration.Phase)
+		}
+		for _, vm := range plan.VirtualMachines {
+			fmt.Fprintf(state, " %s/%s:%s:%s:%d", vm.Namespace, vm.Name, vm.Outcome, vm.Progress, len(vm.Errors))
+		}
+		state.WriteString("\n")
+	}
+	for _, event := range events {
+		fmt.Fprintf(state, "%s:%d\n", event.UID, event.Count)
+	}
+	return state.String()
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,131 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kubevirt.io/kubevirt-migration-operator/pkg/report"
+)
+
+func TestStorageMigrationPlugin(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Storage Migration Plugin Suite")
+}
+
+// newWatchedPlan creates the report of a plan whose last storage migration is in phase, finished unless it runs
+func newWatchedPlan(name, phase string, outcomes map[report.Outcome]int) report.Plan {
+	plan := report.Plan{Namespace: "ns1", Name: name, Outcomes: outcomes}
+	if phase == "" {
+		return plan
+	}
+	migration := report.Migration{Name: name + "-1", Phase: phase}
+	if phase != string(report.OutcomeRunning) {
+		migration.FinishedAt = &metav1.Time{Time: time.Now()}
+	}
+	plan.Migrations = []report.Migration{migration}
+	plan.VirtualMachines = []report.VirtualMachine{{Namespace: "ns1", Name: "vm", Outcome: report.Outcome(phase)}}
+	return plan
+}
+
+var _ = Describe("Watch", func() {
+	failed := map[report.Outcome]int{report.OutcomeFailed: 1}
+
+	DescribeTable("should summarize the plans",
+		func(plans []report.Plan, finished, failed bool) {
+			isFinished, isFailed := summarize(plans)
+			Expect(isFinished).To(Equal(finished))
+			Expect(isFailed).To(Equal(failed))
+		},
+		Entry("without plans", nil, false, false),
+		Entry("without storage migrations", []report.Plan{newWatchedPlan("a", "", nil)}, false, false),
+		Entry("running", []report.Plan{
+			newWatchedPlan("a", "Completed", nil), newWatchedPlan("b", "Running", nil),
+		}, false, false),
+		Entry("completed", []report.Plan{
+			newWatchedPlan("a", "Completed", nil), newWatchedPlan("b", "Completed", nil),
+		}, true, false),
+		Entry("failing while running", []report.Plan{
+			newWatchedPlan("a", "Completed", nil), newWatchedPlan("b", "Running", failed),
+		}, false, true),
+		Entry("failed", []report.Plan{
+			newWatchedPlan("a", "Completed", nil), newWatchedPlan("b", "Failed", nil),
+		}, true, true),
+	)
+
+	DescribeTable("should tell when plans are missing",
+		func(watched []string, plans []report.Plan, message string) {
+			w := &watcher{namespaces: []string{"ns1", "ns2"}, plans: watched}
+			err := w.checkPlans(plans)
+			if message == "" {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(message))
+			}
+		},
+		Entry("with all plans", nil, []report.Plan{newWatchedPlan("a", "", nil)}, ""),
+		Entry("without plans", nil, nil, "no plans found in namespaces ns1, ns2"),
+		Entry("with the named plans", []string{"a", "b"},
+			[]report.Plan{newWatchedPlan("a", "", nil), newWatchedPlan("b", "", nil)}, ""),
+		Entry("with a mistyped plan", []string{"a", "c"},
+			[]report.Plan{newWatchedPlan("a", "", nil)}, "plans c not found in namespaces ns1, ns2"),
+	)
+
+	Context("state", func() {
+		var (
+			plans  []report.Plan
+			events []corev1.Event
+			state  string
+		)
+
+		BeforeEach(func() {
+			plans = []report.Plan{newWatchedPlan("a", "Running", nil)}
+			plans[0].VirtualMachines[0].Progress = "10%"
+			events = []corev1.Event{{ObjectMeta: metav1.ObjectMeta{UID: "event-1"}, Count: 1}}
+			state = getState(plans, events)
+		})
+
+		It("should not change with the time only", func() {
+			plans[0].Migrations[0].StartedAt = metav1.NewTime(time.Now())
+			plans[0].CreatedAt = metav1.NewTime(time.Now())
+			Expect(getState(plans, events)).To(Equal(state))
+		})
+
+		DescribeTable("should change with what a frame shows",
+			func(change func()) {
+				change()
+				Expect(getState(plans, events)).ToNot(Equal(state))
+			},
+			Entry("the migration phase", func() { plans[0].Migrations[0].Phase = "Completed" }),
+			Entry("a new migration", func() {
+				plans[0].Migrations = append(plans[0].Migrations, report.Migration{Name: "a-2"})
+			}),
+			Entry("the virtual machine outcome", func() { plans[0].VirtualMachines[0].Outcome = report.OutcomeCompleted }),
+			Entry("the virtual machine progress", func() { plans[0].VirtualMachines[0].Progress = "20%" }),
+			Entry("the virtual machine errors", func() { plans[0].VirtualMachines[0].Errors = []string{"failed"} }),
+			Entry("a new plan", func() { plans = append(plans, newWatchedPlan("b", "", nil)) }),
+			Entry("a repeated event", func() { events[0].Count++ }),
+			Entry("a new event", func() { events = append(events, corev1.Event{ObjectMeta: metav1.ObjectMeta{UID: "event-2"}}) }),
+		)
+	})
+})
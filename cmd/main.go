@@ -19,6 +19,7 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -26,13 +27,17 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	operatorsv2 "github.com/operator-framework/api/pkg/operators/v2"
+	rbacv1 "k8s.io/api/rbac/v1"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -41,6 +46,7 @@ import (
 
 	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
 	"kubevirt.io/kubevirt-migration-operator/internal/controller"
+	webhookmigrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/internal/webhook/v1alpha1"
 	"kubevirt.io/kubevirt-migration-operator/pkg/resources/utils"
 	// +kubebuilder:scaffold:imports
 )
@@ -55,11 +61,28 @@ func init() {
 
 	utilruntime.Must(extv1.AddToScheme(scheme))
 	utilruntime.Must(migrationsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(operatorsv2.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
 // nolint:gocyclo
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == renderCommand {
+		if err := runRender(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	// the must-gather image ships the binary as /usr/bin/gather, the entrypoint must-gather runs
+	if gatherArgs, ok := getGatherArgs(os.Args); ok {
+		if err := runGather(gatherArgs); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
@@ -205,6 +228,17 @@ func main() {
 			DefaultNamespaces: map[string]cache.Config{
 				namespace: {},
 			},
+			// the controller RBAC is also created in the namespaces the controller is restricted to
+			ByObject: map[client.Object]cache.ByObject{
+				&rbacv1.Role{}: {
+					Namespaces: map[string]cache.Config{cache.AllNamespaces: {}},
+					Label:      labels.SelectorFromSet(labels.Set{utils.GenericLabelKey: ""}),
+				},
+				&rbacv1.RoleBinding{}: {
+					Namespaces: map[string]cache.Config{cache.AllNamespaces: {}},
+					Label:      labels.SelectorFromSet(labels.Set{utils.GenericLabelKey: ""}),
+				},
+			},
 		},
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
@@ -244,6 +278,29 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "MigController")
 		os.Exit(1)
 	}
+	if err = controller.NewHistoryCollector(mgr, namespace).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create history collector")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookmigrationsv1alpha1.SetupMultiNamespacePlanWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", webhookmigrationsv1alpha1.MultiNamespacePlanKind)
+			os.Exit(1)
+		}
+		if err = webhookmigrationsv1alpha1.SetupMigrationQuotaWebhookWithManager(mgr, namespace); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", webhookmigrationsv1alpha1.MigrationKind)
+			os.Exit(1)
+		}
+		if err = webhookmigrationsv1alpha1.SetupPlanDefaultsWebhookWithManager(mgr, namespace); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", webhookmigrationsv1alpha1.PlanKind)
+			os.Exit(1)
+		}
+		if err = webhookmigrationsv1alpha1.SetupPlanPreflightWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "preflight")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -0,0 +1,180 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/internal/controller"
+)
+
+const renderCommand = "render"
+
+// runRender prints the operand manifests the operator applies for a MigController without connecting to a cluster.
+// The operator environment (OPERATOR_VERSION, CONTROLLER_IMAGE, PULL_POLICY, VERBOSITY, DEPLOY_CLUSTER_RESOURCES
+// and TARGET_NAMESPACES) is read from the environment like the operator does.
+func runRender(args []string) error {
+	flags := flag.NewFlagSet(renderCommand, flag.ContinueOnError)
+	crPath := flags.String("cr", "-", "The MigController YAML file, - reads it from stdin.")
+	namespace := flags.String("namespace", "",
+		"The namespace the operator runs in, the namespace of the MigController when empty.")
+	selectedNamespaces := flags.String("selected-namespaces", "",
+		"Comma separated namespaces assumed to match spec.namespaceSelector, which cannot be resolved offline.")
+	outputDir := flags.String("output-dir", "",
+		"Writes a kustomize directory with a file per manifest instead of printing them to stdout.")
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cr, err := readMigController(*crPath)
+	if err != nil {
+		return err
+	}
+	if *namespace == "" {
+		*namespace = cr.Namespace
+	}
+	if *namespace == "" {
+		return fmt.Errorf("the operator namespace is unknown, set --namespace or the MigController namespace")
+	}
+
+	var selected []string
+	for _, ns := range strings.Split(*selectedNamespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			selected = append(selected, ns)
+		}
+	}
+	if cr.Spec.NamespaceSelector != nil && len(selected) == 0 {
+		fmt.Fprintln(os.Stderr, "warning: spec.namespaceSelector cannot be resolved offline, "+
+			"list the matching namespaces with --selected-namespaces")
+	}
+
+	resources, err := controller.RenderResources(cr, *namespace, selected)
+	if err != nil {
+		return err
+	}
+
+	if *outputDir == "" {
+		for _, resource := range resources {
+			if err := writeManifest(resource, os.Stdout); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return writeKustomization(resources, *outputDir)
+}
+
+func readMigController(path string) (*migrationsv1alpha1.MigController, error) {
+	var bytes []byte
+	var err error
+	if path == "-" {
+		bytes, err = io.ReadAll(os.Stdin)
+	} else {
+		bytes, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cr := &migrationsv1alpha1.MigController{}
+	if err := yaml.Unmarshal(bytes, cr); err != nil {
+		return nil, fmt.Errorf("unable to read the MigController; %w", err)
+	}
+	if cr.Kind != "MigController" {
+		return nil, fmt.Errorf("expected a MigController but got %q", cr.Kind)
+	}
+	return cr, nil
+}
+
+// writeKustomization writes a file per manifest into dir along with the kustomization.yaml listing them
+func writeKustomization(resources []client.Object, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	var files []string
+	for _, resource := range resources {
+		parts := []string{strings.ToLower(resource.GetObjectKind().GroupVersionKind().Kind)}
+		if resource.GetNamespace() != "" {
+			parts = append(parts, resource.GetNamespace())
+		}
+		// cluster role names contain colons
+		parts = append(parts, strings.ReplaceAll(resource.GetName(), ":", "-"))
+		file := strings.Join(parts, "_") + ".yaml"
+
+		f, err := os.Create(filepath.Join(dir, file))
+		if err != nil {
+			return err
+		}
+		err = writeManifest(resource, f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+
+	kustomization, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  files,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "kustomization.yaml"), kustomization, 0o644)
+}
+
+// writeManifest writes the resource as a YAML document without the fields the API server populates
+func writeManifest(resource client.Object, writer io.Writer) error {
+	jsonBytes, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+
+	var r unstructured.Unstructured
+	if err := json.Unmarshal(jsonBytes, &r.Object); err != nil {
+		return err
+	}
+	unstructured.RemoveNestedField(r.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(r.Object, "spec", "template", "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(r.Object, "status")
+
+	yamlBytes, err := yaml.Marshal(r.Object)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write([]byte("---\n")); err != nil {
+		return err
+	}
+	_, err = writer.Write(yamlBytes)
+	return err
+}
@@ -0,0 +1,181 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources/cluster"
+)
+
+const (
+	clusterResourcesVerifiedReason = "AsExpected"
+	clusterResourcesMismatchReason = "ClusterResourcesMismatch"
+)
+
+// verifyClusterResources checks the CRDs, ClusterRoles and ClusterRoleBindings the operand depends on when they are
+// pre-installed instead of deployed by the operator, mismatches are reported in the ClusterResourcesVerified condition
+func (r *MigControllerReconciler) verifyClusterResources(cr *migrationsv1alpha1.MigController) error {
+	status := r.Status(cr)
+	if sdk.DeployClusterResources() {
+		if conditions.FindStatusCondition(status.Conditions, migrationsv1alpha1.ConditionClusterResourcesVerified) == nil {
+			return nil
+		}
+		conditions.RemoveStatusCondition(&status.Conditions, migrationsv1alpha1.ConditionClusterResourcesVerified)
+		return r.Client.Status().Update(context.TODO(), cr)
+	}
+
+	watchNamespaces, err := r.getWatchNamespaces(cr)
+	if err != nil {
+		return err
+	}
+	expected, err := cluster.CreateAllStaticResources(r.getClusterArgs(cr, watchNamespaces))
+	if err != nil {
+		return err
+	}
+
+	var mismatches []string
+	for _, desired := range expected {
+		gvk, err := apiutil.GVKForObject(desired, r.scheme)
+		if err != nil {
+			return err
+		}
+		current := sdk.NewDefaultInstance(desired)
+		if err := r.uncachedClient.Get(context.TODO(), client.ObjectKeyFromObject(desired), current); err != nil {
+			if errors.IsNotFound(err) {
+				mismatches = append(mismatches, fmt.Sprintf("%s %s is missing", gvk.Kind, desired.GetName()))
+				continue
+			}
+			return err
+		}
+		if !clusterResourceMatches(desired, current) {
+			mismatches = append(mismatches, fmt.Sprintf("%s %s does not match", gvk.Kind, desired.GetName()))
+		}
+	}
+	sort.Strings(mismatches)
+
+	condition := conditions.Condition{
+		Type:    migrationsv1alpha1.ConditionClusterResourcesVerified,
+		Status:  corev1.ConditionTrue,
+		Reason:  clusterResourcesVerifiedReason,
+		Message: "Pre-installed cluster resources match the expected ones",
+	}
+	if len(mismatches) > 0 {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = clusterResourcesMismatchReason
+		condition.Message = strings.Join(mismatches, "; ")
+	}
+
+	current := conditions.FindStatusCondition(status.Conditions, migrationsv1alpha1.ConditionClusterResourcesVerified)
+	if current != nil && current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+		return nil
+	}
+	if condition.Status == corev1.ConditionFalse {
+		log.Info("Pre-installed cluster resources do not match", "mismatches", mismatches)
+		r.recorder.Event(cr, corev1.EventTypeWarning, clusterResourcesMismatchReason, condition.Message)
+	}
+	conditions.SetStatusCondition(&status.Conditions, condition)
+	return r.Client.Status().Update(context.TODO(), cr)
+}
+
+// clusterResourceMatches checks that the pre-installed resource provides what the desired one would,
+// extra rules and subjects added by whoever installed it are tolerated
+func clusterResourceMatches(desired, current client.Object) bool {
+	switch desired := desired.(type) {
+	case *extv1.CustomResourceDefinition:
+		return crdMatches(desired, current.(*extv1.CustomResourceDefinition))
+	case *rbacv1.ClusterRole:
+		return containsRules(current.(*rbacv1.ClusterRole).Rules, desired.Rules)
+	case *rbacv1.ClusterRoleBinding:
+		binding := current.(*rbacv1.ClusterRoleBinding)
+		return equality.Semantic.DeepEqual(desired.RoleRef, binding.RoleRef) && containsSubjects(binding.Subjects, desired.Subjects)
+	default:
+		return true
+	}
+}
+
+func crdMatches(desired, current *extv1.CustomResourceDefinition) bool {
+	if desired.Spec.Group != current.Spec.Group ||
+		desired.Spec.Scope != current.Spec.Scope ||
+		desired.Spec.Names.Kind != current.Spec.Names.Kind ||
+		desired.Spec.Names.Plural != current.Spec.Names.Plural {
+		return false
+	}
+
+	for _, version := range desired.Spec.Versions {
+		found := false
+		for _, currentVersion := range current.Spec.Versions {
+			if currentVersion.Name != version.Name {
+				continue
+			}
+			found = currentVersion.Served == version.Served &&
+				equality.Semantic.DeepEqual(version.Schema, currentVersion.Schema) &&
+				equality.Semantic.DeepEqual(version.Subresources, currentVersion.Subresources)
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsRules(rules, expected []rbacv1.PolicyRule) bool {
+	for _, rule := range expected {
+		found := false
+		for _, current := range rules {
+			if equality.Semantic.DeepEqual(rule, current) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsSubjects(subjects, expected []rbacv1.Subject) bool {
+	for _, subject := range expected {
+		found := false
+		for _, current := range subjects {
+			if subject == current {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources/cluster"
+)
+
+// getStaticClusterResource returns a copy of the static cluster resource of the given type and name
+func getStaticClusterResource[T client.Object](name string) T {
+	resources, err := cluster.CreateAllStaticResources(&cluster.FactoryArgs{Namespace: fakeOperatorNamespace})
+	Expect(err).ToNot(HaveOccurred())
+	var result T
+	for _, resource := range resources {
+		if obj, ok := resource.(T); ok && obj.GetName() == name {
+			result = obj.DeepCopyObject().(T)
+		}
+	}
+	Expect(client.Object(result)).ToNot(BeNil(), "static cluster resource %s not found", name)
+	return result
+}
+
+var _ = Describe("Cluster resource verification", func() {
+	const crdName = "virtualmachinestoragemigrations.migrations.kubevirt.io"
+
+	Context("matching a pre-installed resource", func() {
+		It("should match an identical resource", func() {
+			desired := getStaticClusterResource[*rbacv1.ClusterRole](common.ControllerResourceName)
+			current := getStaticClusterResource[*rbacv1.ClusterRole](common.ControllerResourceName)
+			Expect(clusterResourceMatches(desired, current)).To(BeTrue())
+
+			desiredCRD := getStaticClusterResource[*extv1.CustomResourceDefinition](crdName)
+			currentCRD := getStaticClusterResource[*extv1.CustomResourceDefinition](crdName)
+			Expect(clusterResourceMatches(desiredCRD, currentCRD)).To(BeTrue())
+		})
+
+		It("should tolerate extra rules and subjects", func() {
+			desired := getStaticClusterResource[*rbacv1.ClusterRole](common.ControllerResourceName)
+			current := getStaticClusterResource[*rbacv1.ClusterRole](common.ControllerResourceName)
+			current.Rules = append(current.Rules, rbacv1.PolicyRule{
+				APIGroups: []string{""},
+				Resources: []string{"secrets"},
+				Verbs:     []string{"get"},
+			})
+			Expect(clusterResourceMatches(desired, current)).To(BeTrue())
+
+			desiredBinding := getStaticClusterResource[*rbacv1.ClusterRoleBinding](common.ControllerServiceAccountName)
+			currentBinding := getStaticClusterResource[*rbacv1.ClusterRoleBinding](common.ControllerServiceAccountName)
+			currentBinding.Subjects = append(currentBinding.Subjects, rbacv1.Subject{
+				Kind: rbacv1.UserKind,
+				Name: "admin",
+			})
+			Expect(clusterResourceMatches(desiredBinding, currentBinding)).To(BeTrue())
+		})
+
+		It("should not match a role missing a rule", func() {
+			desired := getStaticClusterResource[*rbacv1.ClusterRole](common.ControllerResourceName)
+			current := getStaticClusterResource[*rbacv1.ClusterRole](common.ControllerResourceName)
+			current.Rules = current.Rules[1:]
+			Expect(clusterResourceMatches(desired, current)).To(BeFalse())
+		})
+
+		It("should not match a binding to another role or missing a subject", func() {
+			desired := getStaticClusterResource[*rbacv1.ClusterRoleBinding](common.ControllerServiceAccountName)
+			current := getStaticClusterResource[*rbacv1.ClusterRoleBinding](common.ControllerServiceAccountName)
+			current.RoleRef.Name = "other"
+			Expect(clusterResourceMatches(desired, current)).To(BeFalse())
+
+			current = getStaticClusterResource[*rbacv1.ClusterRoleBinding](common.ControllerServiceAccountName)
+			current.Subjects = nil
+			Expect(clusterResourceMatches(desired, current)).To(BeFalse())
+		})
+
+		It("should not match a CRD with another schema or without a version", func() {
+			desired := getStaticClusterResource[*extv1.CustomResourceDefinition](crdName)
+			current := getStaticClusterResource[*extv1.CustomResourceDefinition](crdName)
+			current.Spec.Versions[0].Schema.OpenAPIV3Schema.Description = "changed"
+			Expect(clusterResourceMatches(desired, current)).To(BeFalse())
+
+			current = getStaticClusterResource[*extv1.CustomResourceDefinition](crdName)
+			current.Spec.Versions[0].Name = "v1"
+			Expect(clusterResourceMatches(desired, current)).To(BeFalse())
+
+			current = getStaticClusterResource[*extv1.CustomResourceDefinition](crdName)
+			current.Spec.Scope = extv1.ClusterScoped
+			Expect(clusterResourceMatches(desired, current)).To(BeFalse())
+		})
+	})
+
+	It("should report missing and mismatching pre-installed resources", func() {
+		os.Setenv("DEPLOY_CLUSTER_RESOURCES", "false")
+		DeferCleanup(os.Unsetenv, "DEPLOY_CLUSTER_RESOURCES")
+
+		cr := newDeployedMigController("0.0.1")
+		r := newFakeReconciler(cr)
+		resources, err := cluster.CreateAllStaticResources(r.getClusterArgs(cr, nil))
+		Expect(err).ToNot(HaveOccurred())
+		applyResources(r.Client, resources)
+
+		Expect(r.verifyClusterResources(cr)).To(Succeed())
+		condition := conditions.FindStatusCondition(cr.Status.Conditions, migrationsv1alpha1.ConditionClusterResourcesVerified)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+
+		By("Changing the pre-installed resources")
+		role := &rbacv1.ClusterRole{}
+		Expect(r.Client.Get(context.TODO(), client.ObjectKey{Name: common.ControllerResourceName}, role)).To(Succeed())
+		role.Rules = role.Rules[1:]
+		Expect(r.Client.Update(context.TODO(), role)).To(Succeed())
+		crd := &extv1.CustomResourceDefinition{}
+		Expect(r.Client.Get(context.TODO(), client.ObjectKey{Name: crdName}, crd)).To(Succeed())
+		Expect(r.Client.Delete(context.TODO(), crd)).To(Succeed())
+
+		Expect(r.verifyClusterResources(cr)).To(Succeed())
+		condition = conditions.FindStatusCondition(cr.Status.Conditions, migrationsv1alpha1.ConditionClusterResourcesVerified)
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(clusterResourcesMismatchReason))
+		Expect(condition.Message).To(Equal(
+			"ClusterRole " + common.ControllerResourceName + " does not match; " +
+				"CustomResourceDefinition " + crdName + " is missing"))
+		Expect(r.recorder.(*record.FakeRecorder).Events).To(Receive(ContainSubstring(clusterResourcesMismatchReason)))
+	})
+})
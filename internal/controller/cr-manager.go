@@ -5,6 +5,7 @@ import (
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -32,16 +33,27 @@ func (r *MigControllerReconciler) Create() client.Object {
 
 // GetDependantResourcesListObjects provides slice of List resources corresponding to migration-controller-dependant resource types
 func (r *MigControllerReconciler) GetDependantResourcesListObjects() []client.ObjectList {
-	return []client.ObjectList{
-		&extv1.CustomResourceDefinitionList{},
-		&rbacv1.ClusterRoleBindingList{},
-		&rbacv1.ClusterRoleList{},
+	lists := []client.ObjectList{
 		&appsv1.DeploymentList{},
 		&corev1.ServiceList{},
 		&rbacv1.RoleBindingList{},
 		&rbacv1.RoleList{},
 		&corev1.ServiceAccountList{},
 	}
+	// pre-installed cluster resources must not be cleaned up as unused
+	if sdk.DeployClusterResources() {
+		lists = append([]client.ObjectList{
+			&rbacv1.ClusterRoleBindingList{},
+			&rbacv1.ClusterRoleList{},
+			&admissionregistrationv1.ValidatingAdmissionPolicyList{},
+			&admissionregistrationv1.ValidatingAdmissionPolicyBindingList{},
+		}, lists...)
+		// neither are the CRDs installed by OLM
+		if !r.clusterArgs.OLMManagedCRDs {
+			lists = append([]client.ObjectList{&extv1.CustomResourceDefinitionList{}}, lists...)
+		}
+	}
+	return lists
 }
 
 // IsCreating checks whether operator config is missing (which means it is create-type reconciliation)
@@ -54,34 +66,68 @@ func (r *MigControllerReconciler) IsCreating(_ client.Object) (bool, error) {
 }
 
 func (r *MigControllerReconciler) getNamespacedArgs(cr *migrationsv1alpha1.MigController) *namespaced.FactoryArgs {
-	result := *r.namespacedArgs
+	result := newNamespacedArgs(r.namespacedArgs, cr)
 
 	if cr != nil {
-		if cr.Spec.ImagePullPolicy != "" {
-			result.PullPolicy = string(cr.Spec.ImagePullPolicy)
-		}
-		if cr.Spec.PriorityClass != nil && string(*cr.Spec.PriorityClass) != "" {
-			result.PriorityClassName = string(*cr.Spec.PriorityClass)
-		}
 		// Verify the priority class name exists.
 		priorityClass := &schedulingv1.PriorityClass{}
 		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: result.PriorityClassName}, priorityClass); err != nil {
 			// Any error we cannot determine if priority class exists.
 			result.PriorityClassName = ""
 		}
+	}
+
+	return result
+}
+
+// newNamespacedArgs applies the MigController spec to the namespaced resource arguments of the operator
+func newNamespacedArgs(base *namespaced.FactoryArgs, cr *migrationsv1alpha1.MigController) *namespaced.FactoryArgs {
+	result := *base
+
+	if cr != nil {
+		if cr.Spec.ImagePullPolicy != "" {
+			result.PullPolicy = string(cr.Spec.ImagePullPolicy)
+		}
+		if cr.Spec.PriorityClass != nil && string(*cr.Spec.PriorityClass) != "" {
+			result.PriorityClassName = string(*cr.Spec.PriorityClass)
+		}
 		result.InfraNodePlacement = &cr.Spec.Infra
 	}
 
 	return &result
 }
 
+func (r *MigControllerReconciler) getClusterArgs(cr *migrationsv1alpha1.MigController, watchNamespaces []string) *cluster.FactoryArgs {
+	return newClusterArgs(r.clusterArgs, cr, watchNamespaces)
+}
+
+// newClusterArgs applies the MigController spec to the cluster resource arguments of the operator
+func newClusterArgs(base *cluster.FactoryArgs, cr *migrationsv1alpha1.MigController, watchNamespaces []string) *cluster.FactoryArgs {
+	result := *base
+	result.WatchNamespaces = watchNamespaces
+
+	if cr.Spec.RBAC != nil {
+		result.AggregateToNamespaceRoles = cr.Spec.RBAC.AggregateToNamespaceRoles
+		result.StorageMigrators = cr.Spec.RBAC.StorageMigrators
+		result.MultiNamespaceStorageMigrators = cr.Spec.RBAC.MultiNamespaceStorageMigrators
+	}
+	result.PlanPolicies = cr.Spec.PlanPolicies
+
+	return &result
+}
+
 // GetAllResources provides slice of resources the migration controller depends on
 func (r *MigControllerReconciler) GetAllResources(crObject client.Object) ([]client.Object, error) {
 	cr := crObject.(*migrationsv1alpha1.MigController)
 	var resources []client.Object
 
-	if true {
-		crs, err := cluster.CreateAllStaticResources(r.clusterArgs)
+	watchNamespaces, err := r.getWatchNamespaces(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	if sdk.DeployClusterResources() {
+		crs, err := cluster.CreateAllStaticResources(r.getClusterArgs(cr, watchNamespaces))
 		if err != nil {
 			sdk.MarkCrFailedHealing(cr, r.Status(cr), "CreateResources", "Unable to create all resources", r.recorder)
 			return nil, err
@@ -90,7 +136,9 @@ func (r *MigControllerReconciler) GetAllResources(crObject client.Object) ([]cli
 		resources = append(resources, crs...)
 	}
 
-	nsrs, err := namespaced.CreateAllResources(r.getNamespacedArgs(cr))
+	namespacedArgs := r.getNamespacedArgs(cr)
+	namespacedArgs.WatchNamespaces = watchNamespaces
+	nsrs, err := namespaced.CreateAllResources(namespacedArgs)
 	if err != nil {
 		sdk.MarkCrFailedHealing(cr, r.Status(cr), "CreateNamespaceResources", "Unable to create all namespaced resources", r.recorder)
 		return nil, err
@@ -98,6 +146,10 @@ func (r *MigControllerReconciler) GetAllResources(crObject client.Object) ([]cli
 
 	resources = append(resources, nsrs...)
 
+	if sdk.IsUpgradeRolledBack(r.Status(cr)) {
+		return r.rollbackResources(cr, resources)
+	}
+
 	// drs, err := cluster.CreateAllDynamicResources(r.clusterArgs)
 	// if err != nil {
 	// 	sdk.MarkCrFailedHealing(cr, r.Status(cr), "CreateDynamicResources", "Unable to create all dynamic resources", r.recorder)
@@ -0,0 +1,59 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+// getDrift computes the changes reconciliation would make to the operand
+func (r *MigControllerReconciler) getDrift(cr *migrationsv1alpha1.MigController) ([]migrationsv1alpha1.MigControllerResourceDrift, error) {
+	drift, err := r.reconciler.DryRun(log, cr, r.namespacedArgs.OperatorVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []migrationsv1alpha1.MigControllerResourceDrift
+	for _, resource := range drift {
+		gvk, err := apiutil.GVKForObject(resource.Object, r.scheme)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, migrationsv1alpha1.MigControllerResourceDrift{
+			Kind:         gvk.Kind,
+			Namespace:    resource.Object.GetNamespace(),
+			Name:         resource.Object.GetName(),
+			Operation:    migrationsv1alpha1.MigControllerDriftOperation(resource.Operation),
+			ChangedPaths: resource.ChangedPaths,
+		})
+	}
+
+	return resources, nil
+}
+
+func (r *MigControllerReconciler) setDrift(cr *migrationsv1alpha1.MigController, drift []migrationsv1alpha1.MigControllerResourceDrift) error {
+	if equality.Semantic.DeepEqual(drift, cr.Status.Drift) {
+		return nil
+	}
+	cr.Status.Drift = drift
+	return r.Client.Status().Update(context.TODO(), cr)
+}
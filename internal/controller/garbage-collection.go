@@ -0,0 +1,95 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources/cluster"
+)
+
+// collectGarbage deletes the resources that follow the MigController spec rather than the operator version,
+// e.g. bindings of removed subjects, of namespaces no longer watched or removed plan policies.
+// Other unused resources are only removed on upgrade
+func (r *MigControllerReconciler) collectGarbage(desired []client.Object) error {
+	lists := []client.ObjectList{
+		&rbacv1.RoleList{},
+		&rbacv1.RoleBindingList{},
+	}
+	if sdk.DeployClusterResources() {
+		lists = append(lists,
+			&rbacv1.ClusterRoleBindingList{},
+			&admissionregistrationv1.ValidatingAdmissionPolicyList{},
+			&admissionregistrationv1.ValidatingAdmissionPolicyBindingList{},
+		)
+	}
+
+	for _, list := range lists {
+		if err := r.Client.List(context.TODO(), list, client.HasLabels{createVersionLabel}); err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			obj := item.(client.Object)
+			if !r.isSpecDrivenResource(obj) || sdk.IsUnmanaged(obj, UnmanagedAnnotation) || isDesired(obj, desired) {
+				continue
+			}
+			log.Info("Deleting unused resource", "type", reflect.TypeOf(obj), "namespace", obj.GetNamespace(), "name", obj.GetName())
+			if err := r.Client.Delete(context.TODO(), obj); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isSpecDrivenResource checks whether the resource is created depending on the MigController spec
+func (r *MigControllerReconciler) isSpecDrivenResource(obj client.Object) bool {
+	switch obj.(type) {
+	case *rbacv1.Role, *rbacv1.RoleBinding:
+		// controller RBAC in the watched namespaces
+		return obj.GetNamespace() != r.namespace
+	case *rbacv1.ClusterRoleBinding:
+		// storage migration subjects
+		return obj.GetName() == cluster.StorageMigrateRoleName || obj.GetName() == cluster.StorageMigrateMultinsRoleName
+	case *admissionregistrationv1.ValidatingAdmissionPolicy, *admissionregistrationv1.ValidatingAdmissionPolicyBinding:
+		// plan policies
+		return true
+	default:
+		return false
+	}
+}
+
+func isDesired(obj client.Object, desired []client.Object) bool {
+	for _, desiredObj := range desired {
+		if sdk.SameResource(obj, desiredObj) {
+			return true
+		}
+	}
+	return false
+}
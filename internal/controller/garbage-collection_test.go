@@ -0,0 +1,160 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	operatorsv2 "github.com/operator-framework/api/pkg/operators/v2"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	sdkr "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources/cluster"
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources/namespaced"
+)
+
+const fakeOperatorNamespace = "kubevirt-migration-system"
+
+// newFakeReconciler creates a reconciler backed by a fake client holding the given objects
+func newFakeReconciler(objs ...client.Object) *MigControllerReconciler {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+	Expect(operatorsv2.AddToScheme(scheme)).To(Succeed())
+	Expect(migrationsv1alpha1.AddToScheme(scheme)).To(Succeed())
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&migrationsv1alpha1.MigController{}).
+		Build()
+
+	r := &MigControllerReconciler{
+		namespace:      fakeOperatorNamespace,
+		Client:         fakeClient,
+		uncachedClient: fakeClient,
+		scheme:         scheme,
+		recorder:       record.NewFakeRecorder(100),
+		namespacedArgs: &namespaced.FactoryArgs{
+			OperatorVersion: "0.0.1",
+			Namespace:       fakeOperatorNamespace,
+			ControllerImage: "kubevirt/kubevirt-migration-operator:latest",
+		},
+		clusterArgs: &cluster.FactoryArgs{
+			Namespace: fakeOperatorNamespace,
+			Client:    fakeClient,
+			Logger:    log,
+		},
+	}
+	callbackDispatcher := callbacks.NewCallbackDispatcher(log, fakeClient, fakeClient, scheme, fakeOperatorNamespace)
+	r.reconciler = sdkr.NewReconciler(
+		r, log, fakeClient,
+		callbackDispatcher, scheme, func() cache.Cache { return nil },
+		createVersionLabel, updateVersionLabel, LastAppliedConfigAnnotation,
+		requeueInterval, finalizerName, true, r.recorder,
+	).
+		WithNamespacedCR().
+		WithWatching(true)
+	r.registerHooks()
+
+	return r
+}
+
+func newNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
+// applyResources creates the given resources the way the operator does, labelled with the operator version
+func applyResources(c client.Client, resources []client.Object) {
+	for _, resource := range resources {
+		obj := resource.DeepCopyObject().(client.Object)
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[createVersionLabel] = "0.0.1"
+		obj.SetLabels(labels)
+		Expect(c.Create(context.TODO(), obj)).To(Succeed())
+	}
+}
+
+var _ = Describe("Garbage collection", func() {
+	It("should delete the controller RBAC of namespaces no longer selected", func() {
+		selected := map[string]string{"migrations": "enabled"}
+		r := newFakeReconciler(
+			newNamespace("selected", selected),
+			newNamespace("deselected", selected),
+		)
+		cr := &migrationsv1alpha1.MigController{
+			ObjectMeta: metav1.ObjectMeta{Name: "migcontroller", Namespace: fakeOperatorNamespace},
+			Spec: migrationsv1alpha1.MigControllerSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: selected},
+			},
+		}
+
+		resources, err := r.GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		applyResources(r.Client, resources)
+
+		roleBindings := &rbacv1.RoleBindingList{}
+		Expect(r.Client.List(context.TODO(), roleBindings, client.InNamespace("deselected"))).To(Succeed())
+		Expect(roleBindings.Items).To(HaveLen(1))
+
+		By("Removing the label from a namespace")
+		namespace := &corev1.Namespace{}
+		Expect(r.Client.Get(context.TODO(), client.ObjectKey{Name: "deselected"}, namespace)).To(Succeed())
+		namespace.Labels = nil
+		Expect(r.Client.Update(context.TODO(), namespace)).To(Succeed())
+
+		resources, err = r.GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.collectGarbage(resources)).To(Succeed())
+
+		roles := &rbacv1.RoleList{}
+		Expect(r.Client.List(context.TODO(), roles, client.InNamespace("deselected"))).To(Succeed())
+		Expect(roles.Items).To(BeEmpty())
+		Expect(r.Client.List(context.TODO(), roleBindings, client.InNamespace("deselected"))).To(Succeed())
+		Expect(roleBindings.Items).To(BeEmpty())
+
+		Expect(r.Client.List(context.TODO(), roles, client.InNamespace("selected"))).To(Succeed())
+		Expect(roles.Items).To(HaveLen(1))
+		Expect(r.Client.List(context.TODO(), roleBindings, client.InNamespace("selected"))).To(Succeed())
+		Expect(roleBindings.Items).To(HaveLen(1))
+
+		By("Keeping the RBAC in the operator namespace")
+		Expect(r.Client.List(context.TODO(), roles, client.InNamespace(fakeOperatorNamespace))).To(Succeed())
+		Expect(roles.Items).ToNot(BeEmpty())
+	})
+})
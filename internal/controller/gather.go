@@ -0,0 +1,57 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources/cluster"
+)
+
+// ListManagedResources lists the operand resources the operator manages, the kinds of
+// GetDependantResourcesListObjects carrying the create version label, as the reconciler finds them
+// on upgrade. The operator environment is read like NewReconciler does, so the CRDs are left out when
+// installed by OLM. Kinds the cluster does not serve are skipped.
+func ListManagedResources(ctx context.Context, c client.Reader) ([]client.Object, error) {
+	r := &MigControllerReconciler{
+		clusterArgs: &cluster.FactoryArgs{
+			OLMManagedCRDs: os.Getenv(operatorConditionNameEnv) != "",
+		},
+	}
+
+	var resources []client.Object
+	for _, list := range r.GetDependantResourcesListObjects() {
+		if err := c.List(ctx, list, client.HasLabels{createVersionLabel}); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			resources = append(resources, item.(client.Object))
+		}
+	}
+	return resources, nil
+}
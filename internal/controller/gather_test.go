@@ -0,0 +1,78 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Gathering managed resources", func() {
+	var c client.Client
+
+	managed := metav1.ObjectMeta{Labels: map[string]string{createVersionLabel: "0.0.1"}}
+
+	getNames := func(resources []client.Object) []string {
+		names := []string{}
+		for _, resource := range resources {
+			names = append(names, fmt.Sprintf("%T/%s", resource, client.ObjectKeyFromObject(resource)))
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		deployment := &appsv1.Deployment{ObjectMeta: *managed.DeepCopy()}
+		deployment.Namespace, deployment.Name = fakeOperatorNamespace, "managed"
+		unmanaged := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: fakeOperatorNamespace, Name: "unmanaged"}}
+		clusterRole := &rbacv1.ClusterRole{ObjectMeta: *managed.DeepCopy()}
+		clusterRole.Name = "managed"
+		crd := &extv1.CustomResourceDefinition{ObjectMeta: *managed.DeepCopy()}
+		crd.Name = "managed"
+		c = newFakeReconciler(deployment, unmanaged, clusterRole, crd).Client
+	})
+
+	It("should list the resources carrying the create version label", func() {
+		resources, err := ListManagedResources(context.Background(), c)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getNames(resources)).To(ConsistOf(
+			"*v1.CustomResourceDefinition//managed",
+			"*v1.ClusterRole//managed",
+			"*v1.Deployment/"+fakeOperatorNamespace+"/managed",
+		))
+	})
+
+	It("should leave out the CRDs when installed by OLM", func() {
+		os.Setenv(operatorConditionNameEnv, operatorConditionName)
+		DeferCleanup(os.Unsetenv, operatorConditionNameEnv)
+		resources, err := ListManagedResources(context.Background(), c)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getNames(resources)).To(ConsistOf(
+			"*v1.ClusterRole//managed",
+			"*v1.Deployment/"+fakeOperatorNamespace+"/managed",
+		))
+	})
+})
@@ -0,0 +1,318 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+	"kubevirt.io/kubevirt-migration-operator/pkg/migrationstatus"
+)
+
+const (
+	historyInterval = 1 * time.Minute
+
+	// maxHistorySize keeps the archive ConfigMap well below the 1MiB object size limit
+	maxHistorySize = 512 * 1024
+
+	collectedTTLReason   = "ttl"
+	collectedLimitReason = "limit"
+)
+
+var collectedMigrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "kubevirt_migration_operator_collected_storage_migrations_total",
+	Help: "The number of finished storage migrations deleted by the operator per kind and reason",
+}, []string{"kind", "reason"})
+
+func init() {
+	metrics.Registry.MustRegister(collectedMigrations)
+}
+
+// historyKinds are the storage migration kinds the history collector deletes, with the spec field
+// referencing their plan
+var historyKinds = map[string]string{
+	"VirtualMachineStorageMigration":               "virtualMachineStorageMigrationPlanRef",
+	"MultiNamespaceVirtualMachineStorageMigration": "multiNamespaceVirtualMachineStorageMigrationPlanRef",
+}
+
+// finishedMigration is a finished storage migration along with when it finished
+type finishedMigration struct {
+	migration  *unstructured.Unstructured
+	finishedAt time.Time
+}
+
+// historyEntry is the archived final status of a collected storage migration
+type historyEntry struct {
+	FinishedAt metav1.Time `json:"finishedAt"`
+	Status     interface{} `json:"status,omitempty"`
+}
+
+// HistoryCollector deletes finished storage migrations once they outlive the TTL or the per plan limit
+// set in the MigController spec.history. The storage migrations live in the namespaces watched by the
+// controller while the manager cache only covers the operator namespace, so instead of watching them it
+// lists them periodically.
+type HistoryCollector struct {
+	client    client.Client
+	reader    client.Reader
+	scheme    *runtime.Scheme
+	namespace string
+	// targetNamespaces are the OperatorGroup target namespaces, nil when all namespaces are targeted
+	targetNamespaces []string
+	now              func() time.Time
+}
+
+// NewHistoryCollector creates the history collector for the MigController in namespace
+func NewHistoryCollector(mgr ctrl.Manager, namespace string) *HistoryCollector {
+	return &HistoryCollector{
+		client:           mgr.GetClient(),
+		reader:           mgr.GetAPIReader(),
+		scheme:           mgr.GetScheme(),
+		namespace:        namespace,
+		targetNamespaces: common.GetTargetNamespaces(),
+		now:              time.Now,
+	}
+}
+
+// SetupWithManager runs the history collector with the manager
+func (c *HistoryCollector) SetupWithManager(mgr ctrl.Manager) error {
+	return mgr.Add(c)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, only the leader collects
+func (c *HistoryCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable
+func (c *HistoryCollector) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := c.collect(ctx); err != nil {
+			log.Error(err, "failed to collect finished storage migrations")
+		}
+	}, historyInterval)
+	return nil
+}
+
+func (c *HistoryCollector) collect(ctx context.Context) error {
+	crs := &migrationsv1alpha1.MigControllerList{}
+	if err := c.client.List(ctx, crs, client.InNamespace(c.namespace)); err != nil {
+		return err
+	}
+	for i := range crs.Items {
+		cr := &crs.Items[i]
+		if cr.DeletionTimestamp != nil || cr.Spec.History == nil || isPaused(cr) {
+			continue
+		}
+		namespaces, err := listWatchNamespaces(ctx, c.client, cr, c.targetNamespaces)
+		if err != nil {
+			return err
+		}
+		for kind, planRef := range historyKinds {
+			if err := c.collectKind(ctx, cr, namespaces, kind, planRef); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// collectKind collects the finished storage migrations of kind in namespaces, in all of them when nil
+func (c *HistoryCollector) collectKind(ctx context.Context, cr *migrationsv1alpha1.MigController, namespaces []string,
+	kind, planRef string) error {
+	if namespaces == nil {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var migrations []unstructured.Unstructured
+	for _, namespace := range namespaces {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind(kind + "List"))
+		if err := c.reader.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			// nothing to collect before the migration CRDs are installed
+			if meta.IsNoMatchError(err) {
+				return nil
+			}
+			return err
+		}
+		migrations = append(migrations, list.Items...)
+	}
+
+	expired, exceeding := selectCollectedMigrations(cr.Spec.History, migrations, planRef, c.now())
+	if err := c.deleteMigrations(ctx, cr, collectedTTLReason, expired); err != nil {
+		return err
+	}
+	return c.deleteMigrations(ctx, cr, collectedLimitReason, exceeding)
+}
+
+func (c *HistoryCollector) deleteMigrations(ctx context.Context, cr *migrationsv1alpha1.MigController, reason string,
+	migrations []finishedMigration) error {
+	for _, finished := range migrations {
+		if err := c.archive(ctx, cr, finished); err != nil {
+			return err
+		}
+		kind := finished.migration.GetKind()
+		log.Info("Deleting finished storage migration", "kind", kind, "migration", client.ObjectKeyFromObject(finished.migration),
+			"finished", finished.finishedAt, "reason", reason)
+		if err := c.client.Delete(ctx, finished.migration); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		collectedMigrations.WithLabelValues(kind, reason).Inc()
+	}
+	return nil
+}
+
+// selectCollectedMigrations returns the finished migrations past the TTL, and the remaining ones beyond
+// the limit of their plan, oldest first
+func selectCollectedMigrations(history *migrationsv1alpha1.MigControllerHistory, migrations []unstructured.Unstructured,
+	planRef string, now time.Time) ([]finishedMigration, []finishedMigration) {
+	var expired, exceeding []finishedMigration
+	plans := map[string][]finishedMigration{}
+	for i := range migrations {
+		migration := &migrations[i]
+		if migration.GetDeletionTimestamp() != nil || !migrationstatus.IsFinished(migration) {
+			continue
+		}
+		finished := finishedMigration{migration: migration, finishedAt: migrationstatus.FinishedAt(migration)}
+		if history.TTLAfterFinished != nil && now.Sub(finished.finishedAt) > history.TTLAfterFinished.Duration {
+			expired = append(expired, finished)
+			continue
+		}
+		plan, _, _ := unstructured.NestedString(migration.Object, "spec", planRef, "name")
+		key := migration.GetNamespace() + "/" + plan
+		plans[key] = append(plans[key], finished)
+	}
+
+	if history.LimitPerPlan != nil {
+		for _, finished := range plans {
+			// newest first, the oldest ones beyond the limit are collected
+			sort.Slice(finished, func(i, j int) bool { return finished[i].finishedAt.After(finished[j].finishedAt) })
+			for i := len(finished) - 1; i >= int(*history.LimitPerPlan); i-- {
+				exceeding = append(exceeding, finished[i])
+			}
+		}
+	}
+
+	byAge := func(migrations []finishedMigration) {
+		sort.SliceStable(migrations, func(i, j int) bool { return migrations[i].finishedAt.Before(migrations[j].finishedAt) })
+	}
+	byAge(expired)
+	byAge(exceeding)
+	return expired, exceeding
+}
+
+// archive records the final status of the storage migration as configured in spec.history.archive
+func (c *HistoryCollector) archive(ctx context.Context, cr *migrationsv1alpha1.MigController, finished finishedMigration) error {
+	status, _, _ := unstructured.NestedFieldCopy(finished.migration.Object, "status")
+	entry := historyEntry{FinishedAt: metav1.NewTime(finished.finishedAt), Status: status}
+
+	switch cr.Spec.History.Archive {
+	case migrationsv1alpha1.MigControllerHistoryArchiveLog:
+		log.Info("Archiving finished storage migration", "kind", finished.migration.GetKind(),
+			"migration", client.ObjectKeyFromObject(finished.migration), "finished", entry.FinishedAt, "status", status)
+		return nil
+	case migrationsv1alpha1.MigControllerHistoryArchiveConfigMap:
+		return c.archiveToConfigMap(ctx, cr, finished.migration, entry)
+	default:
+		return nil
+	}
+}
+
+func (c *HistoryCollector) archiveToConfigMap(ctx context.Context, cr *migrationsv1alpha1.MigController,
+	migration *unstructured.Unstructured, entry historyEntry) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: c.namespace, Name: common.HistoryConfigMapName}
+	create := false
+	if err := c.client.Get(ctx, key, cm); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		create = true
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      common.HistoryConfigMapName,
+				Namespace: c.namespace,
+				Labels:    map[string]string{"operator.migrations.kubevirt.io": ""},
+			},
+		}
+		if err := controllerutil.SetControllerReference(cr, cm, c.scheme); err != nil {
+			return err
+		}
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	// only the characters of kubernetes names are used, so the key is a valid ConfigMap key
+	cm.Data[strings.Join([]string{migration.GetNamespace(), strings.ToLower(migration.GetKind()), migration.GetName()}, ".")] = string(value)
+	trimHistory(cm.Data, maxHistorySize)
+
+	if create {
+		return c.client.Create(ctx, cm)
+	}
+	return c.client.Update(ctx, cm)
+}
+
+// trimHistory drops the entries that finished first until the archive fits in maxSize
+func trimHistory(data map[string]string, maxSize int) {
+	size := 0
+	for key, value := range data {
+		size += len(key) + len(value)
+	}
+	if size <= maxSize {
+		return
+	}
+
+	keys := make([]string, 0, len(data))
+	finishedAt := map[string]time.Time{}
+	for key, value := range data {
+		keys = append(keys, key)
+		entry := historyEntry{}
+		if err := json.Unmarshal([]byte(value), &entry); err == nil {
+			finishedAt[key] = entry.FinishedAt.Time
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return finishedAt[keys[i]].Before(finishedAt[keys[j]]) })
+	for _, key := range keys {
+		if size <= maxSize {
+			return
+		}
+		size -= len(key) + len(data[key])
+		delete(data, key)
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+var historyNow = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newHistoryMigration creates a storage migration of plan in the given phase whose conditions last
+// transitioned age ago
+func newHistoryMigration(namespace, name, plan, phase string, age time.Duration) unstructured.Unstructured {
+	migration := unstructured.Unstructured{}
+	migration.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind("VirtualMachineStorageMigration"))
+	migration.SetNamespace(namespace)
+	migration.SetName(name)
+	migration.SetCreationTimestamp(metav1.NewTime(historyNow.Add(-age - time.Hour)))
+	Expect(unstructured.SetNestedField(migration.Object, plan, "spec", historyKinds[migration.GetKind()], "name")).To(Succeed())
+	Expect(unstructured.SetNestedField(migration.Object, phase, "status", "phase")).To(Succeed())
+	Expect(unstructured.SetNestedSlice(migration.Object, []interface{}{
+		map[string]interface{}{"type": "Ready", "lastTransitionTime": historyNow.Add(-age).Format(time.RFC3339)},
+	}, "status", "conditions")).To(Succeed())
+	return migration
+}
+
+func deletingMigration(migration unstructured.Unstructured) unstructured.Unstructured {
+	deletionTimestamp := metav1.NewTime(historyNow)
+	migration.SetDeletionTimestamp(&deletionTimestamp)
+	return migration
+}
+
+func getMigrationNames(migrations []finishedMigration) []string {
+	names := []string{}
+	for _, finished := range migrations {
+		names = append(names, finished.migration.GetNamespace()+"/"+finished.migration.GetName())
+	}
+	return names
+}
+
+func newHistoryEntry(age time.Duration) string {
+	value, err := json.Marshal(historyEntry{FinishedAt: metav1.NewTime(historyNow.Add(-age))})
+	Expect(err).ToNot(HaveOccurred())
+	return string(value)
+}
+
+var _ = Describe("Storage migration history", func() {
+	planRef := historyKinds["VirtualMachineStorageMigration"]
+
+	DescribeTable("should select the finished storage migrations to collect",
+		func(history migrationsv1alpha1.MigControllerHistory, migrations []unstructured.Unstructured, expired, exceeding []string) {
+			expiredMigrations, exceedingMigrations := selectCollectedMigrations(&history, migrations, planRef, historyNow)
+			Expect(getMigrationNames(expiredMigrations)).To(Equal(expired))
+			Expect(getMigrationNames(exceedingMigrations)).To(Equal(exceeding))
+		},
+		Entry("without limits",
+			migrationsv1alpha1.MigControllerHistory{},
+			[]unstructured.Unstructured{
+				newHistoryMigration("ns1", "old", "plan", "Completed", 100*time.Hour),
+			},
+			[]string{}, []string{}),
+		Entry("past the TTL",
+			migrationsv1alpha1.MigControllerHistory{TTLAfterFinished: &metav1.Duration{Duration: time.Hour}},
+			[]unstructured.Unstructured{
+				newHistoryMigration("ns1", "recent", "plan", "Completed", 30*time.Minute),
+				newHistoryMigration("ns1", "older", "plan", "Failed", 2*time.Hour),
+				newHistoryMigration("ns2", "oldest", "plan", "Cancelled", 3*time.Hour),
+			},
+			[]string{"ns2/oldest", "ns1/older"}, []string{}),
+		Entry("beyond the limit of each plan, oldest first",
+			migrationsv1alpha1.MigControllerHistory{LimitPerPlan: ptr.To[int32](1)},
+			[]unstructured.Unstructured{
+				newHistoryMigration("ns1", "second", "plan", "Completed", 2*time.Hour),
+				newHistoryMigration("ns1", "newest", "plan", "Completed", time.Hour),
+				newHistoryMigration("ns1", "first", "plan", "Completed", 3*time.Hour),
+				newHistoryMigration("ns1", "other-plan", "other", "Completed", 5*time.Hour),
+				newHistoryMigration("ns2", "same-plan-name", "plan", "Completed", 4*time.Hour),
+			},
+			[]string{}, []string{"ns1/first", "ns1/second"}),
+		Entry("past the TTL before counting the limit",
+			migrationsv1alpha1.MigControllerHistory{
+				TTLAfterFinished: &metav1.Duration{Duration: 4 * time.Hour},
+				LimitPerPlan:     ptr.To[int32](1),
+			},
+			[]unstructured.Unstructured{
+				newHistoryMigration("ns1", "expired", "plan", "Completed", 5*time.Hour),
+				newHistoryMigration("ns1", "exceeding", "plan", "Completed", 3*time.Hour),
+				newHistoryMigration("ns1", "kept", "plan", "Completed", time.Hour),
+			},
+			[]string{"ns1/expired"}, []string{"ns1/exceeding"}),
+		Entry("never in progress or deleted storage migrations",
+			migrationsv1alpha1.MigControllerHistory{
+				TTLAfterFinished: &metav1.Duration{Duration: time.Hour},
+				LimitPerPlan:     ptr.To[int32](1),
+			},
+			[]unstructured.Unstructured{
+				newHistoryMigration("ns1", "running", "plan", "Running", 5*time.Hour),
+				newHistoryMigration("ns1", "pending", "plan", "", 4*time.Hour),
+				deletingMigration(newHistoryMigration("ns1", "deleting", "plan", "Completed", 3*time.Hour)),
+				newHistoryMigration("ns1", "recent", "plan", "Completed", 30*time.Minute),
+			},
+			[]string{}, []string{}),
+	)
+
+	It("should only collect multi namespace storage migrations finished in every namespace", func() {
+		multiPlanRef := historyKinds["MultiNamespaceVirtualMachineStorageMigration"]
+		newMultiNamespaceMigration := func(name string, phases ...string) unstructured.Unstructured {
+			migration := unstructured.Unstructured{}
+			migration.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind("MultiNamespaceVirtualMachineStorageMigration"))
+			migration.SetNamespace("ns1")
+			migration.SetName(name)
+			migration.SetCreationTimestamp(metav1.NewTime(historyNow.Add(-2 * time.Hour)))
+			var namespaces []interface{}
+			for _, phase := range phases {
+				namespaces = append(namespaces, map[string]interface{}{"phase": phase})
+			}
+			Expect(unstructured.SetNestedSlice(migration.Object, namespaces, "status", "namespaces")).To(Succeed())
+			return migration
+		}
+
+		history := &migrationsv1alpha1.MigControllerHistory{TTLAfterFinished: &metav1.Duration{Duration: time.Hour}}
+		expired, exceeding := selectCollectedMigrations(history, []unstructured.Unstructured{
+			newMultiNamespaceMigration("finished", "Completed", "Failed"),
+			newMultiNamespaceMigration("partially-finished", "Completed", "Running"),
+			newMultiNamespaceMigration("not-started"),
+		}, multiPlanRef, historyNow)
+		Expect(getMigrationNames(expired)).To(Equal([]string{"ns1/finished"}))
+		Expect(exceeding).To(BeEmpty())
+	})
+
+	DescribeTable("should only collect storage migrations in the watched namespaces",
+		func(spec migrationsv1alpha1.MigControllerSpec, expected []string) {
+			cr := newDeployedMigController("0.0.1")
+			cr.Spec = spec
+			cr.Spec.History = &migrationsv1alpha1.MigControllerHistory{TTLAfterFinished: &metav1.Duration{Duration: time.Hour}}
+			objs := []client.Object{cr}
+			for _, namespace := range []string{"ns1", "ns2"} {
+				migration := newHistoryMigration(namespace, "expired", "plan", "Completed", 2*time.Hour)
+				objs = append(objs, &migration)
+			}
+			r := newFakeReconciler(objs...)
+			collector := &HistoryCollector{
+				client:    r.Client,
+				reader:    r.Client,
+				scheme:    r.scheme,
+				namespace: fakeOperatorNamespace,
+				now:       func() time.Time { return historyNow },
+			}
+			Expect(collector.collect(context.Background())).To(Succeed())
+
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind("VirtualMachineStorageMigrationList"))
+			Expect(r.Client.List(context.Background(), list)).To(Succeed())
+			names := []string{}
+			for _, migration := range list.Items {
+				names = append(names, migration.GetNamespace()+"/"+migration.GetName())
+			}
+			Expect(names).To(ConsistOf(expected))
+		},
+		Entry("in all namespaces without restriction", migrationsv1alpha1.MigControllerSpec{}, []string{}),
+		Entry("in the watched namespaces", migrationsv1alpha1.MigControllerSpec{WatchNamespaces: []string{"ns1"}},
+			[]string{"ns2/expired"}),
+		Entry("in no namespace when the restriction matches none", migrationsv1alpha1.MigControllerSpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"migrations": "true"}},
+		}, []string{"ns1/expired", "ns2/expired"}),
+	)
+
+	DescribeTable("should drop the archived entries that finished first until the archive fits",
+		func(maxSize int, expected []string) {
+			data := map[string]string{
+				"ns1.virtualmachinestoragemigration.new":    newHistoryEntry(time.Hour),
+				"ns1.virtualmachinestoragemigration.middle": newHistoryEntry(2 * time.Hour),
+				"ns1.virtualmachinestoragemigration.old":    newHistoryEntry(3 * time.Hour),
+				"ns1.virtualmachinestoragemigration.broken": "{",
+			}
+			entrySize := func(key string) int { return len(key) + len(data[key]) }
+			size := 0
+			for key := range data {
+				size += entrySize(key)
+			}
+			if maxSize < 0 {
+				// the size of the entries listed as expected
+				maxSize = 0
+				for _, name := range expected {
+					maxSize += entrySize("ns1.virtualmachinestoragemigration." + name)
+				}
+			} else {
+				maxSize += size
+			}
+
+			trimHistory(data, maxSize)
+			var names []string
+			for key := range data {
+				names = append(names, strings.TrimPrefix(key, "ns1.virtualmachinestoragemigration."))
+			}
+			Expect(names).To(ConsistOf(expected))
+		},
+		Entry("keeping all entries when they fit", 0, []string{"new", "middle", "old", "broken"}),
+		Entry("dropping the unreadable and oldest entries", -1, []string{"new", "middle"}),
+		Entry("keeping only the newest entry", -1, []string{"new"}),
+		Entry("dropping all entries", -1, []string{}),
+	)
+})
@@ -58,6 +58,10 @@ const (
 	updateVersionLabel = "operator.migrations.kubevirt.io/updateVersion"
 	// LastAppliedConfigAnnotation is the annotation that holds the last resource state which we put on resources under our governance
 	LastAppliedConfigAnnotation = "operator.migrations.kubevirt.io/lastAppliedConfiguration"
+	// UnmanagedAnnotation set to "true" on a resource under our governance stops the operator from reconciling it
+	UnmanagedAnnotation = "operator.migrations.kubevirt.io/unmanaged"
+	// IgnoredFieldsAnnotation holds a JSON list of JSON pointers to fields of a resource under our governance that the operator leaves alone
+	IgnoredFieldsAnnotation = "operator.migrations.kubevirt.io/ignoredFields"
 
 	requeueInterval = 1 * time.Minute
 )
@@ -71,6 +75,11 @@ type MigControllerReconciler struct {
 	clusterArgs    *cluster.FactoryArgs
 	reconciler     *sdkr.Reconciler
 	namespace      string
+	uncachedClient client.Client
+	// operatorConditionName is the OLM OperatorCondition of the operator, empty when not installed by OLM
+	operatorConditionName string
+	// targetNamespaces are the OperatorGroup target namespaces, nil when all namespaces are targeted
+	targetNamespaces []string
 
 	getCache   func() cache.Cache
 	controller controller.Controller
@@ -81,10 +90,13 @@ func NewReconciler(mgr manager.Manager) (*MigControllerReconciler, error) {
 	var namespacedArgs namespaced.FactoryArgs
 	namespace := GetNamespace("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
 	restClient := mgr.GetClient()
+	operatorConditionName := os.Getenv(operatorConditionNameEnv)
 	clusterArgs := &cluster.FactoryArgs{
 		Namespace: namespace,
 		Client:    restClient,
 		Logger:    log,
+		// the CSV owns the storage migration CRDs, OLM creates and removes them
+		OLMManagedCRDs: operatorConditionName != "",
 	}
 
 	err := envconfig.Process("", &namespacedArgs)
@@ -113,7 +125,11 @@ func NewReconciler(mgr manager.Manager) (*MigControllerReconciler, error) {
 		namespacedArgs: &namespacedArgs,
 		clusterArgs:    clusterArgs,
 		namespace:      namespace,
+		uncachedClient: uncachedClient,
 		getCache:       mgr.GetCache,
+
+		operatorConditionName: operatorConditionName,
+		targetNamespaces:      common.GetTargetNamespaces(),
 	}
 	callbackDispatcher := callbacks.NewCallbackDispatcher(log, restClient, uncachedClient, scheme, namespace)
 	r.reconciler = sdkr.NewReconciler(
@@ -133,9 +149,13 @@ func NewReconciler(mgr manager.Manager) (*MigControllerReconciler, error) {
 // +kubebuilder:rbac:groups=migrations.kubevirt.io,resources=migcontrollers/finalizers,verbs=update
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=apps,namespace=kubevirt-migration-system,resources=deployments,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=apps,namespace=kubevirt-migration-system,resources=controllerrevisions,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=core,namespace=kubevirt-migration-system,resources=serviceaccounts,verbs=list;watch;create;update;delete
-// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,namespace=kubevirt-migration-system,resources=roles;rolebindings,verbs=list;watch;create;update;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=list;watch;create;update;delete
+// +kubebuilder:rbac:groups=operators.coreos.com,namespace=kubevirt-migration-system,resources=operatorconditions,verbs=get;update
 // +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingadmissionpolicies;validatingadmissionpolicybindings,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions;customresourcedefinitions/status,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=list;watch;create;update;delete
 
@@ -149,10 +169,12 @@ func NewReconciler(mgr manager.Manager) (*MigControllerReconciler, error) {
 // +kubebuilder:rbac:groups=migrations.kubevirt.io,resources=migclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=migrations.kubevirt.io,resources=migclusters/finalizers,verbs=update
 // +kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=list;watch
-// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=list;watch;update
-// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=list;watch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=core,resources=resourcequotas,verbs=list
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=kubevirt.io,resources=kubevirts,verbs=list;watch
 // +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstances,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -182,12 +204,101 @@ func (r *MigControllerReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return reconcile.Result{}, err
 	}
 
+	if cr.DeletionTimestamp == nil {
+		if isPaused(cr) {
+			if err := r.reconcilePaused(cr); err != nil {
+				log.Error(err, "failed to reconcile paused MigController")
+				return reconcile.Result{}, err
+			}
+			desired, err := r.GetAllResources(cr)
+			if err != nil {
+				log.Error(err, "failed to get desired resources")
+				return reconcile.Result{}, err
+			}
+			// the operand is left alone, what is reported about it and the storage migrations is kept current
+			if err := r.updateStatusReports(cr, desired); err != nil {
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{RequeueAfter: requeueInterval}, nil
+		}
+		if err := r.resumeReconciliation(cr); err != nil {
+			log.Error(err, "failed to resume reconciliation")
+			return reconcile.Result{}, err
+		}
+	}
+
 	res, err := r.reconciler.Reconcile(req, operatorVersion, log)
 	if err != nil {
 		log.Error(err, "failed to reconcile")
+		return res, err
 	}
 
-	return res, err
+	// re-read the CR, the SDK reconciler works on its own copy
+	if err := r.Client.Get(context.TODO(), crKey, cr); err != nil {
+		return res, client.IgnoreNotFound(err)
+	}
+	if cr.DeletionTimestamp == nil {
+		if err := r.recordRevision(cr, operatorVersion); err != nil {
+			log.Error(err, "failed to record operand revision")
+			return reconcile.Result{}, err
+		}
+		if err := r.clearForcedRollout(cr); err != nil {
+			log.Error(err, "failed to clear forced rollout")
+			return reconcile.Result{}, err
+		}
+		if err := r.updateWatchedNamespaces(cr); err != nil {
+			log.Error(err, "failed to report watched namespaces")
+			return reconcile.Result{}, err
+		}
+		desired, err := r.GetAllResources(cr)
+		if err != nil {
+			log.Error(err, "failed to get desired resources")
+			return reconcile.Result{}, err
+		}
+		if err := r.collectGarbage(desired); err != nil {
+			log.Error(err, "failed to delete unused resources")
+			return reconcile.Result{}, err
+		}
+		if err := r.updateStatusReports(cr, desired); err != nil {
+			return reconcile.Result{}, err
+		}
+		// changes are applied again, nothing is drifting
+		if err := r.setDrift(cr, nil); err != nil {
+			return reconcile.Result{}, err
+		}
+		// storage migrations are not watched, the quota usage is refreshed periodically
+		if cr.Spec.Quota != nil && !res.Requeue && res.RequeueAfter == 0 {
+			res.RequeueAfter = requeueInterval
+		}
+	}
+
+	return res, nil
+}
+
+// updateStatusReports refreshes the OperatorCondition and the MigController status fields reporting on the
+// operand and the storage migrations, none of them changes the operand
+func (r *MigControllerReconciler) updateStatusReports(cr *migrationsv1alpha1.MigController, desired []client.Object) error {
+	if err := r.updateOperatorCondition(cr); err != nil {
+		log.Error(err, "failed to update OperatorCondition")
+		return err
+	}
+	if err := r.updateUnmanagedResources(cr); err != nil {
+		log.Error(err, "failed to report unmanaged resources")
+		return err
+	}
+	if err := r.verifyClusterResources(cr); err != nil {
+		log.Error(err, "failed to verify cluster resources")
+		return err
+	}
+	if err := r.updateActivePolicies(cr, desired); err != nil {
+		log.Error(err, "failed to report active plan policies")
+		return err
+	}
+	if err := r.updateQuotaUsage(cr); err != nil {
+		log.Error(err, "failed to report quota usage")
+		return err
+	}
+	return nil
 }
 
 // createOperatorConfig creates operator config map
@@ -24,6 +24,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
@@ -35,11 +36,13 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	record "k8s.io/client-go/tools/record"
 
 	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
 	sdkr "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
 	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
 	"kubevirt.io/kubevirt-migration-operator/pkg/resources/cluster"
 	"kubevirt.io/kubevirt-migration-operator/pkg/resources/namespaced"
 )
@@ -79,9 +82,11 @@ var _ = Describe("MigController Controller", func() {
 			Expect(k8sClient.Status().Update(ctx, migcontroller)).To(Succeed())
 
 			controllerReconciler = &MigControllerReconciler{
-				namespace: testNamespace,
-				Client:    k8sClient,
-				scheme:    k8sClient.Scheme(),
+				namespace:      testNamespace,
+				Client:         k8sClient,
+				uncachedClient: k8sClient,
+				scheme:         k8sClient.Scheme(),
+				recorder:       recorder,
 				namespacedArgs: &namespaced.FactoryArgs{
 					OperatorVersion: "0.0.1",
 					Namespace:       testNamespace,
@@ -153,6 +158,60 @@ var _ = Describe("MigController Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 
+		It("should defer controller rollout while storage migrations are running", func() {
+			By("Reconciling the created resource")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Starting a storage migration")
+			migration := &unstructured.Unstructured{}
+			migration.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind("VirtualMachineStorageMigration"))
+			migration.SetName("running-migration")
+			migration.SetNamespace(testNamespace)
+			Eventually(func() error {
+				return k8sClient.Create(ctx, migration)
+			}, time.Second*15, time.Second*1).Should(Succeed())
+			Expect(unstructured.SetNestedSlice(migration.Object, []interface{}{
+				map[string]interface{}{"name": "vm", "progress": "50%"},
+			}, "status", "runningMigrations")).To(Succeed())
+			Expect(k8sClient.Status().Update(ctx, migration)).To(Succeed())
+
+			By("Changing the controller placement")
+			resource := &migrationsv1alpha1.MigController{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Infra.NodeSelector = map[string]string{"infra": "true"}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			deploymentKey := types.NamespacedName{Name: common.ControllerResourceName, Namespace: testNamespace}
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.NodeSelector).To(BeEmpty())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(conditions.IsStatusConditionTrue(resource.Status.Conditions, migrationsv1alpha1.ConditionRolloutPending)).To(BeTrue())
+
+			By("Forcing the rollout")
+			resource.Annotations = map[string]string{migrationsv1alpha1.ForceRolloutAnnotation: "true"}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue("infra", "true"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(conditions.FindStatusCondition(resource.Status.Conditions, migrationsv1alpha1.ConditionRolloutPending)).To(BeNil())
+			Expect(k8sClient.Delete(ctx, migration)).To(Succeed())
+		})
+
 		DescribeTable("check all expected cluster role rules exist", func(role string, rules []rbacv1.PolicyRule) {
 			resource := &migrationsv1alpha1.MigController{}
 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
@@ -0,0 +1,108 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	operatorsv2 "github.com/operator-framework/api/pkg/operators/v2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/migrationstatus"
+)
+
+const (
+	// operatorConditionNameEnv is injected by OLM into the operator deployment
+	operatorConditionNameEnv = "OPERATOR_CONDITION_NAME"
+
+	upgradeableReason           = "AsExpected"
+	migrationsRunningReason     = "MigrationsRunning"
+	migControllerDegradedReason = "MigControllerDegraded"
+)
+
+// updateOperatorCondition tells OLM whether the operator can be upgraded, upgrades are held
+// while storage migrations are in progress or the MigController is degraded
+func (r *MigControllerReconciler) updateOperatorCondition(cr *migrationsv1alpha1.MigController) error {
+	if r.operatorConditionName == "" {
+		// not installed by OLM
+		return nil
+	}
+
+	upgradeable := metav1.Condition{
+		Type:    operatorsv2.Upgradeable,
+		Status:  metav1.ConditionTrue,
+		Reason:  upgradeableReason,
+		Message: "The operator is upgradeable",
+	}
+	inProgress, err := r.countUnfinishedMigrations()
+	if err != nil {
+		return err
+	}
+	if inProgress > 0 {
+		upgradeable.Status = metav1.ConditionFalse
+		upgradeable.Reason = migrationsRunningReason
+		upgradeable.Message = fmt.Sprintf("%d storage migrations are in progress", inProgress)
+	} else if conditions.IsStatusConditionTrue(cr.Status.Conditions, conditions.ConditionDegraded) {
+		upgradeable.Status = metav1.ConditionFalse
+		upgradeable.Reason = migControllerDegradedReason
+		upgradeable.Message = fmt.Sprintf("MigController %s is degraded", cr.Name)
+	}
+
+	operatorCondition := &operatorsv2.OperatorCondition{}
+	key := client.ObjectKey{Namespace: r.namespace, Name: r.operatorConditionName}
+	if err := r.uncachedClient.Get(context.TODO(), key, operatorCondition); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("OperatorCondition not found", "name", r.operatorConditionName)
+			return nil
+		}
+		return err
+	}
+
+	current := meta.FindStatusCondition(operatorCondition.Spec.Conditions, operatorsv2.Upgradeable)
+	if current != nil && current.Status == upgradeable.Status && current.Reason == upgradeable.Reason && current.Message == upgradeable.Message {
+		return nil
+	}
+
+	upgradeable.ObservedGeneration = operatorCondition.Generation
+	meta.SetStatusCondition(&operatorCondition.Spec.Conditions, upgradeable)
+	log.Info("Updating OperatorCondition", "name", r.operatorConditionName, "upgradeable", upgradeable.Status, "reason", upgradeable.Reason)
+	return r.uncachedClient.Update(context.TODO(), operatorCondition)
+}
+
+// countUnfinishedMigrations returns the number of storage migrations that did not reach a terminal phase yet. Unlike
+// the virtual machines being migrated, these also cover the plans executing in between two virtual machine migrations
+func (r *MigControllerReconciler) countUnfinishedMigrations() (int, error) {
+	unfinished := 0
+	for _, kind := range []string{"VirtualMachineStorageMigrationList", "MultiNamespaceVirtualMachineStorageMigrationList"} {
+		migrations, err := r.listMigrations(kind)
+		if err != nil {
+			return 0, err
+		}
+		for i := range migrations {
+			if !migrationstatus.IsFinished(&migrations[i]) {
+				unfinished++
+			}
+		}
+	}
+	return unfinished, nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	operatorsv2 "github.com/operator-framework/api/pkg/operators/v2"
+	corev1 "k8s.io/api/core/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+const operatorConditionName = "kubevirt-migration-operator.v0.0.1"
+
+var _ = Describe("OperatorCondition", func() {
+	var (
+		r                 *MigControllerReconciler
+		cr                *migrationsv1alpha1.MigController
+		operatorCondition *operatorsv2.OperatorCondition
+	)
+
+	getUpgradeable := func() *metav1.Condition {
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(operatorCondition), operatorCondition)).To(Succeed())
+		return meta.FindStatusCondition(operatorCondition.Spec.Conditions, operatorsv2.Upgradeable)
+	}
+
+	BeforeEach(func() {
+		cr = newDeployedMigController("0.0.1")
+		operatorCondition = &operatorsv2.OperatorCondition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      operatorConditionName,
+				Namespace: fakeOperatorNamespace,
+			},
+		}
+		r = newFakeReconciler(cr, operatorCondition)
+		r.operatorConditionName = operatorConditionName
+	})
+
+	It("should report Upgradeable=False while storage migrations are in progress", func() {
+		Expect(r.updateOperatorCondition(cr)).To(Succeed())
+		upgradeable := getUpgradeable()
+		Expect(upgradeable).ToNot(BeNil())
+		Expect(upgradeable.Status).To(Equal(metav1.ConditionTrue))
+		Expect(upgradeable.Reason).To(Equal(upgradeableReason))
+
+		By("Starting a storage migration")
+		migration := newRunningMigration()
+		Expect(r.Client.Create(context.TODO(), migration)).To(Succeed())
+		Expect(r.updateOperatorCondition(cr)).To(Succeed())
+		upgradeable = getUpgradeable()
+		Expect(upgradeable.Status).To(Equal(metav1.ConditionFalse))
+		Expect(upgradeable.Reason).To(Equal(migrationsRunningReason))
+		Expect(upgradeable.Message).To(Equal("1 storage migrations are in progress"))
+
+		By("Finishing the storage migration")
+		Expect(r.Client.Delete(context.TODO(), migration)).To(Succeed())
+		Expect(r.updateOperatorCondition(cr)).To(Succeed())
+		upgradeable = getUpgradeable()
+		Expect(upgradeable.Status).To(Equal(metav1.ConditionTrue))
+		Expect(upgradeable.Reason).To(Equal(upgradeableReason))
+	})
+
+	It("should report Upgradeable=False in between the virtual machine migrations of a plan", func() {
+		migration := newRunningMigration()
+		Expect(unstructured.SetNestedField(migration.Object, "Running", "status", "phase")).To(Succeed())
+		unstructured.RemoveNestedField(migration.Object, "status", "runningMigrations")
+		Expect(r.Client.Create(context.TODO(), migration)).To(Succeed())
+		Expect(r.updateOperatorCondition(cr)).To(Succeed())
+		upgradeable := getUpgradeable()
+		Expect(upgradeable.Status).To(Equal(metav1.ConditionFalse))
+		Expect(upgradeable.Reason).To(Equal(migrationsRunningReason))
+
+		By("Completing the storage migration")
+		Expect(unstructured.SetNestedField(migration.Object, "Completed", "status", "phase")).To(Succeed())
+		Expect(r.Client.Update(context.TODO(), migration)).To(Succeed())
+		Expect(r.updateOperatorCondition(cr)).To(Succeed())
+		upgradeable = getUpgradeable()
+		Expect(upgradeable.Status).To(Equal(metav1.ConditionTrue))
+		Expect(upgradeable.Reason).To(Equal(upgradeableReason))
+	})
+
+	It("should report Upgradeable=False while the MigController is degraded", func() {
+		conditions.SetStatusCondition(&cr.Status.Conditions, conditions.Condition{
+			Type:   conditions.ConditionDegraded,
+			Status: corev1.ConditionTrue,
+		})
+		Expect(r.updateOperatorCondition(cr)).To(Succeed())
+		upgradeable := getUpgradeable()
+		Expect(upgradeable.Status).To(Equal(metav1.ConditionFalse))
+		Expect(upgradeable.Reason).To(Equal(migControllerDegradedReason))
+
+		conditions.SetStatusCondition(&cr.Status.Conditions, conditions.Condition{
+			Type:   conditions.ConditionDegraded,
+			Status: corev1.ConditionFalse,
+		})
+		Expect(r.updateOperatorCondition(cr)).To(Succeed())
+		upgradeable = getUpgradeable()
+		Expect(upgradeable.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("should leave OperatorConditions alone when not installed by OLM", func() {
+		r.operatorConditionName = ""
+		Expect(r.updateOperatorCondition(cr)).To(Succeed())
+		Expect(getUpgradeable()).To(BeNil())
+	})
+
+	It("should leave the storage migration CRDs to OLM", func() {
+		r.clusterArgs.OLMManagedCRDs = true
+		resources, err := r.GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resources).ToNot(BeEmpty())
+		for _, resource := range resources {
+			Expect(resource).ToNot(BeAssignableToTypeOf(&extv1.CustomResourceDefinition{}))
+		}
+		Expect(r.GetDependantResourcesListObjects()).ToNot(ContainElement(BeAssignableToTypeOf(&extv1.CustomResourceDefinitionList{})))
+
+		r.clusterArgs.OLMManagedCRDs = false
+		resources, err = r.GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resources).To(ContainElement(BeAssignableToTypeOf(&extv1.CustomResourceDefinition{})))
+		Expect(r.GetDependantResourcesListObjects()).To(ContainElement(BeAssignableToTypeOf(&extv1.CustomResourceDefinitionList{})))
+	})
+})
@@ -0,0 +1,101 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+const (
+	reconciliationPausedReason  = "ReconciliationPaused"
+	reconciliationResumedReason = "ReconciliationResumed"
+)
+
+// isPaused checks whether applying changes to the operand is paused
+func isPaused(cr *migrationsv1alpha1.MigController) bool {
+	return cr.Spec.Paused || cr.Annotations[migrationsv1alpha1.PauseAnnotation] == "true"
+}
+
+// reconcilePaused refreshes the MigController status without touching the operand,
+// the changes reconciliation would make are reported as drift
+func (r *MigControllerReconciler) reconcilePaused(cr *migrationsv1alpha1.MigController) error {
+	status := r.Status(cr)
+	currentConditionValues := sdk.GetConditionValues(status.Conditions)
+
+	// the operand is missing when paused before it was ever deployed
+	if _, err := r.reconciler.CheckDegraded(log, cr); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	if !conditions.IsStatusConditionTrue(status.Conditions, migrationsv1alpha1.ConditionReconciliationPaused) {
+		log.Info("Reconciliation paused")
+		r.recorder.Event(cr, corev1.EventTypeNormal, reconciliationPausedReason, "Reconciliation of operand resources paused")
+	}
+	conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
+		Type:    migrationsv1alpha1.ConditionReconciliationPaused,
+		Status:  corev1.ConditionTrue,
+		Reason:  reconciliationPausedReason,
+		Message: "Operand resources are not created, updated or deleted while paused",
+	})
+
+	drift, err := r.getDrift(cr)
+	if err != nil {
+		return err
+	}
+
+	if !sdk.ConditionsChanged(currentConditionValues, sdk.GetConditionValues(status.Conditions)) &&
+		equality.Semantic.DeepEqual(drift, cr.Status.Drift) {
+		return nil
+	}
+	cr.Status.Drift = drift
+	return r.Client.Status().Update(context.TODO(), cr)
+}
+
+// resumeReconciliation reports the changes deferred while paused, they are applied by the following reconcile
+func (r *MigControllerReconciler) resumeReconciliation(cr *migrationsv1alpha1.MigController) error {
+	status := r.Status(cr)
+	if conditions.FindStatusCondition(status.Conditions, migrationsv1alpha1.ConditionReconciliationPaused) == nil {
+		return nil
+	}
+
+	var changes []string
+	for _, drift := range cr.Status.Drift {
+		change := fmt.Sprintf("%s %s %s", drift.Operation, drift.Kind, drift.Name)
+		log.Info("Applying deferred change", "operation", drift.Operation, "kind", drift.Kind, "namespace", drift.Namespace, "name", drift.Name, "paths", drift.ChangedPaths)
+		changes = append(changes, change)
+	}
+	message := "Reconciliation of operand resources resumed"
+	if len(changes) > 0 {
+		message = fmt.Sprintf("%s, applying %d deferred changes: %s", message, len(changes), strings.Join(changes, ", "))
+	}
+	log.Info("Reconciliation resumed", "deferred changes", len(changes))
+	r.recorder.Event(cr, corev1.EventTypeNormal, reconciliationResumedReason, message)
+
+	conditions.RemoveStatusCondition(&status.Conditions, migrationsv1alpha1.ConditionReconciliationPaused)
+	cr.Status.Drift = nil
+	return r.Client.Status().Update(context.TODO(), cr)
+}
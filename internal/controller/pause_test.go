@@ -0,0 +1,125 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gstruct"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	operatorsv2 "github.com/operator-framework/api/pkg/operators/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+)
+
+var _ = Describe("Paused reconciliation", func() {
+	var (
+		r  *MigControllerReconciler
+		cr *migrationsv1alpha1.MigController
+	)
+
+	BeforeEach(func() {
+		cr = newDeployedMigController("0.0.1")
+		cr.Spec.Paused = true
+		r = newFakeReconciler(cr)
+	})
+
+	It("should report drift without touching the operand while paused", func() {
+		res, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cr)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(reconcile.Result{RequeueAfter: requeueInterval}))
+
+		deployments := &appsv1.DeploymentList{}
+		Expect(r.Client.List(context.TODO(), deployments)).To(Succeed())
+		Expect(deployments.Items).To(BeEmpty())
+
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr)).To(Succeed())
+		Expect(conditions.IsStatusConditionTrue(cr.Status.Conditions, migrationsv1alpha1.ConditionReconciliationPaused)).To(BeTrue())
+		Expect(cr.Status.Drift).To(ContainElement(gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+			"Kind":      Equal("Deployment"),
+			"Name":      Equal(common.ControllerResourceName),
+			"Operation": Equal(migrationsv1alpha1.DriftCreate),
+		})))
+		Expect(r.recorder.(*record.FakeRecorder).Events).To(Receive(ContainSubstring(reconciliationPausedReason)))
+
+		By("Reconciling again without new events")
+		_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cr)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.recorder.(*record.FakeRecorder).Events).ToNot(Receive(ContainSubstring(reconciliationPausedReason)))
+	})
+
+	It("should keep the OperatorCondition current while paused", func() {
+		operatorCondition := &operatorsv2.OperatorCondition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      operatorConditionName,
+				Namespace: fakeOperatorNamespace,
+			},
+		}
+		Expect(r.Client.Create(context.TODO(), operatorCondition)).To(Succeed())
+		r.operatorConditionName = operatorConditionName
+		Expect(r.Client.Create(context.TODO(), newRunningMigration())).To(Succeed())
+
+		_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cr)})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(operatorCondition), operatorCondition)).To(Succeed())
+		upgradeable := meta.FindStatusCondition(operatorCondition.Spec.Conditions, operatorsv2.Upgradeable)
+		Expect(upgradeable).ToNot(BeNil())
+		Expect(upgradeable.Status).To(Equal(metav1.ConditionFalse))
+		Expect(upgradeable.Reason).To(Equal(migrationsRunningReason))
+	})
+
+	It("should report the deferred changes when resumed", func() {
+		Expect(r.reconcilePaused(cr)).To(Succeed())
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr)).To(Succeed())
+		deferred := len(cr.Status.Drift)
+		Expect(deferred).ToNot(BeZero())
+		Expect(r.recorder.(*record.FakeRecorder).Events).To(Receive(ContainSubstring(reconciliationPausedReason)))
+
+		cr.Spec.Paused = false
+		Expect(r.resumeReconciliation(cr)).To(Succeed())
+
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr)).To(Succeed())
+		Expect(conditions.FindStatusCondition(cr.Status.Conditions, migrationsv1alpha1.ConditionReconciliationPaused)).To(BeNil())
+		Expect(cr.Status.Drift).To(BeEmpty())
+		Expect(r.recorder.(*record.FakeRecorder).Events).To(Receive(And(
+			ContainSubstring(reconciliationResumedReason),
+			ContainSubstring("applying %d deferred changes", deferred),
+		)))
+
+		By("Resuming only once")
+		Expect(r.resumeReconciliation(cr)).To(Succeed())
+		Expect(r.recorder.(*record.FakeRecorder).Events).ToNot(Receive(ContainSubstring(reconciliationResumedReason)))
+	})
+
+	It("should pause with the annotation as well", func() {
+		cr.Spec.Paused = false
+		Expect(isPaused(cr)).To(BeFalse())
+		cr.Annotations = map[string]string{migrationsv1alpha1.PauseAnnotation: "true"}
+		Expect(isPaused(cr)).To(BeTrue())
+	})
+})
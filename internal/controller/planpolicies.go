@@ -0,0 +1,84 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+// updateActivePolicies reports the plan policies that are in place, i.e. both the ValidatingAdmissionPolicy
+// and its binding exist, in the MigController status
+func (r *MigControllerReconciler) updateActivePolicies(cr *migrationsv1alpha1.MigController, desired []client.Object) error {
+	var active []string
+	for _, resource := range desired {
+		if _, ok := resource.(*admissionregistrationv1.ValidatingAdmissionPolicy); !ok {
+			continue
+		}
+		key := client.ObjectKey{Name: resource.GetName()}
+		found, err := r.exists(key, &admissionregistrationv1.ValidatingAdmissionPolicy{})
+		if err != nil {
+			return err
+		}
+		if found {
+			// policies are bound under their own name
+			if found, err = r.exists(key, &admissionregistrationv1.ValidatingAdmissionPolicyBinding{}); err != nil {
+				return err
+			}
+		}
+		if found {
+			active = append(active, resource.GetName())
+		}
+	}
+	sort.Strings(active)
+
+	if equality.Semantic.DeepEqual(active, cr.Status.ActivePolicies) {
+		return nil
+	}
+	cr.Status.ActivePolicies = active
+	return r.Client.Status().Update(context.TODO(), cr)
+}
+
+func (r *MigControllerReconciler) exists(key client.ObjectKey, obj client.Object) (bool, error) {
+	if err := r.Client.Get(context.TODO(), key, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return obj.GetDeletionTimestamp() == nil, nil
+}
+
+// reconcileDeletePlanPolicy removes the plan policies when the MigController is deleted, being cluster scoped
+// they cannot be owned by it
+func (r *MigControllerReconciler) reconcileDeletePlanPolicy(args *callbacks.ReconcileCallbackArgs) error {
+	if args.State != callbacks.ReconcileStateOperatorDelete || args.DesiredObject == nil {
+		return nil
+	}
+
+	log.Info("Deleting plan policy", "type", reflect.TypeOf(args.DesiredObject), "name", args.DesiredObject.GetName())
+	return client.IgnoreNotFound(r.Client.Delete(context.TODO(), args.DesiredObject))
+}
@@ -0,0 +1,54 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/quota"
+)
+
+// updateQuotaUsage reports the running storage migrations counted against the quota in the MigController status
+// and as metrics, the quota itself is enforced by the webhook
+func (r *MigControllerReconciler) updateQuotaUsage(cr *migrationsv1alpha1.MigController) error {
+	var usage *migrationsv1alpha1.MigControllerQuotaUsage
+	if cr.Spec.Quota != nil {
+		var migrations []unstructured.Unstructured
+		for _, kind := range []string{"VirtualMachineStorageMigrationList", "MultiNamespaceVirtualMachineStorageMigrationList"} {
+			listed, err := r.listMigrations(kind)
+			if err != nil {
+				return err
+			}
+			migrations = append(migrations, listed...)
+		}
+		counted := quota.Count(migrations)
+		quota.ReportUsage(cr.Spec.Quota, counted)
+		usage = counted.Status()
+	} else {
+		quota.ReportUsage(nil, quota.Usage{})
+	}
+
+	if equality.Semantic.DeepEqual(usage, cr.Status.QuotaUsage) {
+		return nil
+	}
+	cr.Status.QuotaUsage = usage
+	return r.Client.Status().Update(context.TODO(), cr)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,6 +25,10 @@ func (r *MigControllerReconciler) watch() error {
 		return err
 	}
 
+	if err := r.watchNamespaces(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -93,11 +98,21 @@ func (r *MigControllerReconciler) registerHooks() {
 	r.reconciler.
 		WithPreCreateHook(r.preCreate).
 		WithWatchRegistrator(r.watch).
-		WithSanityChecker(r.checkSanity)
+		WithSanityChecker(r.checkSanity).
+		WithUpgradeTimeoutGetter(r.getUpgradeTimeout).
+		WithUpgradeFailureHandler(r.handleUpgradeFailure).
+		WithRolloutPendingChecker(r.isRolloutPending).
+		WithDesiredResourcesFilter(r.deferRollout).
+		WithUnmanagedAnnotation(UnmanagedAnnotation).
+		WithIgnoredFieldsAnnotation(IgnoredFieldsAnnotation)
 
 	r.reconciler.AddCallback(&apiextensionsv1.CustomResourceDefinition{}, r.reconcileDeleteCRDs)
 	r.reconciler.AddCallback(&rbacv1.ClusterRoleBinding{}, r.reconcileDeleteClusterRoleBinding)
 	r.reconciler.AddCallback(&rbacv1.ClusterRole{}, r.reconcileDeleteClusterRole)
+	r.reconciler.AddCallback(&rbacv1.Role{}, r.reconcileDeleteWatchNamespaceRBAC)
+	r.reconciler.AddCallback(&rbacv1.RoleBinding{}, r.reconcileDeleteWatchNamespaceRBAC)
+	r.reconciler.AddCallback(&admissionregistrationv1.ValidatingAdmissionPolicy{}, r.reconcileDeletePlanPolicy)
+	r.reconciler.AddCallback(&admissionregistrationv1.ValidatingAdmissionPolicyBinding{}, r.reconcileDeletePlanPolicy)
 }
 
 func (r *MigControllerReconciler) reconcileDeleteClusterRoleBinding(args *callbacks.ReconcileCallbackArgs) error {
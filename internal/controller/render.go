@@ -0,0 +1,95 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"os"
+	"sort"
+
+	"github.com/kelseyhightower/envconfig"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources/cluster"
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources/namespaced"
+)
+
+// RenderResources returns the operand resources the operator creates for the MigController, labeled as the
+// reconciler labels them on creation and sorted by kind, namespace and name. The operator environment is read
+// like NewReconciler does, so the CRDs are left out when installed by OLM, but nothing is read from the cluster:
+// the priority class is assumed to exist, owner references are left out and selectedNamespaces stand in for the
+// namespaces matching spec.namespaceSelector.
+func RenderResources(cr *migrationsv1alpha1.MigController, namespace string, selectedNamespaces []string) ([]client.Object, error) {
+	var namespacedArgs namespaced.FactoryArgs
+	if err := envconfig.Process("", &namespacedArgs); err != nil {
+		return nil, err
+	}
+	namespacedArgs.Namespace = namespace
+
+	watchNamespaces := resolveWatchNamespaces(cr, selectedNamespaces, common.GetTargetNamespaces())
+
+	var resources []client.Object
+	if sdk.DeployClusterResources() {
+		clusterArgs := newClusterArgs(&cluster.FactoryArgs{
+			Namespace:      namespace,
+			Logger:         log,
+			OLMManagedCRDs: os.Getenv(operatorConditionNameEnv) != "",
+		}, cr, watchNamespaces)
+		crs, err := cluster.CreateAllStaticResources(clusterArgs)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, crs...)
+	}
+
+	args := newNamespacedArgs(&namespacedArgs, cr)
+	args.WatchNamespaces = watchNamespaces
+	nsrs, err := namespaced.CreateAllResources(args)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, nsrs...)
+
+	labels := sdk.GetRecommendedLabelsFromCr(cr)
+	for _, resource := range resources {
+		sdk.SetLabel(createVersionLabel, namespacedArgs.OperatorVersion, resource)
+		for k, v := range labels {
+			sdk.SetLabel(k, v, resource)
+		}
+		if deployment, ok := resource.(*appsv1.Deployment); ok {
+			for k, v := range labels {
+				sdk.SetLabel(k, v, &deployment.Spec.Template)
+			}
+		}
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		a, b := resources[i], resources[j]
+		// the factories set the type meta of every resource
+		if kindA, kindB := a.GetObjectKind().GroupVersionKind().Kind, b.GetObjectKind().GroupVersionKind().Kind; kindA != kindB {
+			return kindA < kindB
+		}
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		return a.GetName() < b.GetName()
+	})
+	return resources, nil
+}
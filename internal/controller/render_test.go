@@ -0,0 +1,131 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"os"
+	"sort"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+)
+
+// setRenderEnv sets the operator environment RenderResources reads for the duration of the spec
+func setRenderEnv(deployClusterResources string) {
+	env := map[string]string{
+		"OPERATOR_VERSION":         "0.0.1",
+		"CONTROLLER_IMAGE":         "kubevirt/kubevirt-migration-operator:latest",
+		"DEPLOY_CLUSTER_RESOURCES": deployClusterResources,
+		"VERBOSITY":                "1",
+		"PULL_POLICY":              "IfNotPresent",
+	}
+	for name, value := range env {
+		os.Setenv(name, value)
+		DeferCleanup(os.Unsetenv, name)
+	}
+}
+
+// getObjectKeys returns the kind, namespace and name of the resources
+func getObjectKeys(resources []client.Object) []string {
+	keys := []string{}
+	for _, resource := range resources {
+		keys = append(keys, resource.GetObjectKind().GroupVersionKind().Kind+"/"+client.ObjectKeyFromObject(resource).String())
+	}
+	return keys
+}
+
+var _ = Describe("Rendering resources", func() {
+	var cr *migrationsv1alpha1.MigController
+
+	BeforeEach(func() {
+		cr = newDeployedMigController("0.0.1")
+	})
+
+	It("should render the resources the operator creates for a default MigController", func() {
+		setRenderEnv("true")
+		cr.Labels = map[string]string{sdk.AppKubernetesPartOfLabel: "kubevirt"}
+		resources, err := RenderResources(cr, fakeOperatorNamespace, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		desired, err := newFakeReconciler(cr).GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getObjectKeys(resources)).To(ConsistOf(getObjectKeys(desired)))
+		Expect(getObjectKeys(resources)).To(ContainElements(
+			"CustomResourceDefinition//virtualmachinestoragemigrationplans.migrations.kubevirt.io",
+			"ClusterRole//"+common.ControllerResourceName,
+			"Deployment/"+fakeOperatorNamespace+"/"+common.ControllerResourceName,
+			"ServiceAccount/"+fakeOperatorNamespace+"/"+common.ControllerServiceAccountName,
+		))
+
+		By("Sorting the resources by kind, namespace and name")
+		Expect(sort.SliceIsSorted(resources, func(i, j int) bool {
+			a, b := resources[i], resources[j]
+			kindA, kindB := a.GetObjectKind().GroupVersionKind().Kind, b.GetObjectKind().GroupVersionKind().Kind
+			if kindA != kindB {
+				return kindA < kindB
+			}
+			if a.GetNamespace() != b.GetNamespace() {
+				return a.GetNamespace() < b.GetNamespace()
+			}
+			return a.GetName() < b.GetName()
+		})).To(BeTrue())
+
+		By("Labeling the resources as the reconciler does")
+		for _, resource := range resources {
+			Expect(resource.GetLabels()).To(HaveKeyWithValue(createVersionLabel, "0.0.1"), getObjectKeys([]client.Object{resource})[0])
+			Expect(resource.GetLabels()).To(HaveKeyWithValue(sdk.AppKubernetesPartOfLabel, "kubevirt"))
+			Expect(resource.GetOwnerReferences()).To(BeEmpty())
+			if deployment, ok := resource.(*appsv1.Deployment); ok {
+				Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue(sdk.AppKubernetesPartOfLabel, "kubevirt"))
+			}
+		}
+	})
+
+	It("should leave out the cluster resources when they are not deployed by the operator", func() {
+		setRenderEnv("false")
+		resources, err := RenderResources(cr, fakeOperatorNamespace, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resources).ToNot(BeEmpty())
+		for _, resource := range resources {
+			Expect(resource.GetNamespace()).To(Equal(fakeOperatorNamespace), getObjectKeys([]client.Object{resource})[0])
+		}
+	})
+
+	It("should leave out the CRDs when installed by OLM", func() {
+		setRenderEnv("true")
+		os.Setenv(operatorConditionNameEnv, operatorConditionName)
+		DeferCleanup(os.Unsetenv, operatorConditionNameEnv)
+		resources, err := RenderResources(cr, fakeOperatorNamespace, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getObjectKeys(resources)).To(ContainElement("ClusterRole//" + common.ControllerResourceName))
+		for _, resource := range resources {
+			Expect(resource.GetObjectKind().GroupVersionKind().Kind).ToNot(Equal("CustomResourceDefinition"))
+		}
+	})
+
+	It("should fail without the operator environment", func() {
+		_, err := RenderResources(cr, fakeOperatorNamespace, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,211 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+const (
+	defaultMaxRolloutDeferral = 6 * time.Hour
+
+	rolloutDeferredReason = "MigrationsRunning"
+)
+
+// deferRollout holds back pod template changes of the deployments about to be applied while storage migrations are
+// running, the deployments keep the last applied template until the migrations finish or the deferral deadline passes
+func (r *MigControllerReconciler) deferRollout(obj client.Object, resources []client.Object) ([]client.Object, error) {
+	cr := obj.(*migrationsv1alpha1.MigController)
+	status := r.Status(cr)
+	maxDeferral := getMaxRolloutDeferral(cr)
+	// rolling back a failed upgrade is not held back either
+	if maxDeferral == 0 || cr.Annotations[migrationsv1alpha1.ForceRolloutAnnotation] == "true" || sdk.IsUpgradeRolledBack(status) {
+		conditions.RemoveStatusCondition(&status.Conditions, migrationsv1alpha1.ConditionRolloutPending)
+		return resources, nil
+	}
+
+	held := map[*appsv1.Deployment]*appsv1.Deployment{}
+	for _, resource := range resources {
+		desired, ok := resource.(*appsv1.Deployment)
+		if !ok {
+			continue
+		}
+		applied, err := r.getAppliedDeployment(desired)
+		if err != nil {
+			return nil, err
+		}
+		if applied != nil && !equality.Semantic.DeepEqual(desired.Spec.Template, applied.Spec.Template) {
+			held[desired] = applied
+		}
+	}
+	if len(held) == 0 {
+		conditions.RemoveStatusCondition(&status.Conditions, migrationsv1alpha1.ConditionRolloutPending)
+		return resources, nil
+	}
+
+	running, err := r.countRunningMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if running == 0 {
+		conditions.RemoveStatusCondition(&status.Conditions, migrationsv1alpha1.ConditionRolloutPending)
+		return resources, nil
+	}
+
+	pending := conditions.FindStatusCondition(status.Conditions, migrationsv1alpha1.ConditionRolloutPending)
+	if pending != nil && pending.Status == corev1.ConditionTrue && time.Since(pending.LastTransitionTime.Time) > maxDeferral {
+		log.Info("Rollout deferral deadline passed, rolling out", "running migrations", running, "deferred since", pending.LastTransitionTime)
+		conditions.RemoveStatusCondition(&status.Conditions, migrationsv1alpha1.ConditionRolloutPending)
+		return resources, nil
+	}
+
+	for desired, applied := range held {
+		log.Info("Deferring rollout while storage migrations are running", "deployment", desired.Name, "running migrations", running)
+		desired.Spec.Template = *applied.Spec.Template.DeepCopy()
+	}
+
+	message := fmt.Sprintf("Rollout deferred while %d storage migrations are running", running)
+	if !conditions.IsStatusConditionTrue(status.Conditions, migrationsv1alpha1.ConditionRolloutPending) {
+		r.recorder.Event(cr, corev1.EventTypeNormal, rolloutDeferredReason, message)
+	}
+	conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
+		Type:    migrationsv1alpha1.ConditionRolloutPending,
+		Status:  corev1.ConditionTrue,
+		Reason:  rolloutDeferredReason,
+		Message: message,
+	})
+
+	return resources, nil
+}
+
+// clearForcedRollout removes the force rollout annotation once the deployments rolled out, so that later changes
+// are deferred again
+func (r *MigControllerReconciler) clearForcedRollout(cr *migrationsv1alpha1.MigController) error {
+	if cr.Annotations[migrationsv1alpha1.ForceRolloutAnnotation] != "true" {
+		return nil
+	}
+
+	deployments, err := r.reconciler.GetAllDeployments(cr)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments {
+		current := &appsv1.Deployment{}
+		if err := r.Client.Get(context.TODO(), client.ObjectKeyFromObject(deployment), current); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if current.Status.ObservedGeneration != current.Generation || !sdk.CheckDeploymentReady(current) {
+			return nil
+		}
+	}
+
+	log.Info("Forced rollout completed, removing annotation", "annotation", migrationsv1alpha1.ForceRolloutAnnotation)
+	delete(cr.Annotations, migrationsv1alpha1.ForceRolloutAnnotation)
+	return r.Client.Update(context.TODO(), cr)
+}
+
+// isRolloutPending reports whether deployment changes are being held back
+func (r *MigControllerReconciler) isRolloutPending(obj client.Object) bool {
+	return conditions.IsStatusConditionTrue(r.Status(obj).Conditions, migrationsv1alpha1.ConditionRolloutPending)
+}
+
+// getAppliedDeployment returns the deployment as last applied by the operator, nil if it has not been applied yet
+func (r *MigControllerReconciler) getAppliedDeployment(desired *appsv1.Deployment) (*appsv1.Deployment, error) {
+	current := &appsv1.Deployment{}
+	if err := r.Client.Get(context.TODO(), client.ObjectKeyFromObject(desired), current); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	lastApplied, ok := current.Annotations[LastAppliedConfigAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	applied := &appsv1.Deployment{}
+	if err := json.Unmarshal([]byte(lastApplied), applied); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// countRunningMigrations returns the number of virtual machine storage migrations currently in progress in the cluster
+func (r *MigControllerReconciler) countRunningMigrations() (int, error) {
+	running := 0
+
+	migrations, err := r.listMigrations("VirtualMachineStorageMigrationList")
+	if err != nil {
+		return 0, err
+	}
+	for _, migration := range migrations {
+		vms, _, _ := unstructured.NestedSlice(migration.Object, "status", "runningMigrations")
+		running += len(vms)
+	}
+
+	migrations, err = r.listMigrations("MultiNamespaceVirtualMachineStorageMigrationList")
+	if err != nil {
+		return 0, err
+	}
+	for _, migration := range migrations {
+		namespaces, _, _ := unstructured.NestedSlice(migration.Object, "status", "namespaces")
+		for _, namespace := range namespaces {
+			if ns, ok := namespace.(map[string]interface{}); ok {
+				vms, _, _ := unstructured.NestedSlice(ns, "runningMigrations")
+				running += len(vms)
+			}
+		}
+	}
+
+	return running, nil
+}
+
+func (r *MigControllerReconciler) listMigrations(kind string) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind(kind))
+	if err := r.uncachedClient.List(context.TODO(), list); err != nil {
+		// the migration CRDs are installed by the operator, nothing can be running before they are
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func getMaxRolloutDeferral(cr *migrationsv1alpha1.MigController) time.Duration {
+	if cr.Spec.RolloutStrategy == nil || cr.Spec.RolloutStrategy.MaxDeferral == nil {
+		return defaultMaxRolloutDeferral
+	}
+	return cr.Spec.RolloutStrategy.MaxDeferral.Duration
+}
@@ -0,0 +1,134 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+)
+
+func newRunningMigration() *unstructured.Unstructured {
+	migration := &unstructured.Unstructured{}
+	migration.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind("VirtualMachineStorageMigration"))
+	migration.SetName("running-migration")
+	migration.SetNamespace("default")
+	Expect(unstructured.SetNestedSlice(migration.Object, []interface{}{
+		map[string]interface{}{"name": "vm", "progress": "50%"},
+	}, "status", "runningMigrations")).To(Succeed())
+	return migration
+}
+
+func getControllerDeployment(resources []client.Object) *appsv1.Deployment {
+	for _, resource := range resources {
+		if deployment, ok := resource.(*appsv1.Deployment); ok && deployment.Name == common.ControllerResourceName {
+			return deployment
+		}
+	}
+	return nil
+}
+
+var _ = Describe("Rollout deferral", func() {
+	var (
+		r  *MigControllerReconciler
+		cr *migrationsv1alpha1.MigController
+	)
+
+	BeforeEach(func() {
+		cr = newDeployedMigController("0.0.1")
+		r = newFakeReconciler(cr, newRunningMigration())
+
+		By("Applying the controller deployment")
+		resources, err := r.GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		deployment := getControllerDeployment(resources)
+		lastApplied, err := json.Marshal(deployment)
+		Expect(err).ToNot(HaveOccurred())
+		deployment.Annotations = map[string]string{LastAppliedConfigAnnotation: string(lastApplied)}
+		Expect(r.Client.Create(context.TODO(), deployment)).To(Succeed())
+
+		By("Changing the controller placement")
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr)).To(Succeed())
+		cr.Spec.Infra.NodeSelector = map[string]string{"infra": "true"}
+	})
+
+	It("should only hold back changes about to be applied", func() {
+		resources, err := r.GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getControllerDeployment(resources).Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue("infra", "true"))
+		Expect(conditions.FindStatusCondition(cr.Status.Conditions, migrationsv1alpha1.ConditionRolloutPending)).To(BeNil())
+
+		resources, err = r.deferRollout(cr, resources)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getControllerDeployment(resources).Spec.Template.Spec.NodeSelector).To(BeEmpty())
+		Expect(conditions.IsStatusConditionTrue(cr.Status.Conditions, migrationsv1alpha1.ConditionRolloutPending)).To(BeTrue())
+	})
+
+	It("should clear the force rollout annotation once rolled out", func() {
+		cr.Annotations = map[string]string{migrationsv1alpha1.ForceRolloutAnnotation: "true"}
+		Expect(r.Client.Update(context.TODO(), cr)).To(Succeed())
+
+		resources, err := r.GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		resources, err = r.deferRollout(cr, resources)
+		Expect(err).ToNot(HaveOccurred())
+		desired := getControllerDeployment(resources)
+		Expect(desired.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue("infra", "true"))
+		Expect(conditions.FindStatusCondition(cr.Status.Conditions, migrationsv1alpha1.ConditionRolloutPending)).To(BeNil())
+
+		By("Keeping the annotation while the deployment rolls out")
+		deployment := &appsv1.Deployment{}
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(desired), deployment)).To(Succeed())
+		lastApplied, err := json.Marshal(desired)
+		Expect(err).ToNot(HaveOccurred())
+		deployment.Annotations[LastAppliedConfigAnnotation] = string(lastApplied)
+		deployment.Spec.Template = desired.Spec.Template
+		Expect(r.Client.Update(context.TODO(), deployment)).To(Succeed())
+		Expect(r.clearForcedRollout(cr)).To(Succeed())
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr)).To(Succeed())
+		Expect(cr.Annotations).To(HaveKeyWithValue(migrationsv1alpha1.ForceRolloutAnnotation, "true"))
+
+		By("Removing the annotation once the deployment is ready")
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(desired), deployment)).To(Succeed())
+		deployment.Status.ObservedGeneration = deployment.Generation
+		deployment.Status.Replicas = *deployment.Spec.Replicas
+		deployment.Status.ReadyReplicas = *deployment.Spec.Replicas
+		Expect(r.Client.Status().Update(context.TODO(), deployment)).To(Succeed())
+		Expect(r.clearForcedRollout(cr)).To(Succeed())
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr)).To(Succeed())
+		Expect(cr.Annotations).ToNot(HaveKey(migrationsv1alpha1.ForceRolloutAnnotation))
+
+		By("Deferring later changes again")
+		cr.Spec.Infra.NodeSelector = map[string]string{"infra": "false"}
+		resources, err = r.GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		resources, err = r.deferRollout(cr, resources)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getControllerDeployment(resources).Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue("infra", "true"))
+		Expect(conditions.IsStatusConditionTrue(cr.Status.Conditions, migrationsv1alpha1.ConditionRolloutPending)).To(BeTrue())
+	})
+})
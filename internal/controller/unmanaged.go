@@ -0,0 +1,56 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+// updateUnmanagedResources reports the operand resources excluded from reconciliation in the MigController status,
+// so hotfixes applied to them stay visible
+func (r *MigControllerReconciler) updateUnmanagedResources(cr *migrationsv1alpha1.MigController) error {
+	unmanaged, err := r.reconciler.GetUnmanagedResources(cr)
+	if err != nil {
+		return err
+	}
+
+	var resources []migrationsv1alpha1.MigControllerUnmanagedResource
+	for _, resource := range unmanaged {
+		gvk, err := apiutil.GVKForObject(resource.Object, r.scheme)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, migrationsv1alpha1.MigControllerUnmanagedResource{
+			Kind:          gvk.Kind,
+			Namespace:     resource.Object.GetNamespace(),
+			Name:          resource.Object.GetName(),
+			Unmanaged:     resource.Unmanaged,
+			IgnoredFields: resource.IgnoredFields,
+		})
+	}
+
+	if equality.Semantic.DeepEqual(resources, cr.Status.UnmanagedResources) {
+		return nil
+	}
+	cr.Status.UnmanagedResources = resources
+	return r.Client.Status().Update(context.TODO(), cr)
+}
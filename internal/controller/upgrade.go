@@ -0,0 +1,260 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+)
+
+const (
+	// revisionVersionLabel holds the operator version an operand ControllerRevision was recorded for
+	revisionVersionLabel = "operator.migrations.kubevirt.io/revisionVersion"
+
+	revisionHistoryLimit = 3
+	upgradeHistoryLimit  = 5
+)
+
+// getUpgradeTimeout returns the upgrade timeout configured on the MigController
+func (r *MigControllerReconciler) getUpgradeTimeout(obj client.Object) time.Duration {
+	cr := obj.(*migrationsv1alpha1.MigController)
+	if cr.Spec.UpgradeStrategy == nil || cr.Spec.UpgradeStrategy.Timeout == nil {
+		return 0
+	}
+	return cr.Spec.UpgradeStrategy.Timeout.Duration
+}
+
+// handleUpgradeFailure records the failed upgrade and, when enabled, points the operand back at the last
+// revision recorded for the observed version. The restored deployments are picked up by GetAllResources.
+func (r *MigControllerReconciler) handleUpgradeFailure(obj client.Object, logger logr.Logger) (bool, error) {
+	cr := obj.(*migrationsv1alpha1.MigController)
+	entry := migrationsv1alpha1.MigControllerUpgradeHistory{
+		FromVersion: cr.Status.ObservedVersion,
+		ToVersion:   cr.Status.TargetVersion,
+		Result:      migrationsv1alpha1.UpgradeFailed,
+		Time:        metav1.Now(),
+	}
+
+	rolledBack := false
+	if cr.Spec.UpgradeStrategy != nil && cr.Spec.UpgradeStrategy.AutoRollback {
+		revision, err := r.getVersionRevision(cr, cr.Status.ObservedVersion)
+		if err != nil {
+			return false, err
+		}
+		if revision != nil {
+			logger.Info("Rolling back operand", "revision", revision.Name, "version", cr.Status.ObservedVersion)
+			entry.Result = migrationsv1alpha1.UpgradeRolledBack
+			entry.Revision = revision.Name
+			rolledBack = true
+		} else {
+			logger.Info("No revision recorded for the observed version, unable to roll back", "version", cr.Status.ObservedVersion)
+		}
+	}
+
+	addUpgradeHistory(cr, entry)
+	return rolledBack, nil
+}
+
+// rollbackResources replaces the desired deployments with the ones recorded in the revision being rolled back to
+func (r *MigControllerReconciler) rollbackResources(cr *migrationsv1alpha1.MigController, resources []client.Object) ([]client.Object, error) {
+	revision, err := r.getVersionRevision(cr, cr.Status.ObservedVersion)
+	if err != nil || revision == nil {
+		return resources, err
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := json.Unmarshal(revision.Data.Raw, deployments); err != nil {
+		return nil, fmt.Errorf("unable to decode revision %s; %w", revision.Name, err)
+	}
+
+	for i, desired := range resources {
+		for j := range deployments.Items {
+			if sdk.SameResource(desired, &deployments.Items[j]) {
+				resources[i] = &deployments.Items[j]
+			}
+		}
+	}
+
+	return resources, nil
+}
+
+// recordRevision stores the operand deployments of a fully deployed MigController in a ControllerRevision
+// so that a later failed upgrade can be rolled back to it
+func (r *MigControllerReconciler) recordRevision(cr *migrationsv1alpha1.MigController, operatorVersion string) error {
+	status := &cr.Status.Status
+	if status.Phase != sdkapi.PhaseDeployed || status.ObservedVersion != operatorVersion || sdk.IsUpgrading(status) {
+		return nil
+	}
+
+	resources, err := r.GetAllResources(cr)
+	if err != nil {
+		return err
+	}
+	deployments := &appsv1.DeploymentList{}
+	for _, resource := range resources {
+		if deployment, ok := resource.(*appsv1.Deployment); ok {
+			deployments.Items = append(deployments.Items, *deployment)
+		}
+	}
+	data, err := json.Marshal(deployments)
+	if err != nil {
+		return err
+	}
+
+	hasher := fnv.New32a()
+	hasher.Write(data)
+	name := fmt.Sprintf("%s-%s", common.ControllerResourceName, rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())))
+
+	revisions, err := r.listRevisions(cr)
+	if err != nil {
+		return err
+	}
+	if len(revisions) > 0 && revisions[0].Name == name {
+		return nil
+	}
+
+	var revision *appsv1.ControllerRevision
+	for i := range revisions {
+		if revisions[i].Name == name {
+			revision = &revisions[i]
+			revisions = append(revisions[:i:i], revisions[i+1:]...)
+			break
+		}
+	}
+	if revision != nil {
+		// an older revision becomes current again, e.g. after upgrading back to a previous version,
+		// so renumber it as the newest one
+		revision.Revision = revisions[0].Revision + 1
+		revision.Labels[revisionVersionLabel] = operatorVersion
+		if err := r.Client.Update(context.TODO(), revision); err != nil {
+			return err
+		}
+		log.Info("Reused operand revision", "revision", name, "version", operatorVersion)
+	} else if err := r.createRevision(cr, name, operatorVersion, data, revisions); err != nil {
+		return err
+	}
+
+	// the first revision of a new version marks the completion of an upgrade
+	if len(revisions) > 0 && revisions[0].Labels[revisionVersionLabel] != operatorVersion {
+		addUpgradeHistory(cr, migrationsv1alpha1.MigControllerUpgradeHistory{
+			FromVersion: revisions[0].Labels[revisionVersionLabel],
+			ToVersion:   operatorVersion,
+			Result:      migrationsv1alpha1.UpgradeSucceeded,
+			Time:        metav1.Now(),
+			Revision:    name,
+		})
+		if err := r.Client.Status().Update(context.TODO(), cr); err != nil {
+			return err
+		}
+	}
+
+	for _, stale := range revisions[min(len(revisions), revisionHistoryLimit-1):] {
+		if err := r.Client.Delete(context.TODO(), &stale); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createRevision creates the ControllerRevision holding the given operand deployments, numbered after the
+// newest of the existing revisions
+func (r *MigControllerReconciler) createRevision(cr *migrationsv1alpha1.MigController, name, operatorVersion string,
+	data []byte, revisions []appsv1.ControllerRevision) error {
+	revision := &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: r.namespace,
+			Labels: map[string]string{
+				common.ComponentLabel: common.ControllerResourceName,
+				revisionVersionLabel:  operatorVersion,
+			},
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: 1,
+	}
+	if len(revisions) > 0 {
+		revision.Revision = revisions[0].Revision + 1
+	}
+	if err := controllerutil.SetControllerReference(cr, revision, r.scheme); err != nil {
+		return err
+	}
+	if err := r.Client.Create(context.TODO(), revision); err != nil {
+		return err
+	}
+	log.Info("Recorded operand revision", "revision", name, "version", operatorVersion)
+	return nil
+}
+
+// getVersionRevision returns the newest revision recorded for the given operator version
+func (r *MigControllerReconciler) getVersionRevision(cr *migrationsv1alpha1.MigController, version string) (*appsv1.ControllerRevision, error) {
+	revisions, err := r.listRevisions(cr)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revisions {
+		if revisions[i].Labels[revisionVersionLabel] == version {
+			return &revisions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// listRevisions returns the operand revisions owned by the MigController, newest first
+func (r *MigControllerReconciler) listRevisions(cr *migrationsv1alpha1.MigController) ([]appsv1.ControllerRevision, error) {
+	list := &appsv1.ControllerRevisionList{}
+	if err := r.Client.List(context.TODO(), list,
+		client.InNamespace(r.namespace),
+		client.MatchingLabels{common.ComponentLabel: common.ControllerResourceName},
+	); err != nil {
+		return nil, err
+	}
+
+	var revisions []appsv1.ControllerRevision
+	for _, revision := range list.Items {
+		if metav1.IsControlledBy(&revision, cr) {
+			revisions = append(revisions, revision)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+
+	return revisions, nil
+}
+
+func addUpgradeHistory(cr *migrationsv1alpha1.MigController, entry migrationsv1alpha1.MigControllerUpgradeHistory) {
+	history := append([]migrationsv1alpha1.MigControllerUpgradeHistory{entry}, cr.Status.UpgradeHistory...)
+	cr.Status.UpgradeHistory = history[:min(len(history), upgradeHistoryLimit)]
+}
@@ -0,0 +1,184 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+)
+
+func newDeployedMigController(version string) *migrationsv1alpha1.MigController {
+	cr := &migrationsv1alpha1.MigController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "migcontroller",
+			Namespace: fakeOperatorNamespace,
+			UID:       types.UID("migcontroller-uid"),
+		},
+	}
+	cr.Status.Phase = sdkapi.PhaseDeployed
+	cr.Status.ObservedVersion = version
+	cr.Status.TargetVersion = version
+	return cr
+}
+
+func getControllerImage(resources []client.Object) string {
+	for _, resource := range resources {
+		if deployment, ok := resource.(*appsv1.Deployment); ok && deployment.Name == common.ControllerResourceName {
+			return deployment.Spec.Template.Spec.Containers[0].Image
+		}
+	}
+	return ""
+}
+
+var _ = Describe("Operand revisions", func() {
+	var (
+		r  *MigControllerReconciler
+		cr *migrationsv1alpha1.MigController
+	)
+
+	BeforeEach(func() {
+		cr = newDeployedMigController("0.0.1")
+		r = newFakeReconciler(cr)
+	})
+
+	It("should record a revision of the deployed operand once", func() {
+		Expect(r.recordRevision(cr, "0.0.1")).To(Succeed())
+		Expect(r.recordRevision(cr, "0.0.1")).To(Succeed())
+
+		revisions, err := r.listRevisions(cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(revisions).To(HaveLen(1))
+		Expect(revisions[0].Labels).To(HaveKeyWithValue(revisionVersionLabel, "0.0.1"))
+		Expect(revisions[0].Revision).To(BeEquivalentTo(1))
+	})
+
+	It("should not record a revision while upgrading", func() {
+		cr.Status.TargetVersion = "0.0.2"
+		Expect(r.recordRevision(cr, "0.0.2")).To(Succeed())
+
+		revisions, err := r.listRevisions(cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(revisions).To(BeEmpty())
+	})
+
+	It("should record the completed upgrade with the first revision of a new version", func() {
+		Expect(r.recordRevision(cr, "0.0.1")).To(Succeed())
+
+		r.namespacedArgs.OperatorVersion = "0.0.2"
+		r.namespacedArgs.ControllerImage = "kubevirt/kubevirt-migration-operator:v0.0.2"
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr)).To(Succeed())
+		cr.Status.ObservedVersion = "0.0.2"
+		cr.Status.TargetVersion = "0.0.2"
+		Expect(r.recordRevision(cr, "0.0.2")).To(Succeed())
+
+		revisions, err := r.listRevisions(cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(revisions).To(HaveLen(2))
+		Expect(revisions[0].Labels).To(HaveKeyWithValue(revisionVersionLabel, "0.0.2"))
+		Expect(revisions[0].Revision).To(BeEquivalentTo(2))
+
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr)).To(Succeed())
+		Expect(cr.Status.UpgradeHistory).To(HaveLen(1))
+		Expect(cr.Status.UpgradeHistory[0].FromVersion).To(Equal("0.0.1"))
+		Expect(cr.Status.UpgradeHistory[0].ToVersion).To(Equal("0.0.2"))
+		Expect(cr.Status.UpgradeHistory[0].Result).To(Equal(migrationsv1alpha1.UpgradeSucceeded))
+		Expect(cr.Status.UpgradeHistory[0].Revision).To(Equal(revisions[0].Name))
+	})
+
+	It("should renumber the revision of a version that becomes current again", func() {
+		upgradeTo := func(version string) {
+			r.namespacedArgs.OperatorVersion = version
+			r.namespacedArgs.ControllerImage = "kubevirt/kubevirt-migration-operator:v" + version
+			Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr)).To(Succeed())
+			cr.Status.ObservedVersion = version
+			cr.Status.TargetVersion = version
+			Expect(r.recordRevision(cr, version)).To(Succeed())
+		}
+
+		By("Upgrading from 0.0.1 to 0.0.2 and back to 0.0.1")
+		upgradeTo("0.0.1")
+		upgradeTo("0.0.2")
+		upgradeTo("0.0.1")
+
+		revisions, err := r.listRevisions(cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(revisions).To(HaveLen(2))
+		Expect(revisions[0].Labels).To(HaveKeyWithValue(revisionVersionLabel, "0.0.1"))
+		Expect(revisions[0].Revision).To(BeEquivalentTo(3))
+		Expect(revisions[1].Labels).To(HaveKeyWithValue(revisionVersionLabel, "0.0.2"))
+
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr)).To(Succeed())
+		Expect(cr.Status.UpgradeHistory).To(HaveLen(2))
+		Expect(cr.Status.UpgradeHistory[0].FromVersion).To(Equal("0.0.2"))
+		Expect(cr.Status.UpgradeHistory[0].ToVersion).To(Equal("0.0.1"))
+		Expect(cr.Status.UpgradeHistory[0].Revision).To(Equal(revisions[0].Name))
+
+		By("Failing an upgrade from 0.0.1 rolls back to the renumbered revision")
+		revision, err := r.getVersionRevision(cr, "0.0.1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(revision.Name).To(Equal(revisions[0].Name))
+	})
+
+	It("should roll the deployments back to the revision of the observed version", func() {
+		r.namespacedArgs.ControllerImage = "kubevirt/kubevirt-migration-operator:v0.0.1"
+		Expect(r.recordRevision(cr, "0.0.1")).To(Succeed())
+
+		By("Failing an upgrade to a new controller image")
+		r.namespacedArgs.OperatorVersion = "0.0.2"
+		r.namespacedArgs.ControllerImage = "kubevirt/kubevirt-migration-operator:v0.0.2"
+		cr.Status.TargetVersion = "0.0.2"
+		cr.Spec.UpgradeStrategy = &migrationsv1alpha1.MigControllerUpgradeStrategy{AutoRollback: true}
+		rolledBack, err := r.handleUpgradeFailure(cr, log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rolledBack).To(BeTrue())
+		Expect(cr.Status.UpgradeHistory).To(HaveLen(1))
+		Expect(cr.Status.UpgradeHistory[0].Result).To(Equal(migrationsv1alpha1.UpgradeRolledBack))
+
+		resources, err := r.GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getControllerImage(resources)).To(Equal("kubevirt/kubevirt-migration-operator:v0.0.2"))
+
+		resources, err = r.rollbackResources(cr, resources)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getControllerImage(resources)).To(Equal("kubevirt/kubevirt-migration-operator:v0.0.1"))
+	})
+
+	It("should keep the desired deployments without a revision to roll back to", func() {
+		r.namespacedArgs.ControllerImage = "kubevirt/kubevirt-migration-operator:v0.0.2"
+		cr.Spec.UpgradeStrategy = &migrationsv1alpha1.MigControllerUpgradeStrategy{AutoRollback: true}
+		rolledBack, err := r.handleUpgradeFailure(cr, log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rolledBack).To(BeFalse())
+
+		resources, err := r.GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		resources, err = r.rollbackResources(cr, resources)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getControllerImage(resources)).To(Equal("kubevirt/kubevirt-migration-operator:v0.0.2"))
+	})
+})
@@ -0,0 +1,153 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+const noWatchedNamespacesReason = "NoNamespacesMatched"
+
+// getWatchNamespaces resolves the namespaces the controller is restricted to, nil when it watches all namespaces
+// and empty when the restriction matches no namespace.
+// When installed by OLM into a subset of namespaces the selection is limited to the OperatorGroup target namespaces
+func (r *MigControllerReconciler) getWatchNamespaces(cr *migrationsv1alpha1.MigController) ([]string, error) {
+	return listWatchNamespaces(context.TODO(), r.Client, cr, r.targetNamespaces)
+}
+
+// listWatchNamespaces resolves the namespaces the controller of cr is restricted to, reading the namespaces
+// matching spec.namespaceSelector with c
+func listWatchNamespaces(ctx context.Context, c client.Reader, cr *migrationsv1alpha1.MigController,
+	targetNamespaces []string) ([]string, error) {
+	var selected []string
+	if cr.Spec.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(cr.Spec.NamespaceSelector)
+		if err != nil {
+			return nil, err
+		}
+		list := &corev1.NamespaceList{}
+		if err := c.List(ctx, list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		for _, namespace := range list.Items {
+			if namespace.DeletionTimestamp == nil {
+				selected = append(selected, namespace.Name)
+			}
+		}
+	}
+
+	return resolveWatchNamespaces(cr, selected, targetNamespaces), nil
+}
+
+// resolveWatchNamespaces combines spec.watchNamespaces with the selected namespaces matching spec.namespaceSelector
+// and limits them to the target namespaces, nil when the controller watches all namespaces and empty when it watches none
+func resolveWatchNamespaces(cr *migrationsv1alpha1.MigController, selected, targetNamespaces []string) []string {
+	if len(cr.Spec.WatchNamespaces) == 0 && cr.Spec.NamespaceSelector == nil {
+		if targetNamespaces == nil {
+			return nil
+		}
+		return sets.List(sets.New(targetNamespaces...))
+	}
+
+	namespaces := sets.New(cr.Spec.WatchNamespaces...).Insert(selected...)
+	if targetNamespaces != nil {
+		namespaces = namespaces.Intersection(sets.New(targetNamespaces...))
+	}
+
+	return sets.List(namespaces)
+}
+
+// updateWatchedNamespaces reports the namespaces the controller is restricted to in the MigController status
+func (r *MigControllerReconciler) updateWatchedNamespaces(cr *migrationsv1alpha1.MigController) error {
+	namespaces, err := r.getWatchNamespaces(cr)
+	if err != nil {
+		return err
+	}
+	status := r.Status(cr)
+	currentConditionValues := sdk.GetConditionValues(status.Conditions)
+	if namespaces != nil && len(namespaces) == 0 {
+		conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
+			Type:    migrationsv1alpha1.ConditionNoWatchedNamespaces,
+			Status:  corev1.ConditionTrue,
+			Reason:  noWatchedNamespacesReason,
+			Message: "The controller is restricted to namespaces matching none, it is not run",
+		})
+		namespaces = nil
+	} else {
+		conditions.RemoveStatusCondition(&status.Conditions, migrationsv1alpha1.ConditionNoWatchedNamespaces)
+	}
+
+	if !sdk.ConditionsChanged(currentConditionValues, sdk.GetConditionValues(status.Conditions)) &&
+		equality.Semantic.DeepEqual(namespaces, cr.Status.WatchedNamespaces) {
+		return nil
+	}
+	cr.Status.WatchedNamespaces = namespaces
+	return r.Client.Status().Update(context.TODO(), cr)
+}
+
+// watchNamespaces reconciles the MigControllers selecting namespaces when namespaces come, go or change their labels
+func (r *MigControllerReconciler) watchNamespaces() error {
+	return r.controller.Watch(source.Kind(r.getCache(), &corev1.Namespace{},
+		handler.TypedEnqueueRequestsFromMapFunc(r.namespaceToMigControllers),
+		predicate.TypedLabelChangedPredicate[*corev1.Namespace]{},
+	))
+}
+
+func (r *MigControllerReconciler) namespaceToMigControllers(ctx context.Context, _ *corev1.Namespace) []reconcile.Request {
+	list := &migrationsv1alpha1.MigControllerList{}
+	if err := r.Client.List(ctx, list, client.InNamespace(r.namespace)); err != nil {
+		log.Error(err, "unable to list MigControllers")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, cr := range list.Items {
+		if cr.Spec.NamespaceSelector != nil {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)})
+		}
+	}
+	return requests
+}
+
+// reconcileDeleteWatchNamespaceRBAC removes the controller RBAC from the watched namespaces, unlike the resources
+// in the operator namespace it cannot be owned by the MigController and is not garbage collected
+func (r *MigControllerReconciler) reconcileDeleteWatchNamespaceRBAC(args *callbacks.ReconcileCallbackArgs) error {
+	if args.State != callbacks.ReconcileStateOperatorDelete || args.DesiredObject == nil {
+		return nil
+	}
+	if args.DesiredObject.GetNamespace() == r.namespace {
+		return nil
+	}
+
+	log.Info("Deleting watched namespace RBAC", "namespace", args.DesiredObject.GetNamespace(), "name", args.DesiredObject.GetName())
+	return client.IgnoreNotFound(r.Client.Delete(context.TODO(), args.DesiredObject))
+}
@@ -0,0 +1,130 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources/namespaced"
+)
+
+func getRoleBindingNamespaces(resources []client.Object) []string {
+	var namespaces []string
+	for _, resource := range resources {
+		if binding, ok := resource.(*rbacv1.RoleBinding); ok && binding.Namespace != fakeOperatorNamespace {
+			namespaces = append(namespaces, binding.Namespace)
+		}
+	}
+	return namespaces
+}
+
+var _ = Describe("Watch namespaces", func() {
+	selected := map[string]string{"migrations": "enabled"}
+
+	DescribeTable("should resolve the watched namespaces", func(spec migrationsv1alpha1.MigControllerSpec, targetNamespaces, expected []string) {
+		r := newFakeReconciler(
+			newNamespace("ns1", selected),
+			newNamespace("ns2", selected),
+			newNamespace("ns3", nil),
+		)
+		r.targetNamespaces = targetNamespaces
+		cr := &migrationsv1alpha1.MigController{Spec: spec}
+		Expect(r.getWatchNamespaces(cr)).To(Equal(expected))
+	},
+		Entry("all namespaces", migrationsv1alpha1.MigControllerSpec{}, nil, nil),
+		Entry("listed and selected namespaces",
+			migrationsv1alpha1.MigControllerSpec{
+				WatchNamespaces:   []string{"ns3"},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: selected},
+			},
+			nil, []string{"ns1", "ns2", "ns3"}),
+		Entry("the OperatorGroup target namespaces",
+			migrationsv1alpha1.MigControllerSpec{},
+			[]string{"ns2", "ns1", "ns2"}, []string{"ns1", "ns2"}),
+		Entry("the selected namespaces within the OperatorGroup target namespaces",
+			migrationsv1alpha1.MigControllerSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: selected},
+			},
+			[]string{"ns2", "ns3"}, []string{"ns2"}),
+		Entry("no namespace outside the OperatorGroup target namespaces",
+			migrationsv1alpha1.MigControllerSpec{WatchNamespaces: []string{"ns3"}},
+			[]string{"ns1"}, []string{}),
+		Entry("no namespace matching the selector",
+			migrationsv1alpha1.MigControllerSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"migrations": "disabled"}},
+			},
+			nil, []string{}),
+	)
+
+	It("should not run the controller when the restriction matches no namespace", func() {
+		cr := newDeployedMigController("0.0.1")
+		cr.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: selected}
+		r := newFakeReconciler(cr, newNamespace("ns1", nil))
+
+		resources, err := r.GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getRoleBindingNamespaces(resources)).To(BeEmpty())
+		deployment := getControllerDeployment(resources)
+		Expect(deployment).ToNot(BeNil())
+		Expect(deployment.Spec.Replicas).To(HaveValue(BeZero()))
+		for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+			Expect(env.Name).ToNot(Equal(namespaced.WatchNamespacesEnv))
+		}
+		Expect(resources).To(ContainElement(And(
+			BeAssignableToTypeOf(&rbacv1.ClusterRole{}),
+			HaveField("Name", common.ControllerResourceName),
+			HaveField("Rules", Not(ContainElement(HaveField("Resources", ContainElement("persistentvolumeclaims"))))),
+		)))
+
+		Expect(r.updateWatchedNamespaces(cr)).To(Succeed())
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr)).To(Succeed())
+		Expect(cr.Status.WatchedNamespaces).To(BeEmpty())
+		Expect(conditions.IsStatusConditionTrue(cr.Status.Conditions, migrationsv1alpha1.ConditionNoWatchedNamespaces)).To(BeTrue())
+
+		By("Labelling a namespace to match")
+		Expect(r.Client.Update(context.TODO(), newNamespace("ns1", selected))).To(Succeed())
+		Expect(r.updateWatchedNamespaces(cr)).To(Succeed())
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr)).To(Succeed())
+		Expect(cr.Status.WatchedNamespaces).To(Equal([]string{"ns1"}))
+		Expect(conditions.FindStatusCondition(cr.Status.Conditions, migrationsv1alpha1.ConditionNoWatchedNamespaces)).To(BeNil())
+	})
+
+	It("should restrict the controller RBAC to the OperatorGroup target namespaces", func() {
+		r := newFakeReconciler()
+		r.targetNamespaces = []string{"ns1", "ns2"}
+		cr := newDeployedMigController("0.0.1")
+
+		resources, err := r.GetAllResources(cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getRoleBindingNamespaces(resources)).To(ConsistOf("ns1", "ns2"))
+		for _, resource := range resources {
+			if role, ok := resource.(*rbacv1.ClusterRole); ok && role.Name == "migrations.kubevirt.io:admin" {
+				Expect(role.Labels).To(HaveKeyWithValue("rbac.authorization.k8s.io/aggregate-to-admin", "false"))
+			}
+		}
+	})
+})
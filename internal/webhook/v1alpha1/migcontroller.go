@@ -0,0 +1,43 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+// getMigController returns the MigController in the operator namespace, nil when there is none
+func getMigController(ctx context.Context, c client.Reader, namespace string) (*migrationsv1alpha1.MigController, error) {
+	crs := &migrationsv1alpha1.MigControllerList{}
+	if err := c.List(ctx, crs, client.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for i := range crs.Items {
+		if crs.Items[i].DeletionTimestamp == nil {
+			return &crs.Items[i], nil
+		}
+	}
+	return nil, nil
+}
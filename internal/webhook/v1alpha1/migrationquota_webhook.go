@@ -0,0 +1,256 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+	"kubevirt.io/kubevirt-migration-operator/pkg/quota"
+)
+
+const (
+	// MigrationKind is the kind of the storage migrations
+	MigrationKind = "VirtualMachineStorageMigration"
+	// MultiNamespaceMigrationKind is the kind of the multi namespace storage migrations
+	MultiNamespaceMigrationKind = "MultiNamespaceVirtualMachineStorageMigration"
+)
+
+var migrationquotalog = logf.Log.WithName("migrationquota-webhook")
+
+// migrationResources maps the storage migration kinds counted against the quota to their resource
+var migrationResources = map[string]string{
+	MigrationKind:               "virtualmachinestoragemigrations",
+	MultiNamespaceMigrationKind: "multinamespacevirtualmachinestoragemigrations",
+}
+
+// migrationCache reads the storage migrations of all namespaces from a cache of their own, the manager cache
+// only covers the operator namespace. The cache only holds the two storage migration kinds, stripped down to the
+// fields the quota reads. It is created on the first admission, as the operator installs the storage migration CRDs
+// after it starts and a cache can only be created for the kinds the API server serves.
+type migrationCache struct {
+	config  *rest.Config
+	scheme  *runtime.Scheme
+	started chan struct{}
+	ctx     context.Context
+
+	lock   sync.Mutex
+	reader client.Reader
+}
+
+var _ client.Reader = &migrationCache{}
+
+func newMigrationCache(config *rest.Config, scheme *runtime.Scheme) *migrationCache {
+	return &migrationCache{config: config, scheme: scheme, started: make(chan struct{})}
+}
+
+// Start implements manager.Runnable, the cache is stopped with the manager
+func (c *migrationCache) Start(ctx context.Context) error {
+	c.ctx = ctx
+	close(c.started)
+	<-ctx.Done()
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every replica serves the webhook
+func (c *migrationCache) NeedLeaderElection() bool {
+	return false
+}
+
+// Get implements client.Reader
+func (c *migrationCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	reader, err := c.getReader(ctx)
+	if err != nil {
+		return err
+	}
+	return reader.Get(ctx, key, obj, opts...)
+}
+
+// List implements client.Reader
+func (c *migrationCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	reader, err := c.getReader(ctx)
+	if err != nil {
+		return err
+	}
+	return reader.List(ctx, list, opts...)
+}
+
+// getReader returns the cache, creating and starting it on the first call
+func (c *migrationCache) getReader(ctx context.Context) (client.Reader, error) {
+	select {
+	case <-c.started:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.reader != nil {
+		return c.reader, nil
+	}
+	byObject := map[client.Object]cache.ByObject{}
+	for kind := range migrationResources {
+		migration := &unstructured.Unstructured{}
+		migration.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind(kind))
+		byObject[migration] = cache.ByObject{Transform: trimMigration}
+	}
+	migrations, err := cache.New(c.config, cache.Options{Scheme: c.scheme, ByObject: byObject})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the storage migration cache; %w", err)
+	}
+	go func() {
+		if err := migrations.Start(c.ctx); err != nil {
+			migrationquotalog.Error(err, "Storage migration cache stopped")
+		}
+	}()
+	c.reader = migrations
+	return c.reader, nil
+}
+
+// trimMigration keeps the metadata and the phases of a cached storage migration
+func trimMigration(obj interface{}) (interface{}, error) {
+	migration, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj, nil
+	}
+	migration.SetManagedFields(nil)
+	status := map[string]interface{}{}
+	if phase, found, _ := unstructured.NestedString(migration.Object, "status", "phase"); found {
+		status["phase"] = phase
+	}
+	if namespaces, found, _ := unstructured.NestedSlice(migration.Object, "status", "namespaces"); found {
+		var phases []interface{}
+		for _, namespace := range namespaces {
+			ns, _ := namespace.(map[string]interface{})
+			phase, _, _ := unstructured.NestedString(ns, "phase")
+			phases = append(phases, map[string]interface{}{"phase": phase})
+		}
+		status["namespaces"] = phases
+	}
+	migration.Object = map[string]interface{}{
+		"apiVersion": migration.GetAPIVersion(),
+		"kind":       migration.GetKind(),
+		"metadata":   migration.Object["metadata"],
+		"status":     status,
+	}
+	return migration, nil
+}
+
+// SetupMigrationQuotaWebhookWithManager registers the quota webhook for both storage migration kinds in the manager.
+// The running migrations are counted from a cache of their own, started with the manager.
+func SetupMigrationQuotaWebhookWithManager(mgr ctrl.Manager, namespace string) error {
+	migrations := newMigrationCache(mgr.GetConfig(), mgr.GetScheme())
+	if err := mgr.Add(migrations); err != nil {
+		return err
+	}
+
+	validator := &MigrationQuotaCustomValidator{
+		client:     mgr.GetClient(),
+		migrations: migrations,
+		namespace:  namespace,
+	}
+	mgr.GetWebhookServer().Register(common.MigrationQuotaValidatePath,
+		admission.WithCustomValidator(mgr.GetScheme(), newMigration(), validator))
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-migrations-kubevirt-io-v1alpha1-virtualmachinestoragemigration,mutating=false,failurePolicy=fail,sideEffects=None,groups=migrations.kubevirt.io,resources=virtualmachinestoragemigrations;multinamespacevirtualmachinestoragemigrations,verbs=create,versions=v1alpha1,name=vvirtualmachinestoragemigration-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// MigrationQuotaCustomValidator rejects new storage migrations, single or multi namespace, while the namespace
+// or the cluster already runs as many as the MigController quota allows.
+type MigrationQuotaCustomValidator struct {
+	// client reads the MigController in the operator namespace
+	client client.Reader
+	// migrations reads the storage migrations in all namespaces
+	migrations client.Reader
+	namespace  string
+}
+
+var _ webhook.CustomValidator = &MigrationQuotaCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type VirtualMachineStorageMigration.
+func (v *MigrationQuotaCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	migration, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected a %s object but got %T", MigrationKind, obj)
+	}
+
+	limits, err := v.getQuota(ctx)
+	if err != nil || limits == nil {
+		return nil, err
+	}
+
+	var migrations []unstructured.Unstructured
+	for kind := range migrationResources {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind(kind + "List"))
+		if err := v.migrations.List(ctx, list); err != nil {
+			return nil, fmt.Errorf("unable to count the running storage migrations; %w", err)
+		}
+		migrations = append(migrations, list.Items...)
+	}
+
+	reason := quota.Check(limits, quota.Count(migrations), migration.GetNamespace())
+	if reason == "" {
+		return nil, nil
+	}
+	migrationquotalog.Info("Denying storage migration", "migration", client.ObjectKeyFromObject(migration), "reason", reason)
+	quota.ReportRejection(migration.GetNamespace())
+	resource := migrationResources[migration.GetKind()]
+	return nil, apierrors.NewForbidden(migrationsv1alpha1.GroupVersion.WithResource(resource).GroupResource(),
+		migration.GetName(), errors.New("exceeded storage migration quota: "+reason))
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type VirtualMachineStorageMigration.
+func (v *MigrationQuotaCustomValidator) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type VirtualMachineStorageMigration.
+func (v *MigrationQuotaCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// getQuota returns the quota of the MigController, nil when there is no MigController or it sets no quota
+func (v *MigrationQuotaCustomValidator) getQuota(ctx context.Context) (*migrationsv1alpha1.MigControllerQuota, error) {
+	cr, err := getMigController(ctx, v.client, v.namespace)
+	if err != nil || cr == nil {
+		return nil, err
+	}
+	return cr.Spec.Quota, nil
+}
+
+func newMigration() *unstructured.Unstructured {
+	migration := &unstructured.Unstructured{}
+	migration.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind(MigrationKind))
+	return migration
+}
@@ -0,0 +1,206 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/quota"
+)
+
+// listReader lists the MigControllers and storage migrations it holds
+type listReader struct {
+	client.Reader
+	crs        []migrationsv1alpha1.MigController
+	migrations []unstructured.Unstructured
+}
+
+func (r *listReader) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	switch l := list.(type) {
+	case *migrationsv1alpha1.MigControllerList:
+		l.Items = r.crs
+	case *unstructured.UnstructuredList:
+		for _, migration := range r.migrations {
+			if migration.GetKind()+"List" == l.GetKind() {
+				l.Items = append(l.Items, migration)
+			}
+		}
+	}
+	return nil
+}
+
+func newStorageMigration(namespace, name, phase string) *unstructured.Unstructured {
+	migration := newMigration()
+	migration.SetNamespace(namespace)
+	migration.SetName(name)
+	if phase != "" {
+		Expect(unstructured.SetNestedField(migration.Object, phase, "status", "phase")).To(Succeed())
+	}
+	return migration
+}
+
+func newMultiNamespaceStorageMigration(namespace, name string, phases ...string) *unstructured.Unstructured {
+	migration := newStorageMigration(namespace, name, "")
+	migration.SetKind(MultiNamespaceMigrationKind)
+	var namespaces []interface{}
+	for _, phase := range phases {
+		namespaces = append(namespaces, map[string]interface{}{"phase": phase})
+	}
+	if len(namespaces) > 0 {
+		Expect(unstructured.SetNestedSlice(migration.Object, namespaces, "status", "namespaces")).To(Succeed())
+	}
+	return migration
+}
+
+var _ = Describe("VirtualMachineStorageMigration quota webhook", func() {
+	var (
+		reader    *listReader
+		validator *MigrationQuotaCustomValidator
+	)
+
+	BeforeEach(func() {
+		reader = &listReader{
+			crs: []migrationsv1alpha1.MigController{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "migcontroller", Namespace: "operator"},
+					Spec: migrationsv1alpha1.MigControllerSpec{
+						Quota: &migrationsv1alpha1.MigControllerQuota{
+							MaxRunningPerNamespace: ptr.To[int32](2),
+							MaxRunning:             ptr.To[int32](3),
+						},
+					},
+				},
+			},
+			migrations: []unstructured.Unstructured{
+				*newStorageMigration("ns1", "running", "Running"),
+				*newStorageMigration("ns1", "pending", ""),
+				*newStorageMigration("ns1", "completed", "Completed"),
+				*newStorageMigration("ns2", "failed", "Failed"),
+			},
+		}
+		validator = &MigrationQuotaCustomValidator{client: reader, migrations: reader, namespace: "operator"}
+	})
+
+	It("should admit migrations within the quota", func() {
+		_, err := validator.ValidateCreate(context.Background(), newStorageMigration("ns2", "new", ""))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject migrations exceeding the namespace quota", func() {
+		_, err := validator.ValidateCreate(context.Background(), newStorageMigration("ns1", "new", ""))
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("namespace ns1 already runs 2 storage migrations, the limit is 2 per namespace"))
+	})
+
+	It("should reject migrations exceeding the cluster quota", func() {
+		reader.migrations = append(reader.migrations, *newStorageMigration("ns2", "running", "Running"))
+		_, err := validator.ValidateCreate(context.Background(), newStorageMigration("ns3", "new", ""))
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("the cluster already runs 3 storage migrations, the limit is 3"))
+	})
+
+	It("should count the running multi namespace migrations in the namespace they are created in", func() {
+		reader.migrations = append(reader.migrations,
+			*newMultiNamespaceStorageMigration("ns2", "running", "Completed", "Running"),
+			*newMultiNamespaceStorageMigration("ns3", "completed", "Completed", "Failed"),
+		)
+		_, err := validator.ValidateCreate(context.Background(), newStorageMigration("ns3", "new", ""))
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("the cluster already runs 3 storage migrations, the limit is 3"))
+	})
+
+	It("should reject multi namespace migrations exceeding the quota", func() {
+		_, err := validator.ValidateCreate(context.Background(), newMultiNamespaceStorageMigration("ns1", "new"))
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`multinamespacevirtualmachinestoragemigrations.migrations.kubevirt.io "new" is forbidden`))
+		Expect(err.Error()).To(ContainSubstring("namespace ns1 already runs 2 storage migrations, the limit is 2 per namespace"))
+	})
+
+	It("should only cache the fields the quota reads", func() {
+		migration := newMultiNamespaceStorageMigration("ns1", "running", "Completed", "Running")
+		migration.SetLabels(map[string]string{"app": "test"})
+		Expect(unstructured.SetNestedField(migration.Object, "plan", "spec", "multiNamespaceVirtualMachineStorageMigrationPlanRef", "name")).To(Succeed())
+		Expect(unstructured.SetNestedSlice(migration.Object, []interface{}{
+			map[string]interface{}{"name": "ns1", "phase": "Completed", "completedMigrations": []interface{}{"vm1"}},
+			map[string]interface{}{"name": "ns2", "phase": "Running", "runningMigrations": []interface{}{"vm2"}},
+		}, "status", "namespaces")).To(Succeed())
+
+		trimmed, err := trimMigration(migration)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(trimmed).To(Equal(&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": migrationsv1alpha1.GroupVersion.String(),
+			"kind":       MultiNamespaceMigrationKind,
+			"metadata": map[string]interface{}{
+				"namespace": "ns1",
+				"name":      "running",
+				"labels":    map[string]interface{}{"app": "test"},
+			},
+			"status": map[string]interface{}{
+				"namespaces": []interface{}{
+					map[string]interface{}{"phase": "Completed"},
+					map[string]interface{}{"phase": "Running"},
+				},
+			},
+		}}))
+		Expect(quota.IsRunning(trimmed.(*unstructured.Unstructured))).To(BeTrue())
+	})
+
+	It("should admit any migration without a quota", func() {
+		reader.crs[0].Spec.Quota = nil
+		_, err := validator.ValidateCreate(context.Background(), newStorageMigration("ns1", "new", ""))
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("Storage migration cache", func() {
+	It("should wait for the manager to start before creating the cache", func() {
+		migrations := newMigrationCache(&rest.Config{Host: "http://127.0.0.1:1"}, runtime.NewScheme())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := migrations.List(ctx, &unstructured.UnstructuredList{})
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(migrations.reader).To(BeNil())
+	})
+
+	It("should retry creating the cache until the storage migration kinds are served", func() {
+		migrations := newMigrationCache(&rest.Config{Host: "http://127.0.0.1:1"}, runtime.NewScheme())
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(migrations.Start(ctx)).To(Succeed())
+		}()
+
+		for range 2 {
+			err := migrations.List(ctx, &unstructured.UnstructuredList{})
+			Expect(err).To(MatchError(ContainSubstring("unable to create the storage migration cache")))
+			Expect(migrations.reader).To(BeNil())
+		}
+	})
+})
@@ -0,0 +1,220 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources/cluster"
+)
+
+const (
+	// MultiNamespacePlanKind is the kind of the plans migrating virtual machines across namespaces
+	MultiNamespacePlanKind = "MultiNamespaceVirtualMachineStorageMigrationPlan"
+)
+
+var multinamespaceplanlog = logf.Log.WithName("multinamespaceplan-webhook")
+
+// storageMigrateResources are the resources the migrations.kubevirt.io:storagemigrate role grants,
+// a plan creates them in every namespace it references
+var storageMigrateResources = cluster.GetStorageMigrateResources()
+
+// SetupMultiNamespacePlanWebhookWithManager registers the webhook for MultiNamespaceVirtualMachineStorageMigrationPlan in the manager.
+// The plan type is not part of the operator scheme, so the webhook is registered on the server directly
+// instead of through the webhook builder.
+func SetupMultiNamespacePlanWebhookWithManager(mgr ctrl.Manager) error {
+	validator := &MultiNamespacePlanCustomValidator{
+		client:           mgr.GetClient(),
+		targetNamespaces: common.GetTargetNamespaces(),
+	}
+	mgr.GetWebhookServer().Register(common.MultiNamespacePlanValidatePath,
+		admission.WithCustomValidator(mgr.GetScheme(), newMultiNamespacePlan(), validator))
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-migrations-kubevirt-io-v1alpha1-multinamespacevirtualmachinestoragemigrationplan,mutating=false,failurePolicy=fail,sideEffects=None,groups=migrations.kubevirt.io,resources=multinamespacevirtualmachinestoragemigrationplans,verbs=create;update,versions=v1alpha1,name=vmultinamespacevirtualmachinestoragemigrationplan-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// MultiNamespacePlanCustomValidator checks that whoever creates a multi namespace plan is allowed to
+// migrate storage in each namespace the plan references, and whoever updates one in each namespace added to it.
+type MultiNamespacePlanCustomValidator struct {
+	client client.Client
+	// targetNamespaces are the OperatorGroup target namespaces the plans are restricted to,
+	// nil when all namespaces are targeted. OLM scopes the webhook configurations to them, which
+	// only covers the namespace of the plan itself
+	targetNamespaces []string
+}
+
+var _ webhook.CustomValidator = &MultiNamespacePlanCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type MultiNamespaceVirtualMachineStorageMigrationPlan.
+func (v *MultiNamespacePlanCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	plan, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected a %s object but got %T", MultiNamespacePlanKind, obj)
+	}
+	return nil, v.validatePlan(ctx, plan, getPlanNamespaces(plan))
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type MultiNamespaceVirtualMachineStorageMigrationPlan.
+// Only the namespaces added to the plan are checked, the others were when they were added.
+func (v *MultiNamespacePlanCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldPlan, ok := oldObj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected a %s object but got %T", MultiNamespacePlanKind, oldObj)
+	}
+	newPlan, ok := newObj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected a %s object but got %T", MultiNamespacePlanKind, newObj)
+	}
+	// finalizers removed from deleted plans do not add namespaces
+	if newPlan.GetDeletionTimestamp() != nil {
+		return nil, nil
+	}
+	oldNamespaces := getPlanNamespaces(oldPlan)
+	var added []string
+	for _, namespace := range getPlanNamespaces(newPlan) {
+		if !slices.Contains(oldNamespaces, namespace) {
+			added = append(added, namespace)
+		}
+	}
+	return nil, v.validatePlan(ctx, newPlan, added)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type MultiNamespaceVirtualMachineStorageMigrationPlan.
+func (v *MultiNamespacePlanCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validatePlan checks the requester may migrate storage in namespaces of plan
+func (v *MultiNamespacePlanCustomValidator) validatePlan(ctx context.Context, plan *unstructured.Unstructured, namespaces []string) error {
+	if len(namespaces) == 0 {
+		return nil
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var allErrs field.ErrorList
+	for _, namespace := range namespaces {
+		if v.targetNamespaces != nil && !slices.Contains(v.targetNamespaces, namespace) {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "namespaces").Key(namespace),
+				fmt.Sprintf("namespace %q is not a target namespace of the operator", namespace)))
+			continue
+		}
+		denied, err := v.getDeniedResources(ctx, req, namespace)
+		if err != nil {
+			return err
+		}
+		if len(denied) > 0 {
+			multinamespaceplanlog.Info("Denying plan", "plan", client.ObjectKeyFromObject(plan),
+				"user", req.UserInfo.Username, "namespace", namespace)
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "namespaces").Key(namespace),
+				fmt.Sprintf("user %q is not allowed to migrate storage in namespace %q: %s",
+					req.UserInfo.Username, namespace, strings.Join(denied, "; "))))
+		}
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(migrationsv1alpha1.GroupVersion.WithKind(MultiNamespacePlanKind).GroupKind(),
+		plan.GetName(), allErrs)
+}
+
+// getDeniedResources runs a SubjectAccessReview for the requesting user per storage migration resource
+// and returns why the ones the user cannot create were denied
+func (v *MultiNamespacePlanCustomValidator) getDeniedResources(ctx context.Context, req admission.Request, namespace string) ([]string, error) {
+	extra := map[string]authorizationv1.ExtraValue{}
+	for k, value := range req.UserInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(value)
+	}
+
+	var denied []string
+	for _, resource := range storageMigrateResources {
+		review := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   req.UserInfo.Username,
+				Groups: req.UserInfo.Groups,
+				UID:    req.UserInfo.UID,
+				Extra:  extra,
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: namespace,
+					Verb:      "create",
+					Group:     migrationsv1alpha1.GroupVersion.Group,
+					Resource:  resource,
+				},
+			},
+		}
+		if err := v.client.Create(ctx, review); err != nil {
+			return nil, fmt.Errorf("unable to review access to %s in namespace %s; %w", resource, namespace, err)
+		}
+		if !review.Status.Allowed {
+			reason := fmt.Sprintf("cannot create %s", resource)
+			if review.Status.Reason != "" {
+				reason += " (" + review.Status.Reason + ")"
+			}
+			denied = append(denied, reason)
+		}
+	}
+
+	return denied, nil
+}
+
+// getPlanNamespaces returns the namespaces referenced in the plan, sorted and without duplicates
+func getPlanNamespaces(plan *unstructured.Unstructured) []string {
+	entries, _, _ := unstructured.NestedSlice(plan.Object, "spec", "namespaces")
+	seen := map[string]bool{}
+	var namespaces []string
+	for _, entry := range entries {
+		ns, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(ns, "name")
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		namespaces = append(namespaces, name)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+func newMultiNamespacePlan() *unstructured.Unstructured {
+	plan := &unstructured.Unstructured{}
+	plan.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind(MultiNamespacePlanKind))
+	return plan
+}
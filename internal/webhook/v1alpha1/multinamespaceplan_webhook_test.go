@@ -0,0 +1,156 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestWebhook(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Webhook Suite")
+}
+
+// reviewClient answers SubjectAccessReviews, allowing the namespaces in allowed
+type reviewClient struct {
+	client.Client
+	allowed map[string]bool
+	reviews []authorizationv1.SubjectAccessReviewSpec
+}
+
+func (c *reviewClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	review := obj.(*authorizationv1.SubjectAccessReview)
+	c.reviews = append(c.reviews, review.Spec)
+	review.Status.Allowed = c.allowed[review.Spec.ResourceAttributes.Namespace]
+	if !review.Status.Allowed {
+		review.Status.Reason = "no RBAC policy matched"
+	}
+	return nil
+}
+
+func newPlan(namespaces ...string) *unstructured.Unstructured {
+	plan := newMultiNamespacePlan()
+	plan.SetName("plan")
+	plan.SetNamespace("plans")
+	var entries []interface{}
+	for _, namespace := range namespaces {
+		entries = append(entries, map[string]interface{}{"name": namespace})
+	}
+	Expect(unstructured.SetNestedSlice(plan.Object, entries, "spec", "namespaces")).To(Succeed())
+	return plan
+}
+
+var _ = Describe("MultiNamespaceVirtualMachineStorageMigrationPlan webhook", func() {
+	var (
+		reviews   *reviewClient
+		validator *MultiNamespacePlanCustomValidator
+		ctx       context.Context
+	)
+
+	BeforeEach(func() {
+		reviews = &reviewClient{allowed: map[string]bool{"ns1": true}}
+		validator = &MultiNamespacePlanCustomValidator{client: reviews}
+		ctx = admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{
+					Username: "tenant",
+					Groups:   []string{"tenants"},
+				},
+			},
+		})
+	})
+
+	It("should review the storagemigrate permissions of the requester in every namespace", func() {
+		_, err := validator.ValidateCreate(ctx, newPlan("ns1", "ns1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reviews.reviews).To(HaveLen(len(storageMigrateResources)))
+		for i, review := range reviews.reviews {
+			Expect(review.User).To(Equal("tenant"))
+			Expect(review.Groups).To(ConsistOf("tenants"))
+			Expect(review.ResourceAttributes.Namespace).To(Equal("ns1"))
+			Expect(review.ResourceAttributes.Verb).To(Equal("create"))
+			Expect(review.ResourceAttributes.Group).To(Equal("migrations.kubevirt.io"))
+			Expect(review.ResourceAttributes.Resource).To(Equal(storageMigrateResources[i]))
+		}
+	})
+
+	It("should reject the plan listing every namespace the requester may not migrate in", func() {
+		_, err := validator.ValidateUpdate(ctx, newPlan("ns1"), newPlan("ns3", "ns1", "ns2"))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`user "tenant" is not allowed to migrate storage in namespace "ns2"`))
+		Expect(err.Error()).To(ContainSubstring(`user "tenant" is not allowed to migrate storage in namespace "ns3"`))
+		Expect(err.Error()).To(ContainSubstring("cannot create virtualmachinestoragemigrations (no RBAC policy matched)"))
+		Expect(err.Error()).ToNot(ContainSubstring(`namespace "ns1"`))
+	})
+
+	It("should reject namespaces outside the target namespaces of the operator", func() {
+		reviews.allowed["ns2"] = true
+		validator.targetNamespaces = []string{"ns1", "plans"}
+		_, err := validator.ValidateCreate(ctx, newPlan("ns1", "ns2"))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`namespace "ns2" is not a target namespace of the operator`))
+		Expect(err.Error()).ToNot(ContainSubstring(`namespace "ns1"`))
+		for _, review := range reviews.reviews {
+			Expect(review.ResourceAttributes.Namespace).To(Equal("ns1"))
+		}
+	})
+
+	It("should only review the namespaces added by an update", func() {
+		reviews.allowed["ns2"] = true
+		_, err := validator.ValidateUpdate(ctx, newPlan("ns3", "ns1"), newPlan("ns1", "ns2"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reviews.reviews).To(HaveLen(len(storageMigrateResources)))
+		for _, review := range reviews.reviews {
+			Expect(review.ResourceAttributes.Namespace).To(Equal("ns2"))
+		}
+	})
+
+	It("should not review updates keeping the namespaces", func() {
+		newObj := newPlan("ns2", "ns3")
+		Expect(unstructured.SetNestedField(newObj.Object, "deleteSource", "spec", "retentionPolicy")).To(Succeed())
+		_, err := validator.ValidateUpdate(ctx, newPlan("ns3", "ns2"), newObj)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reviews.reviews).To(BeEmpty())
+	})
+
+	It("should not review updates of deleted plans", func() {
+		newObj := newPlan("ns2")
+		newObj.SetDeletionTimestamp(ptr.To(metav1.Now()))
+		_, err := validator.ValidateUpdate(ctx, newPlan("ns1"), newObj)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reviews.reviews).To(BeEmpty())
+	})
+
+	It("should not review deletes", func() {
+		_, err := validator.ValidateDelete(ctx, newPlan("ns2"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reviews.reviews).To(BeEmpty())
+	})
+})
@@ -0,0 +1,227 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+)
+
+const (
+	// PlanKind is the kind of the plans migrating virtual machines in a single namespace
+	PlanKind = "VirtualMachineStorageMigrationPlan"
+)
+
+var plandefaultslog = logf.Log.WithName("plandefaults-webhook")
+
+// SetupPlanDefaultsWebhookWithManager registers the defaulting webhook for VirtualMachineStorageMigrationPlan in the manager.
+// The plan type is not part of the operator scheme, so the webhook is registered on the server directly
+// instead of through the webhook builder.
+func SetupPlanDefaultsWebhookWithManager(mgr ctrl.Manager, namespace string) error {
+	defaulter := &PlanDefaultsCustomDefaulter{client: mgr.GetClient(), namespace: namespace}
+	mgr.GetWebhookServer().Register(common.PlanDefaultsMutatePath,
+		admission.WithCustomDefaulter(mgr.GetScheme(), newSingleNamespacePlan(), defaulter))
+	return nil
+}
+
+// +kubebuilder:webhook:path=/mutate-migrations-kubevirt-io-v1alpha1-virtualmachinestoragemigrationplan,mutating=true,failurePolicy=fail,sideEffects=None,groups=migrations.kubevirt.io,resources=virtualmachinestoragemigrationplans;multinamespacevirtualmachinestoragemigrationplans,verbs=create,versions=v1alpha1,name=mvirtualmachinestoragemigrationplan-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// PlanDefaultsCustomDefaulter fills the target storage class of new plans that leave it unset, from the
+// annotations of the namespace migrated in or else the MigController plan defaults. The retention policy is
+// left alone, the plan schemas default it to keepSource before admission so it is never unset here.
+// Multi namespace plans are served as well, the plan type is read from the object kind.
+type PlanDefaultsCustomDefaulter struct {
+	client    client.Reader
+	namespace string
+}
+
+// planDefaults holds the values to fill into a plan, empty values are not filled
+type planDefaults struct {
+	storageClassName string
+}
+
+var _ webhook.CustomDefaulter = &PlanDefaultsCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type VirtualMachineStorageMigrationPlan.
+func (d *PlanDefaultsCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	plan, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected a %s object but got %T", PlanKind, obj)
+	}
+
+	var applied []string
+	var err error
+	if plan.GetKind() == MultiNamespacePlanKind {
+		applied, err = d.applyMultiNamespacePlanDefaults(ctx, plan)
+	} else {
+		applied, err = d.applySingleNamespacePlanDefaults(ctx, plan)
+	}
+	if err != nil || len(applied) == 0 {
+		return err
+	}
+
+	bytes, err := json.Marshal(applied)
+	if err != nil {
+		return err
+	}
+	annotations := plan.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[migrationsv1alpha1.AppliedDefaultsAnnotation] = string(bytes)
+	plan.SetAnnotations(annotations)
+
+	plandefaultslog.Info("Applied plan defaults", "plan", client.ObjectKeyFromObject(plan), "fields", applied)
+	return nil
+}
+
+// applySingleNamespacePlanDefaults fills the defaults of the plan namespace into the plan spec
+func (d *PlanDefaultsCustomDefaulter) applySingleNamespacePlanDefaults(ctx context.Context, plan *unstructured.Unstructured) ([]string, error) {
+	defaults, err := d.getDefaults(ctx, plan.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	spec, _, _ := unstructured.NestedMap(plan.Object, "spec")
+	if spec == nil {
+		spec = map[string]interface{}{}
+	}
+	applied, err := applyPlanDefaults(spec, defaults, "/spec")
+	if err != nil || len(applied) == 0 {
+		return nil, err
+	}
+	return applied, unstructured.SetNestedMap(plan.Object, spec, "spec")
+}
+
+// applyMultiNamespacePlanDefaults fills the defaults of each namespace the plan migrates in into its namespace entry
+func (d *PlanDefaultsCustomDefaulter) applyMultiNamespacePlanDefaults(ctx context.Context, plan *unstructured.Unstructured) ([]string, error) {
+	entries, _, _ := unstructured.NestedSlice(plan.Object, "spec", "namespaces")
+
+	var applied []string
+	for i, entry := range entries {
+		entryObj, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		namespace, _, _ := unstructured.NestedString(entryObj, "name")
+		if namespace == "" {
+			continue
+		}
+		defaults, err := d.getDefaults(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		entryApplied, err := applyPlanDefaults(entryObj, defaults, "/spec/namespaces/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, entryApplied...)
+	}
+	if len(applied) == 0 {
+		return nil, nil
+	}
+	return applied, unstructured.SetNestedSlice(plan.Object, entries, "spec", "namespaces")
+}
+
+// getDefaults returns the defaults for plans in namespace, the namespace annotations take precedence
+// over the MigController plan defaults
+func (d *PlanDefaultsCustomDefaulter) getDefaults(ctx context.Context, namespace string) (planDefaults, error) {
+	var defaults planDefaults
+
+	ns := &corev1.Namespace{}
+	if err := d.client.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		// a multi namespace plan may list a namespace that does not exist yet
+		if !apierrors.IsNotFound(err) {
+			return defaults, fmt.Errorf("unable to read the defaults of namespace %s; %w", namespace, err)
+		}
+	}
+	defaults.storageClassName = ns.Annotations[migrationsv1alpha1.DefaultStorageClassAnnotation]
+	if defaults.storageClassName != "" {
+		return defaults, nil
+	}
+
+	cr, err := getMigController(ctx, d.client, d.namespace)
+	if err != nil || cr == nil || cr.Spec.PlanDefaults == nil {
+		return defaults, err
+	}
+	defaults.storageClassName = cr.Spec.PlanDefaults.StorageClassName
+	return defaults, nil
+}
+
+// applyPlanDefaults fills the defaults into the fields obj leaves unset and returns the JSON pointers of the
+// fields it filled. obj is the spec of a single namespace plan or a namespace entry of a multi namespace plan,
+// both list their virtual machines the same way, and path is its JSON pointer
+func applyPlanDefaults(obj map[string]interface{}, defaults planDefaults, path string) ([]string, error) {
+	var applied []string
+
+	if defaults.storageClassName != "" {
+		vms, _, _ := unstructured.NestedSlice(obj, "virtualMachines")
+		for i, vm := range vms {
+			vmObj, ok := vm.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			pvcs, _, _ := unstructured.NestedSlice(vmObj, "targetMigrationPVCs")
+			for j, pvc := range pvcs {
+				pvcObj, ok := pvc.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if _, found, _ := unstructured.NestedString(pvcObj, "destinationPVC", "storageClassName"); found {
+					continue
+				}
+				if err := unstructured.SetNestedField(pvcObj, defaults.storageClassName, "destinationPVC", "storageClassName"); err != nil {
+					return nil, err
+				}
+				applied = append(applied, path+"/virtualMachines/"+strconv.Itoa(i)+
+					"/targetMigrationPVCs/"+strconv.Itoa(j)+"/destinationPVC/storageClassName")
+			}
+			if len(pvcs) > 0 {
+				if err := unstructured.SetNestedSlice(vmObj, pvcs, "targetMigrationPVCs"); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if len(vms) > 0 {
+			if err := unstructured.SetNestedSlice(obj, vms, "virtualMachines"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return applied, nil
+}
+
+func newSingleNamespacePlan() *unstructured.Unstructured {
+	plan := &unstructured.Unstructured{}
+	plan.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind(PlanKind))
+	return plan
+}
@@ -0,0 +1,259 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	structuraldefaulting "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources"
+)
+
+// namespaceReader returns its namespaces and lists its MigControllers
+type namespaceReader struct {
+	listReader
+	namespaces []*corev1.Namespace
+}
+
+func (r *namespaceReader) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	for _, namespace := range r.namespaces {
+		if namespace.Name == key.Name {
+			namespace.DeepCopyInto(obj.(*corev1.Namespace))
+			return nil
+		}
+	}
+	return apierrors.NewNotFound(corev1.Resource("namespaces"), key.Name)
+}
+
+func newDefaultsPlan(vms ...map[string]interface{}) *unstructured.Unstructured {
+	plan := newSingleNamespacePlan()
+	plan.SetNamespace("tenant")
+	plan.SetName("plan")
+	var entries []interface{}
+	for _, vm := range vms {
+		entries = append(entries, vm)
+	}
+	Expect(unstructured.SetNestedSlice(plan.Object, entries, "spec", "virtualMachines")).To(Succeed())
+	return plan
+}
+
+func newPlanVM(storageClasses ...string) map[string]interface{} {
+	var pvcs []interface{}
+	for _, storageClass := range storageClasses {
+		destination := map[string]interface{}{}
+		if storageClass != "" {
+			destination["storageClassName"] = storageClass
+		}
+		pvcs = append(pvcs, map[string]interface{}{"volumeName": "disk", "destinationPVC": destination})
+	}
+	return map[string]interface{}{"name": "vm", "targetMigrationPVCs": pvcs}
+}
+
+func newDefaultsMultiNamespacePlan(entries ...map[string]interface{}) *unstructured.Unstructured {
+	plan := newMultiNamespacePlan()
+	plan.SetNamespace("tenant")
+	plan.SetName("plan")
+	var namespaces []interface{}
+	for _, entry := range entries {
+		namespaces = append(namespaces, entry)
+	}
+	Expect(unstructured.SetNestedSlice(plan.Object, namespaces, "spec", "namespaces")).To(Succeed())
+	return plan
+}
+
+func newNamespaceEntry(namespace string, vms ...map[string]interface{}) map[string]interface{} {
+	var entries []interface{}
+	for _, vm := range vms {
+		entries = append(entries, vm)
+	}
+	return map[string]interface{}{"name": namespace, "virtualMachines": entries}
+}
+
+// applySchemaDefaults applies the defaults of the CRD schema of the plan kind to plan, like the API server
+// does before admission
+func applySchemaDefaults(plan *unstructured.Unstructured) {
+	for _, crdYAML := range resources.MigrationControllerCRDs {
+		crd := &extv1.CustomResourceDefinition{}
+		Expect(k8syaml.NewYAMLToJSONDecoder(strings.NewReader(crdYAML)).Decode(crd)).To(Succeed())
+		if crd.Spec.Names.Kind != plan.GetKind() {
+			continue
+		}
+		for _, version := range crd.Spec.Versions {
+			internal := &apiextensions.JSONSchemaProps{}
+			Expect(extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(
+				version.Schema.OpenAPIV3Schema, internal, nil)).To(Succeed())
+			structural, err := structuralschema.NewStructural(internal)
+			Expect(err).ToNot(HaveOccurred())
+			structuraldefaulting.Default(plan.Object, structural)
+			return
+		}
+	}
+	Fail("no CRD for " + plan.GetKind())
+}
+
+var _ = Describe("VirtualMachineStorageMigrationPlan defaults webhook", func() {
+	var (
+		reader    *namespaceReader
+		defaulter *PlanDefaultsCustomDefaulter
+	)
+
+	storageClassOf := func(plan *unstructured.Unstructured, vm, pvc int) string {
+		vms, _, _ := unstructured.NestedSlice(plan.Object, "spec", "virtualMachines")
+		pvcs, _, _ := unstructured.NestedSlice(vms[vm].(map[string]interface{}), "targetMigrationPVCs")
+		storageClass, _, _ := unstructured.NestedString(pvcs[pvc].(map[string]interface{}), "destinationPVC", "storageClassName")
+		return storageClass
+	}
+
+	BeforeEach(func() {
+		reader = &namespaceReader{
+			listReader: listReader{
+				crs: []migrationsv1alpha1.MigController{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "migcontroller", Namespace: "operator"},
+						Spec: migrationsv1alpha1.MigControllerSpec{
+							PlanDefaults: &migrationsv1alpha1.MigControllerPlanDefaults{
+								StorageClassName: "cluster-default",
+							},
+						},
+					},
+				},
+			},
+			namespaces: []*corev1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+			},
+		}
+		defaulter = &PlanDefaultsCustomDefaulter{client: reader, namespace: "operator"}
+	})
+
+	It("should fill the unset fields from the MigController and record them", func() {
+		plan := newDefaultsPlan(newPlanVM("", "fast"), newPlanVM(""))
+		Expect(defaulter.Default(context.Background(), plan)).To(Succeed())
+
+		Expect(storageClassOf(plan, 0, 0)).To(Equal("cluster-default"))
+		Expect(storageClassOf(plan, 0, 1)).To(Equal("fast"))
+		Expect(storageClassOf(plan, 1, 0)).To(Equal("cluster-default"))
+		Expect(plan.GetAnnotations()).To(HaveKeyWithValue(migrationsv1alpha1.AppliedDefaultsAnnotation,
+			`["/spec/virtualMachines/0/targetMigrationPVCs/0/destinationPVC/storageClassName",`+
+				`"/spec/virtualMachines/1/targetMigrationPVCs/0/destinationPVC/storageClassName"]`))
+	})
+
+	It("should prefer the namespace defaults", func() {
+		reader.namespaces[0].Annotations = map[string]string{migrationsv1alpha1.DefaultStorageClassAnnotation: "tenant-default"}
+		plan := newDefaultsPlan(newPlanVM(""))
+		Expect(defaulter.Default(context.Background(), plan)).To(Succeed())
+
+		Expect(storageClassOf(plan, 0, 0)).To(Equal("tenant-default"))
+	})
+
+	It("should leave plans setting every field untouched", func() {
+		plan := newDefaultsPlan(newPlanVM("fast"))
+		Expect(defaulter.Default(context.Background(), plan)).To(Succeed())
+
+		Expect(plan.GetAnnotations()).ToNot(HaveKey(migrationsv1alpha1.AppliedDefaultsAnnotation))
+		Expect(storageClassOf(plan, 0, 0)).To(Equal("fast"))
+	})
+
+	It("should keep the retention policy of a plan admitted without the webhook", func() {
+		plan := newDefaultsPlan(newPlanVM("fast"))
+		applySchemaDefaults(plan)
+
+		retentionPolicy, _, _ := unstructured.NestedString(plan.Object, "spec", "retentionPolicy")
+		Expect(retentionPolicy).To(Equal("keepSource"))
+
+		plan = newDefaultsMultiNamespacePlan(newNamespaceEntry("tenant", newPlanVM("fast")))
+		applySchemaDefaults(plan)
+
+		retentionPolicy, _, _ = unstructured.NestedString(plan.Object, "spec", "retentionPolicy")
+		Expect(retentionPolicy).To(Equal("keepSource"))
+		entries, _, _ := unstructured.NestedSlice(plan.Object, "spec", "namespaces")
+		Expect(entries[0]).To(HaveKeyWithValue("retentionPolicy", "keepSource"))
+	})
+
+	DescribeTable("should never change the retention policy of a plan",
+		func(retentionPolicy string) {
+			plan := newDefaultsPlan(newPlanVM(""))
+			Expect(unstructured.SetNestedField(plan.Object, retentionPolicy, "spec", "retentionPolicy")).To(Succeed())
+			raw, err := json.Marshal(plan)
+			Expect(err).ToNot(HaveOccurred())
+
+			handler := admission.WithCustomDefaulter(runtime.NewScheme(), newSingleNamespacePlan(), defaulter)
+			resp := handler.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: raw},
+			}})
+			Expect(resp.Allowed).To(BeTrue())
+			for _, patch := range resp.Patches {
+				Expect(patch.Path).ToNot(HavePrefix("/spec/retentionPolicy"))
+			}
+		},
+		Entry("set to keepSource", "keepSource"),
+		Entry("set to deleteSource", "deleteSource"),
+	)
+
+	It("should fill the defaults of each namespace of a multi namespace plan", func() {
+		reader.namespaces[1].Annotations = map[string]string{migrationsv1alpha1.DefaultStorageClassAnnotation: "other-default"}
+		plan := newDefaultsMultiNamespacePlan(
+			newNamespaceEntry("tenant", newPlanVM("")),
+			newNamespaceEntry("other", newPlanVM("", "fast")),
+			newNamespaceEntry("missing", newPlanVM("")),
+		)
+		applySchemaDefaults(plan)
+		Expect(defaulter.Default(context.Background(), plan)).To(Succeed())
+
+		entries, _, _ := unstructured.NestedSlice(plan.Object, "spec", "namespaces")
+		for _, entry := range entries {
+			Expect(entry).To(HaveKeyWithValue("retentionPolicy", "keepSource"))
+		}
+		Expect(plan.GetAnnotations()).To(HaveKeyWithValue(migrationsv1alpha1.AppliedDefaultsAnnotation,
+			`["/spec/namespaces/0/virtualMachines/0/targetMigrationPVCs/0/destinationPVC/storageClassName",`+
+				`"/spec/namespaces/1/virtualMachines/0/targetMigrationPVCs/0/destinationPVC/storageClassName",`+
+				`"/spec/namespaces/2/virtualMachines/0/targetMigrationPVCs/0/destinationPVC/storageClassName"]`))
+
+		storageClassOf := func(i int) []string {
+			vms, _, _ := unstructured.NestedSlice(entries[i].(map[string]interface{}), "virtualMachines")
+			pvcs, _, _ := unstructured.NestedSlice(vms[0].(map[string]interface{}), "targetMigrationPVCs")
+			var storageClasses []string
+			for _, pvc := range pvcs {
+				storageClass, _, _ := unstructured.NestedString(pvc.(map[string]interface{}), "destinationPVC", "storageClassName")
+				storageClasses = append(storageClasses, storageClass)
+			}
+			return storageClasses
+		}
+		Expect(storageClassOf(0)).To(Equal([]string{"cluster-default"}))
+		Expect(storageClassOf(1)).To(Equal([]string{"other-default", "fast"}))
+		Expect(storageClassOf(2)).To(Equal([]string{"cluster-default"}))
+	})
+})
@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+	"kubevirt.io/kubevirt-migration-operator/pkg/preflight"
+)
+
+const (
+	// maxPreflightVirtualMachines bounds the work of a dry run, the virtual machine, its instance and its PVCs
+	// are read from the API server one after the other for every virtual machine of the plan
+	maxPreflightVirtualMachines = 25
+
+	// preflightTimeout keeps a dry run within the default webhook timeout of 10 seconds
+	preflightTimeout = 8 * time.Second
+)
+
+var planpreflightlog = logf.Log.WithName("planpreflight-webhook")
+
+// SetupPlanPreflightWebhookWithManager registers the pre-flight dry run webhook for both plan kinds in the manager.
+// The plans are decoded as unstructured objects, so a single path serves both kinds. The cluster state is read
+// with the API reader, the manager cache only covers the operator namespace.
+func SetupPlanPreflightWebhookWithManager(mgr ctrl.Manager) error {
+	validator := &PlanPreflightCustomValidator{client: mgr.GetAPIReader()}
+	mgr.GetWebhookServer().Register(common.PlanPreflightValidatePath,
+		admission.WithCustomValidator(mgr.GetScheme(), newSingleNamespacePlan(), validator))
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-migrations-kubevirt-io-v1alpha1-preflight,mutating=false,failurePolicy=fail,sideEffects=None,groups=migrations.kubevirt.io,resources=virtualmachinestoragemigrationplans;multinamespacevirtualmachinestoragemigrationplans,verbs=create;update,versions=v1alpha1,name=vpreflight-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// PlanPreflightCustomValidator evaluates the plans carrying the pre-flight dry run annotation against
+// the cluster state and rejects them, returning the findings as warnings, so a dry run plan never runs.
+// Updates are only evaluated when they change the spec. Other plans are admitted untouched.
+type PlanPreflightCustomValidator struct {
+	client client.Reader
+}
+
+var _ webhook.CustomValidator = &PlanPreflightCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the plan types.
+func (v *PlanPreflightCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the plan types.
+func (v *PlanPreflightCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldPlan, ok := oldObj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected a %s or %s object but got %T", PlanKind, MultiNamespacePlanKind, oldObj)
+	}
+	newPlan, ok := newObj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected a %s or %s object but got %T", PlanKind, MultiNamespacePlanKind, newObj)
+	}
+	// status updates and finalizers removed from deleted plans do not change what the plan migrates
+	if newPlan.GetDeletionTimestamp() != nil || equality.Semantic.DeepEqual(oldPlan.Object["spec"], newPlan.Object["spec"]) {
+		return nil, nil
+	}
+	return v.validate(ctx, newPlan)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the plan types.
+func (v *PlanPreflightCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *PlanPreflightCustomValidator) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	plan, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected a %s or %s object but got %T", PlanKind, MultiNamespacePlanKind, obj)
+	}
+	if plan.GetAnnotations()[migrationsv1alpha1.PreflightDryRunAnnotation] != "true" {
+		return nil, nil
+	}
+
+	resource := migrationsv1alpha1.GroupVersion.WithResource(strings.ToLower(plan.GetKind()) + "s").GroupResource()
+	if count := preflight.CountVirtualMachines(plan); count > maxPreflightVirtualMachines {
+		return nil, apierrors.NewForbidden(resource, plan.GetName(), fmt.Errorf(
+			"the pre-flight dry run evaluates at most %d virtual machines but the plan has %d, "+
+				"run kubectl storagemigration preflight instead", maxPreflightVirtualMachines, count))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+	report, err := preflight.Analyze(ctx, v.client, plan)
+	if err != nil {
+		return nil, fmt.Errorf("unable to run the pre-flight checks; %w", err)
+	}
+	planpreflightlog.Info("Evaluated plan", "plan", client.ObjectKeyFromObject(plan), "result", report.Result)
+
+	summary := fmt.Sprintf("pre-flight dry run result %s for %d virtual machines, remove the %s annotation to run the plan",
+		report.Result, len(report.VirtualMachines), migrationsv1alpha1.PreflightDryRunAnnotation)
+	return report.Findings(), apierrors.NewForbidden(resource, plan.GetName(), errors.New(summary))
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+var _ = Describe("Plan pre-flight webhook", func() {
+	var (
+		validator *PlanPreflightCustomValidator
+		scheme    *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		gv := schema.GroupVersion{Group: "kubevirt.io", Version: "v1"}
+		scheme.AddKnownTypeWithName(gv.WithKind("KubeVirt"), &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(gv.WithKind("KubeVirtList"), &unstructured.UnstructuredList{})
+		validator = &PlanPreflightCustomValidator{client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+	})
+
+	It("should admit plans without the dry run annotation", func() {
+		plan := newSingleNamespacePlan()
+		plan.SetNamespace("ns1")
+		plan.SetName("plan")
+		warnings, err := validator.ValidateCreate(context.Background(), plan)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	newDryRunPlan := func() *unstructured.Unstructured {
+		plan := newSingleNamespacePlan()
+		plan.SetNamespace("ns1")
+		plan.SetName("plan")
+		plan.SetAnnotations(map[string]string{migrationsv1alpha1.PreflightDryRunAnnotation: "true"})
+		return plan
+	}
+
+	It("should reject dry run plans with the report as warnings", func() {
+		plan := newMultiNamespacePlan()
+		plan.SetNamespace("ns1")
+		plan.SetName("plan")
+		plan.SetAnnotations(map[string]string{migrationsv1alpha1.PreflightDryRunAnnotation: "true"})
+		warnings, err := validator.ValidateCreate(context.Background(), plan)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("pre-flight dry run result Fail for 0 virtual machines"))
+		Expect(warnings).To(ConsistOf("Fail FeatureGates: KubeVirt is not installed"))
+	})
+
+	It("should reject dry run plans passing the checks", func() {
+		kubeVirt := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		kubeVirt.SetAPIVersion("kubevirt.io/v1")
+		kubeVirt.SetKind("KubeVirt")
+		kubeVirt.SetNamespace("kubevirt")
+		kubeVirt.SetName("kubevirt")
+		Expect(unstructured.SetNestedField(kubeVirt.Object, "v1.5.0", "status", "observedKubeVirtVersion")).To(Succeed())
+		validator.client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubeVirt).Build()
+
+		warnings, err := validator.ValidateCreate(context.Background(), newDryRunPlan())
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("pre-flight dry run result Pass for 0 virtual machines, " +
+			"remove the migrations.kubevirt.io/preflight-dry-run annotation to run the plan"))
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("should evaluate dry run plans on updates changing the spec only", func() {
+		oldPlan := newDryRunPlan()
+		newPlan := oldPlan.DeepCopy()
+		Expect(unstructured.SetNestedField(newPlan.Object, "Ready", "status", "phase")).To(Succeed())
+		warnings, err := validator.ValidateUpdate(context.Background(), oldPlan, newPlan)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+
+		Expect(unstructured.SetNestedField(newPlan.Object, "deleteSource", "spec", "retentionPolicy")).To(Succeed())
+		_, err = validator.ValidateUpdate(context.Background(), oldPlan, newPlan)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+
+		By("Skipping deleted plans")
+		deletionTimestamp := metav1.Now()
+		newPlan.SetDeletionTimestamp(&deletionTimestamp)
+		warnings, err = validator.ValidateUpdate(context.Background(), oldPlan, newPlan)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("should reject dry run plans with too many virtual machines without evaluating them", func() {
+		plan := newDryRunPlan()
+		var vms []interface{}
+		for i := 0; i <= maxPreflightVirtualMachines; i++ {
+			vms = append(vms, map[string]interface{}{"name": fmt.Sprintf("vm%d", i)})
+		}
+		Expect(unstructured.SetNestedSlice(plan.Object, vms, "spec", "virtualMachines")).To(Succeed())
+		warnings, err := validator.ValidateCreate(context.Background(), plan)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("evaluates at most 25 virtual machines but the plan has 26"))
+		Expect(warnings).To(BeEmpty())
+	})
+})
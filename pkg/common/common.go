@@ -1,5 +1,10 @@
 package common
 
+import (
+	"os"
+	"strings"
+)
+
 // Common types and constants used by the importer and controller.
 // TODO: maybe the vm cloner can use these common values
 
@@ -37,6 +42,24 @@ const (
 	ComponentLabel = "migrations.kubevirt.io"
 	// ConfigMapName is the name of the configmap that owns controller resources
 	ConfigMapName = "kubevirt-migration-controller-config"
+	// HistoryConfigMapName is the name of the configmap archiving the final status of collected storage migrations
+	HistoryConfigMapName = "kubevirt-migration-history"
+
+	// TargetNamespacesAnnotation is set by OLM on the operator pods to the OperatorGroup target namespaces
+	TargetNamespacesAnnotation = "olm.targetNamespaces"
+	// TargetNamespacesEnv passes the comma separated OperatorGroup target namespaces to the operator,
+	// empty when the operator targets all namespaces
+	TargetNamespacesEnv = "TARGET_NAMESPACES"
+	// MultiNamespacePlanValidatePath is the path the operator serves the multi namespace plan validating webhook at
+	MultiNamespacePlanValidatePath = "/validate-migrations-kubevirt-io-v1alpha1-multinamespacevirtualmachinestoragemigrationplan"
+	// MigrationQuotaValidatePath is the path the operator serves the storage migration quota validating webhook at
+	MigrationQuotaValidatePath = "/validate-migrations-kubevirt-io-v1alpha1-virtualmachinestoragemigration"
+	// PlanDefaultsMutatePath is the path the operator serves the plan defaults mutating webhook at
+	PlanDefaultsMutatePath = "/mutate-migrations-kubevirt-io-v1alpha1-virtualmachinestoragemigrationplan"
+	// PlanPreflightValidatePath is the path the operator serves the plan pre-flight dry run validating webhook at
+	PlanPreflightValidatePath = "/validate-migrations-kubevirt-io-v1alpha1-preflight"
+	// WebhookServerPort is the port the operator webhook server listens on
+	WebhookServerPort = 9443
 
 	// AllowAccessClusterServicesNPLabel is a pod label to be set by virt-components to indicate that they require
 	// access to cluster services otherwise blocked by a strict network policy (NP).
@@ -45,3 +68,19 @@ const (
 	// - kubevirt-migration-operator
 	AllowAccessClusterServicesNPLabel string = "np.kubevirt.io/allow-access-cluster-services"
 )
+
+// GetTargetNamespaces returns the OperatorGroup target namespaces passed in TargetNamespacesEnv,
+// nil when the operator targets all namespaces
+func GetTargetNamespaces() []string {
+	value := strings.TrimSpace(os.Getenv(TargetNamespacesEnv))
+	if value == "" {
+		return nil
+	}
+	var namespaces []string
+	for _, namespace := range strings.Split(value, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
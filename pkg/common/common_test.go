@@ -0,0 +1,49 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCommon(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Common Suite")
+}
+
+var _ = Describe("Target namespaces", func() {
+	DescribeTable("should parse the OperatorGroup target namespaces", func(value string, expected []string) {
+		os.Setenv(TargetNamespacesEnv, value)
+		DeferCleanup(os.Unsetenv, TargetNamespacesEnv)
+		Expect(GetTargetNamespaces()).To(Equal(expected))
+	},
+		Entry("all namespaces", "", nil),
+		Entry("all namespaces with whitespace", " ", nil),
+		Entry("own or single namespace", "ns1", []string{"ns1"}),
+		Entry("multiple namespaces", "ns1,ns2", []string{"ns1", "ns2"}),
+		Entry("multiple namespaces with whitespace and empty entries", " ns1 , ,ns2,", []string{"ns1", "ns2"}),
+	)
+
+	It("should target all namespaces without the environment variable", func() {
+		os.Unsetenv(TargetNamespacesEnv)
+		Expect(GetTargetNamespaces()).To(BeNil())
+	})
+})
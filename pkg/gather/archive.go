@@ -0,0 +1,90 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gather writes diagnostic bundles of the redacted cluster state, as gzipped tarballs or directory trees
+package gather
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"path"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// Bundle holds the files of a diagnostic bundle, the names are slash separated and relative to its top directory
+type Bundle interface {
+	// AddFile adds a file of data at name
+	AddFile(name string, data []byte) error
+	// AddObject redacts the object and adds it as a YAML file at name
+	AddObject(name string, obj map[string]interface{}) error
+	// Close flushes the bundle
+	Close() error
+}
+
+// Archive writes files into a gzipped tarball, below a top directory
+type Archive struct {
+	dir     string
+	modTime time.Time
+	gzip    *gzip.Writer
+	tar     *tar.Writer
+}
+
+// NewArchive returns an archive writing to w, the files are put below dir
+func NewArchive(w io.Writer, dir string) *Archive {
+	gz := gzip.NewWriter(w)
+	return &Archive{dir: dir, modTime: time.Now(), gzip: gz, tar: tar.NewWriter(gz)}
+}
+
+// AddFile adds a file of data at name, relative to the top directory
+func (a *Archive) AddFile(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    path.Join(a.dir, name),
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: a.modTime,
+	}
+	if err := a.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := a.tar.Write(data)
+	return err
+}
+
+// AddObject redacts the object and adds it as a YAML file at name, relative to the top directory
+func (a *Archive) AddObject(name string, obj map[string]interface{}) error {
+	data, err := marshalObject(obj)
+	if err != nil {
+		return err
+	}
+	return a.AddFile(name, data)
+}
+
+// Close flushes the archive, it does not close the underlying writer
+func (a *Archive) Close() error {
+	if err := a.tar.Close(); err != nil {
+		return err
+	}
+	return a.gzip.Close()
+}
+
+// marshalObject redacts the object and returns it as YAML
+func marshalObject(obj map[string]interface{}) ([]byte, error) {
+	Redact(obj)
+	return yaml.Marshal(obj)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Directory writes files into a directory tree, below a top directory. It is the layout must-gather
+// copies out of the /must-gather directory of the gather image
+type Directory struct {
+	dir string
+}
+
+// NewDirectory returns a directory bundle writing the files below dir in root
+func NewDirectory(root, dir string) *Directory {
+	return &Directory{dir: filepath.Join(root, dir)}
+}
+
+// AddFile adds a file of data at name, relative to the top directory
+func (d *Directory) AddFile(name string, data []byte) error {
+	// cleaning the name as rooted keeps the file below the top directory
+	file := filepath.Join(d.dir, filepath.FromSlash(path.Clean("/"+name)))
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
+
+// AddObject redacts the object and adds it as a YAML file at name, relative to the top directory
+func (d *Directory) AddObject(name string, obj map[string]interface{}) error {
+	data, err := marshalObject(obj)
+	if err != nil {
+		return err
+	}
+	return d.AddFile(name, data)
+}
+
+// Close implements Bundle, the files are written as they are added
+func (d *Directory) Close() error {
+	return nil
+}
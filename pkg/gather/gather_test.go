@@ -0,0 +1,159 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestGather(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Gather Suite")
+}
+
+func toUnstructured(obj runtime.Object, kind string) map[string]interface{} {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	Expect(err).ToNot(HaveOccurred())
+	u["kind"] = kind
+	return u
+}
+
+var _ = Describe("Gather", func() {
+	It("should redact the values of secrets", func() {
+		secret := toUnstructured(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "credentials",
+				Annotations: map[string]string{lastAppliedAnnotation: `{"data":{"password":"c2VjcmV0"}}`},
+			},
+			Data:       map[string][]byte{"password": []byte("secret")},
+			StringData: map[string]string{"token": "secret"},
+		}, "Secret")
+		Redact(secret)
+
+		Expect(secret["data"]).To(Equal(map[string]interface{}{"password": Redacted}))
+		Expect(secret["stringData"]).To(Equal(map[string]interface{}{"token": Redacted}))
+		annotation, _, _ := unstructured.NestedString(secret, "metadata", "annotations", lastAppliedAnnotation)
+		Expect(annotation).To(Equal(Redacted))
+	})
+
+	It("should redact sensitive environment variables of containers", func() {
+		deployment := toUnstructured(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:          "controller",
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "operator"}},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name: "controller",
+							Env: []corev1.EnvVar{
+								{Name: "VERBOSITY", Value: "1"},
+								{Name: "API_TOKEN", Value: "secret"},
+								{Name: "DB_PASSWORD", ValueFrom: &corev1.EnvVarSource{}},
+							},
+						}},
+					},
+				},
+			},
+		}, "Deployment")
+		Redact(deployment)
+
+		containers, _, _ := unstructured.NestedSlice(deployment, "spec", "template", "spec", "containers")
+		env := containers[0].(map[string]interface{})["env"].([]interface{})
+		Expect(env[0]).To(HaveKeyWithValue("value", "1"))
+		Expect(env[1]).To(HaveKeyWithValue("value", Redacted))
+		Expect(env[2]).ToNot(HaveKey("value"))
+		Expect(deployment["metadata"]).ToNot(HaveKey("managedFields"))
+	})
+
+	It("should write the files below the top directory", func() {
+		out := &bytes.Buffer{}
+		archive := NewArchive(out, "must-gather")
+		Expect(archive.AddFile("logs/operator.log", []byte("started\n"))).To(Succeed())
+		Expect(archive.AddObject("secrets/credentials.yaml", map[string]interface{}{
+			"kind": "Secret",
+			"data": map[string]interface{}{"password": "c2VjcmV0"},
+		})).To(Succeed())
+		Expect(archive.Close()).To(Succeed())
+
+		gz, err := gzip.NewReader(out)
+		Expect(err).ToNot(HaveOccurred())
+		files := map[string]string{}
+		reader := tar.NewReader(gz)
+		for {
+			header, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).ToNot(HaveOccurred())
+			data, err := io.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			files[header.Name] = string(data)
+		}
+		Expect(files).To(Equal(map[string]string{
+			"must-gather/logs/operator.log":        "started\n",
+			"must-gather/secrets/credentials.yaml": "data:\n  password: REDACTED\nkind: Secret\n",
+		}))
+	})
+
+	It("should write the files into a directory tree below the top directory", func() {
+		root := GinkgoT().TempDir()
+		directory := NewDirectory(root, "must-gather")
+		Expect(directory.AddFile("logs/operator.log", []byte("started\n"))).To(Succeed())
+		Expect(directory.AddFile("../outside.log", []byte("kept inside\n"))).To(Succeed())
+		Expect(directory.AddObject("secrets/credentials.yaml", map[string]interface{}{
+			"kind": "Secret",
+			"data": map[string]interface{}{"password": "c2VjcmV0"},
+		})).To(Succeed())
+		Expect(directory.Close()).To(Succeed())
+
+		files := map[string]string{}
+		Expect(filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			name, err := filepath.Rel(root, file)
+			files[filepath.ToSlash(name)] = string(data)
+			return err
+		})).To(Succeed())
+		Expect(files).To(Equal(map[string]string{
+			"must-gather/logs/operator.log":        "started\n",
+			"must-gather/outside.log":              "kept inside\n",
+			"must-gather/secrets/credentials.yaml": "data:\n  password: REDACTED\nkind: Secret\n",
+		}))
+	})
+})
@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Redacted replaces the redacted values
+const Redacted = "REDACTED"
+
+// lastAppliedAnnotation holds a copy of the object as applied with kubectl, secrets included
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// sensitiveEnv matches the names of the environment variables whose values are redacted
+var sensitiveEnv = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|key)`)
+
+// Redact removes the secret values of the object in place: the values of a Secret, the values of
+// environment variables with sensitive names and the kubectl last applied configuration. Managed
+// fields are dropped as well, they only add noise to the bundle
+func Redact(obj map[string]interface{}) {
+	u := &unstructured.Unstructured{Object: obj}
+	if u.GetKind() == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			if values, found, _ := unstructured.NestedMap(obj, field); found {
+				for key := range values {
+					values[key] = Redacted
+				}
+				_ = unstructured.SetNestedMap(obj, values, field)
+			}
+		}
+	}
+	if annotations := u.GetAnnotations(); annotations[lastAppliedAnnotation] != "" {
+		annotations[lastAppliedAnnotation] = Redacted
+		u.SetAnnotations(annotations)
+	}
+	unstructured.RemoveNestedField(obj, "metadata", "managedFields")
+	redactEnv(obj)
+}
+
+// redactEnv walks the object for container env lists, wherever the containers are nested
+func redactEnv(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if env, ok := field.([]interface{}); ok && key == "env" {
+				for _, e := range env {
+					if variable, ok := e.(map[string]interface{}); ok {
+						if name, _ := variable["name"].(string); sensitiveEnv.MatchString(name) && variable["value"] != nil {
+							variable["value"] = Redacted
+						}
+					}
+				}
+				continue
+			}
+			redactEnv(field)
+		}
+	case []interface{}:
+		for _, item := range v {
+			redactEnv(item)
+		}
+	}
+}
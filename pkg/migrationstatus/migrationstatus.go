@@ -0,0 +1,88 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migrationstatus reads the progress of storage migrations from their status
+package migrationstatus
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// terminalPhases are the phases of storage migrations that are done migrating. The migration controller
+// is not a dependency of the operator, these mirror the terminal phases it reports
+var terminalPhases = map[string]bool{
+	"Completed": true,
+	"Failed":    true,
+	"Cancelled": true,
+}
+
+// IsTerminalPhase tells whether phase is one of a storage migration that is done migrating
+func IsTerminalPhase(phase string) bool {
+	return terminalPhases[phase]
+}
+
+// IsFinished tells whether the storage migration reached a terminal phase, a multi namespace
+// migration reports its phase per namespace and is finished once all of them are
+func IsFinished(migration *unstructured.Unstructured) bool {
+	if phase, found, _ := unstructured.NestedString(migration.Object, "status", "phase"); found {
+		return IsTerminalPhase(phase)
+	}
+	namespaces, _, _ := unstructured.NestedSlice(migration.Object, "status", "namespaces")
+	if len(namespaces) == 0 {
+		return false
+	}
+	for _, namespace := range namespaces {
+		ns, ok := namespace.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if phase, _, _ := unstructured.NestedString(ns, "phase"); !IsTerminalPhase(phase) {
+			return false
+		}
+	}
+	return true
+}
+
+// FinishedAt returns the last transition of the storage migration conditions, its creation
+// when it has none
+func FinishedAt(migration *unstructured.Unstructured) time.Time {
+	finishedAt := migration.GetCreationTimestamp().Time
+	latest := func(conds []interface{}) {
+		for _, cond := range conds {
+			c, ok := cond.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			value, _, _ := unstructured.NestedString(c, "lastTransitionTime")
+			if t, err := time.Parse(time.RFC3339, value); err == nil && t.After(finishedAt) {
+				finishedAt = t
+			}
+		}
+	}
+
+	conds, _, _ := unstructured.NestedSlice(migration.Object, "status", "conditions")
+	latest(conds)
+	namespaces, _, _ := unstructured.NestedSlice(migration.Object, "status", "namespaces")
+	for _, namespace := range namespaces {
+		if ns, ok := namespace.(map[string]interface{}); ok {
+			conds, _, _ := unstructured.NestedSlice(ns, "conditions")
+			latest(conds)
+		}
+	}
+	return finishedAt
+}
@@ -0,0 +1,86 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationstatus
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMigrationStatus(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Migration Status Suite")
+}
+
+var created = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newMigration(status map[string]interface{}) *unstructured.Unstructured {
+	migration := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	migration.SetCreationTimestamp(metav1.NewTime(created))
+	if status != nil {
+		migration.Object["status"] = status
+	}
+	return migration
+}
+
+func newCondition(transitioned time.Time) map[string]interface{} {
+	return map[string]interface{}{"type": "Ready", "lastTransitionTime": transitioned.Format(time.RFC3339)}
+}
+
+var _ = Describe("Migration status", func() {
+	DescribeTable("should tell finished storage migrations",
+		func(status map[string]interface{}, finished bool) {
+			Expect(IsFinished(newMigration(status))).To(Equal(finished))
+		},
+		Entry("without status", nil, false),
+		Entry("running", map[string]interface{}{"phase": "Running"}, false),
+		Entry("completed", map[string]interface{}{"phase": "Completed"}, true),
+		Entry("failed", map[string]interface{}{"phase": "Failed"}, true),
+		Entry("cancelled", map[string]interface{}{"phase": "Cancelled"}, true),
+		Entry("finished in every namespace", map[string]interface{}{"namespaces": []interface{}{
+			map[string]interface{}{"phase": "Completed"}, map[string]interface{}{"phase": "Failed"},
+		}}, true),
+		Entry("running in a namespace", map[string]interface{}{"namespaces": []interface{}{
+			map[string]interface{}{"phase": "Completed"}, map[string]interface{}{"phase": "Running"},
+		}}, false),
+	)
+
+	It("should pin the terminal phases reported by the migration controller", func() {
+		Expect(terminalPhases).To(Equal(map[string]bool{"Completed": true, "Failed": true, "Cancelled": true}))
+	})
+
+	DescribeTable("should return when storage migrations finished",
+		func(status map[string]interface{}, finishedAt time.Time) {
+			Expect(FinishedAt(newMigration(status))).To(BeTemporally("==", finishedAt))
+		},
+		Entry("at creation without conditions", map[string]interface{}{"phase": "Completed"}, created),
+		Entry("at the last condition transition", map[string]interface{}{"conditions": []interface{}{
+			newCondition(created.Add(2 * time.Hour)), newCondition(created.Add(time.Hour)),
+		}}, created.Add(2*time.Hour)),
+		Entry("at the last condition transition of any namespace", map[string]interface{}{
+			"conditions": []interface{}{newCondition(created.Add(time.Hour))},
+			"namespaces": []interface{}{
+				map[string]interface{}{"conditions": []interface{}{newCondition(created.Add(3 * time.Hour))}},
+			},
+		}, created.Add(3*time.Hour)),
+	)
+})
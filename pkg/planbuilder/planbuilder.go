@@ -0,0 +1,281 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package planbuilder builds storage migration plans for the virtual machines matching a label selector
+package planbuilder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+const (
+	// PlanKind is the kind of the plans migrating virtual machines of a single namespace
+	PlanKind = "VirtualMachineStorageMigrationPlan"
+	// MultiNamespacePlanKind is the kind of the plans migrating virtual machines across namespaces
+	MultiNamespacePlanKind = "MultiNamespaceVirtualMachineStorageMigrationPlan"
+)
+
+// VirtualMachineGVK is the KubeVirt virtual machine kind, the plans reference virtual machines by name
+var VirtualMachineGVK = schema.GroupVersionKind{Group: "kubevirt.io", Version: "v1", Kind: "VirtualMachine"}
+
+// Options select the virtual machines and volumes to migrate and how they are split into plans
+type Options struct {
+	// Name of the plan, plans of more than one batch are suffixed with the batch number
+	Name string
+	// Namespaces the virtual machines are looked up in
+	Namespaces []string
+	// Selector the virtual machines have to match
+	Selector labels.Selector
+	// SourceStorageClass restricts the migrated volumes to the ones on this storage class, all volumes when empty
+	SourceStorageClass string
+	// TargetStorageClass is the storage class the volumes are migrated to
+	TargetStorageClass string
+	// RetentionPolicy of the plans, left to the plan default when empty
+	RetentionPolicy string
+	// BatchSize is the number of virtual machines per plan, all virtual machines go into one plan when 0
+	BatchSize int
+	// MultiNamespace builds multi namespace plans in PlanNamespace instead of a plan per namespace
+	MultiNamespace bool
+	// PlanNamespace is the namespace of the multi namespace plans
+	PlanNamespace string
+}
+
+// virtualMachine is a virtual machine of a plan along with the volumes to migrate
+type virtualMachine struct {
+	namespace string
+	entry     map[string]interface{}
+}
+
+// Build returns the plans migrating the volumes of the selected virtual machines, and warnings about
+// the virtual machines that are left out. Every plan is validated against the plan CRD schema
+func Build(ctx context.Context, c client.Reader, opts Options) ([]*unstructured.Unstructured, []string, error) {
+	if opts.Name == "" || opts.TargetStorageClass == "" {
+		return nil, nil, fmt.Errorf("a plan name and a target storage class are required")
+	}
+	if opts.MultiNamespace && opts.PlanNamespace == "" {
+		return nil, nil, fmt.Errorf("multi namespace plans need a plan namespace")
+	}
+	if opts.BatchSize < 0 {
+		return nil, nil, fmt.Errorf("invalid batch size %d", opts.BatchSize)
+	}
+
+	var vms []virtualMachine
+	var warnings []string
+	namespaces := append([]string(nil), opts.Namespaces...)
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		nsVMs, nsWarnings, err := getVirtualMachines(ctx, c, namespace, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		vms = append(vms, nsVMs...)
+		warnings = append(warnings, nsWarnings...)
+	}
+
+	var plans []*unstructured.Unstructured
+	if opts.MultiNamespace {
+		batches := batch(vms, opts.BatchSize)
+		for i, b := range batches {
+			plans = append(plans, newMultiNamespacePlan(planName(opts.Name, i, len(batches)), opts, b))
+		}
+	} else {
+		for _, namespace := range namespaces {
+			var nsVMs []virtualMachine
+			for _, vm := range vms {
+				if vm.namespace == namespace {
+					nsVMs = append(nsVMs, vm)
+				}
+			}
+			batches := batch(nsVMs, opts.BatchSize)
+			for i, b := range batches {
+				plans = append(plans, newPlan(planName(opts.Name, i, len(batches)), namespace, opts, b))
+			}
+		}
+	}
+
+	for _, plan := range plans {
+		if err := Validate(plan); err != nil {
+			return nil, nil, err
+		}
+	}
+	return plans, warnings, nil
+}
+
+// getVirtualMachines returns the selected virtual machines of the namespace that have volumes to migrate
+func getVirtualMachines(ctx context.Context, c client.Reader, namespace string, opts Options) ([]virtualMachine, []string, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(VirtualMachineGVK.GroupVersion().WithKind(VirtualMachineGVK.Kind + "List"))
+	listOpts := []client.ListOption{client.InNamespace(namespace)}
+	if opts.Selector != nil {
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: opts.Selector})
+	}
+	if err := c.List(ctx, list, listOpts...); err != nil {
+		return nil, nil, fmt.Errorf("unable to list the virtual machines in namespace %s; %w", namespace, err)
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+
+	var vms []virtualMachine
+	var warnings []string
+	for i := range list.Items {
+		vm := &list.Items[i]
+		pvcs, err := getTargetMigrationPVCs(ctx, c, vm, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(pvcs) == 0 {
+			warnings = append(warnings, fmt.Sprintf("virtual machine %s/%s has no volumes to migrate", namespace, vm.GetName()))
+			continue
+		}
+		vms = append(vms, virtualMachine{
+			namespace: namespace,
+			entry: map[string]interface{}{
+				"name":                vm.GetName(),
+				"targetMigrationPVCs": pvcs,
+			},
+		})
+	}
+	return vms, warnings, nil
+}
+
+// getTargetMigrationPVCs returns the volumes of the virtual machine backed by a PVC or DataVolume on the
+// source storage class, the destination PVC names are left to the controller
+func getTargetMigrationPVCs(ctx context.Context, c client.Reader, vm *unstructured.Unstructured, opts Options) ([]interface{}, error) {
+	volumes, _, err := unstructured.NestedSlice(vm.Object, "spec", "template", "spec", "volumes")
+	if err != nil {
+		return nil, fmt.Errorf("invalid volumes in virtual machine %s/%s; %w", vm.GetNamespace(), vm.GetName(), err)
+	}
+
+	var pvcs []interface{}
+	for _, v := range volumes {
+		volume, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		volumeName, _, _ := unstructured.NestedString(volume, "name")
+		claimName := getClaimName(volume)
+		if claimName == "" {
+			continue
+		}
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: vm.GetNamespace(), Name: claimName}, pvc); err != nil {
+			return nil, fmt.Errorf("unable to get PVC %s of virtual machine %s/%s; %w", claimName, vm.GetNamespace(), vm.GetName(), err)
+		}
+		storageClass := ""
+		if pvc.Spec.StorageClassName != nil {
+			storageClass = *pvc.Spec.StorageClassName
+		}
+		if storageClass == opts.TargetStorageClass ||
+			(opts.SourceStorageClass != "" && storageClass != opts.SourceStorageClass) {
+			continue
+		}
+
+		pvcs = append(pvcs, map[string]interface{}{
+			"volumeName": volumeName,
+			"destinationPVC": map[string]interface{}{
+				"storageClassName": opts.TargetStorageClass,
+			},
+		})
+	}
+	return pvcs, nil
+}
+
+// getClaimName returns the PVC backing the volume, DataVolumes own a PVC of the same name
+func getClaimName(volume map[string]interface{}) string {
+	if name, _, _ := unstructured.NestedString(volume, "persistentVolumeClaim", "claimName"); name != "" {
+		return name
+	}
+	name, _, _ := unstructured.NestedString(volume, "dataVolume", "name")
+	return name
+}
+
+func batch(vms []virtualMachine, size int) [][]virtualMachine {
+	if len(vms) == 0 {
+		return nil
+	}
+	if size == 0 {
+		return [][]virtualMachine{vms}
+	}
+	var batches [][]virtualMachine
+	for start := 0; start < len(vms); start += size {
+		batches = append(batches, vms[start:min(start+size, len(vms))])
+	}
+	return batches
+}
+
+func planName(name string, i, batches int) string {
+	if batches == 1 {
+		return name
+	}
+	return fmt.Sprintf("%s-%d", name, i+1)
+}
+
+func newPlan(name, namespace string, opts Options, vms []virtualMachine) *unstructured.Unstructured {
+	var entries []interface{}
+	for _, vm := range vms {
+		entries = append(entries, vm.entry)
+	}
+	spec := map[string]interface{}{"virtualMachines": entries}
+	if opts.RetentionPolicy != "" {
+		spec["retentionPolicy"] = opts.RetentionPolicy
+	}
+
+	plan := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	plan.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind(PlanKind))
+	plan.SetName(name)
+	plan.SetNamespace(namespace)
+	return plan
+}
+
+func newMultiNamespacePlan(name string, opts Options, vms []virtualMachine) *unstructured.Unstructured {
+	var namespaces []interface{}
+	entries := map[string][]interface{}{}
+	for _, vm := range vms {
+		if _, ok := entries[vm.namespace]; !ok {
+			namespaces = append(namespaces, vm.namespace)
+		}
+		entries[vm.namespace] = append(entries[vm.namespace], vm.entry)
+	}
+	var namespaceEntries []interface{}
+	for _, namespace := range namespaces {
+		namespaceEntries = append(namespaceEntries, map[string]interface{}{
+			"name":            namespace,
+			"virtualMachines": entries[namespace.(string)],
+		})
+	}
+	spec := map[string]interface{}{"namespaces": namespaceEntries}
+	if opts.RetentionPolicy != "" {
+		spec["retentionPolicy"] = opts.RetentionPolicy
+	}
+
+	plan := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	plan.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind(MultiNamespacePlanKind))
+	plan.SetName(name)
+	plan.SetNamespace(opts.PlanNamespace)
+	return plan
+}
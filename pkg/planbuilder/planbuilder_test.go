@@ -0,0 +1,178 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPlanBuilder(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Plan Builder Suite")
+}
+
+func newVM(namespace, name string, app string, claims ...string) *unstructured.Unstructured {
+	var volumes []interface{}
+	for i, claim := range claims {
+		volume := map[string]interface{}{"name": "disk" + string(rune('0'+i))}
+		if i%2 == 0 {
+			volume["persistentVolumeClaim"] = map[string]interface{}{"claimName": claim}
+		} else {
+			volume["dataVolume"] = map[string]interface{}{"name": claim}
+		}
+		volumes = append(volumes, volume)
+	}
+	volumes = append(volumes, map[string]interface{}{"name": "cloudinit", "cloudInitNoCloud": map[string]interface{}{}})
+
+	vm := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	vm.SetGroupVersionKind(VirtualMachineGVK)
+	vm.SetNamespace(namespace)
+	vm.SetName(name)
+	vm.SetLabels(map[string]string{"app": app})
+	Expect(unstructured.SetNestedSlice(vm.Object, volumes, "spec", "template", "spec", "volumes")).To(Succeed())
+	return vm
+}
+
+func newPVC(namespace, name, storageClass string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: ptr.To(storageClass)},
+	}
+}
+
+func vmNames(plan *unstructured.Unstructured, path ...string) []string {
+	vms, _, _ := unstructured.NestedSlice(plan.Object, path...)
+	var names []string
+	for _, vm := range vms {
+		names = append(names, vm.(map[string]interface{})["name"].(string))
+	}
+	return names
+}
+
+var _ = Describe("Plan builder", func() {
+	var (
+		c    client.Client
+		opts Options
+		ctx  = context.Background()
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		scheme.AddKnownTypeWithName(VirtualMachineGVK, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(VirtualMachineGVK.GroupVersion().WithKind("VirtualMachineList"), &unstructured.UnstructuredList{})
+
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newVM("ns1", "vm-a", "db", "a-root", "a-data"),
+			newVM("ns1", "vm-b", "db", "b-root"),
+			newVM("ns1", "vm-c", "web", "c-root"),
+			newVM("ns2", "vm-d", "db", "d-root"),
+			newVM("ns2", "vm-e", "db", "e-root"),
+			newPVC("ns1", "a-root", "old"),
+			newPVC("ns1", "a-data", "other"),
+			newPVC("ns1", "b-root", "old"),
+			newPVC("ns1", "c-root", "old"),
+			newPVC("ns2", "d-root", "old"),
+			newPVC("ns2", "e-root", "new"),
+		).Build()
+
+		opts = Options{
+			Name:               "evacuate",
+			Namespaces:         []string{"ns2", "ns1"},
+			Selector:           labels.SelectorFromSet(labels.Set{"app": "db"}),
+			SourceStorageClass: "old",
+			TargetStorageClass: "new",
+			RetentionPolicy:    "keepSource",
+		}
+	})
+
+	It("should build a plan per namespace with the volumes on the source storage class", func() {
+		plans, warnings, err := Build(ctx, c, opts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warnings).To(ConsistOf("virtual machine ns2/vm-e has no volumes to migrate"))
+		Expect(plans).To(HaveLen(2))
+
+		Expect(plans[0].GetKind()).To(Equal(PlanKind))
+		Expect(plans[0].GetNamespace()).To(Equal("ns1"))
+		Expect(plans[0].GetName()).To(Equal("evacuate"))
+		Expect(vmNames(plans[0], "spec", "virtualMachines")).To(Equal([]string{"vm-a", "vm-b"}))
+		vms, _, _ := unstructured.NestedSlice(plans[0].Object, "spec", "virtualMachines")
+		Expect(vms[0].(map[string]interface{})["targetMigrationPVCs"]).To(Equal([]interface{}{
+			map[string]interface{}{
+				"volumeName":     "disk0",
+				"destinationPVC": map[string]interface{}{"storageClassName": "new"},
+			},
+		}))
+		Expect(plans[0].Object["spec"]).To(HaveKeyWithValue("retentionPolicy", "keepSource"))
+
+		Expect(plans[1].GetNamespace()).To(Equal("ns2"))
+		Expect(vmNames(plans[1], "spec", "virtualMachines")).To(Equal([]string{"vm-d"}))
+	})
+
+	It("should split the virtual machines into batches", func() {
+		opts.Selector = labels.Everything()
+		opts.BatchSize = 2
+		plans, _, err := Build(ctx, c, opts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plans).To(HaveLen(3))
+		Expect(plans[0].GetName()).To(Equal("evacuate-1"))
+		Expect(vmNames(plans[0], "spec", "virtualMachines")).To(Equal([]string{"vm-a", "vm-b"}))
+		Expect(plans[1].GetName()).To(Equal("evacuate-2"))
+		Expect(vmNames(plans[1], "spec", "virtualMachines")).To(Equal([]string{"vm-c"}))
+		Expect(plans[2].GetName()).To(Equal("evacuate"))
+		Expect(plans[2].GetNamespace()).To(Equal("ns2"))
+	})
+
+	It("should build multi namespace plans in the plan namespace", func() {
+		opts.MultiNamespace = true
+		opts.PlanNamespace = "plans"
+		opts.BatchSize = 2
+		plans, _, err := Build(ctx, c, opts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plans).To(HaveLen(2))
+		for _, plan := range plans {
+			Expect(plan.GetKind()).To(Equal(MultiNamespacePlanKind))
+			Expect(plan.GetNamespace()).To(Equal("plans"))
+		}
+		namespaces, _, _ := unstructured.NestedSlice(plans[0].Object, "spec", "namespaces")
+		Expect(namespaces).To(HaveLen(1))
+		Expect(namespaces[0]).To(HaveKeyWithValue("name", "ns1"))
+		Expect(vmNames(plans[0], "spec", "namespaces")).To(Equal([]string{"ns1"}))
+		namespaces, _, _ = unstructured.NestedSlice(plans[1].Object, "spec", "namespaces")
+		Expect(namespaces).To(HaveLen(1))
+		Expect(namespaces[0]).To(HaveKeyWithValue("name", "ns2"))
+	})
+
+	It("should reject plans not matching the plan schema", func() {
+		opts.RetentionPolicy = "forget"
+		_, _, err := Build(ctx, c, opts)
+		Expect(err).To(MatchError(ContainSubstring("spec.retentionPolicy")))
+	})
+})
@@ -0,0 +1,66 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources"
+)
+
+// Validate checks the object against the schema of its kind in the CRDs the operator installs
+func Validate(obj *unstructured.Unstructured) error {
+	gvk := obj.GroupVersionKind()
+	crds, err := resources.NewCRDs()
+	if err != nil {
+		return err
+	}
+
+	for _, crd := range crds {
+		if crd.Spec.Group != gvk.Group || crd.Spec.Names.Kind != gvk.Kind {
+			continue
+		}
+		for _, version := range crd.Spec.Versions {
+			if version.Name != gvk.Version || version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			schema := &apiextensions.JSONSchemaProps{}
+			if err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(version.Schema.OpenAPIV3Schema, schema, nil); err != nil {
+				return err
+			}
+			validator, _, err := validation.NewSchemaValidator(schema)
+			if err != nil {
+				return err
+			}
+			if errs := validation.ValidateCustomResource(nil, obj.UnstructuredContent(), validator); len(errs) > 0 {
+				var messages []string
+				for _, e := range errs {
+					messages = append(messages, e.Error())
+				}
+				return fmt.Errorf("invalid %s %s/%s: %s", gvk.Kind, obj.GetNamespace(), obj.GetName(), strings.Join(messages, "; "))
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no schema for %s", gvk)
+}
@@ -0,0 +1,433 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preflight evaluates storage migration plans against the cluster state before they run
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Result is the outcome of a check, a report or a virtual machine
+type Result string
+
+const (
+	// ResultPass means nothing stands in the way of the migration
+	ResultPass Result = "Pass"
+	// ResultWarn means the migration may run but deserves a look
+	ResultWarn Result = "Warn"
+	// ResultFail means the migration is going to fail
+	ResultFail Result = "Fail"
+
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	liveMigratableCondition       = "LiveMigratable"
+)
+
+var (
+	kubeVirtListGVK = schema.GroupVersionKind{Group: "kubevirt.io", Version: "v1", Kind: "KubeVirtList"}
+	vmGVK           = schema.GroupVersionKind{Group: "kubevirt.io", Version: "v1", Kind: "VirtualMachine"}
+	vmiGVK          = schema.GroupVersionKind{Group: "kubevirt.io", Version: "v1", Kind: "VirtualMachineInstance"}
+
+	// RequiredFeatureGates are the KubeVirt feature gates storage migrations rely on, with the KubeVirt
+	// version enabling them by default. Both graduated to beta, which KubeVirt enables unless disabled
+	RequiredFeatureGates = map[string]string{
+		"VolumesUpdateStrategy": "v1.5.0",
+		"VolumeMigration":       "v1.5.0",
+	}
+)
+
+// Check is the result of a single check
+type Check struct {
+	Name    string `json:"name"`
+	Result  Result `json:"result"`
+	Message string `json:"message,omitempty"`
+}
+
+// VirtualMachineReport holds the checks of a virtual machine of the plan
+type VirtualMachineReport struct {
+	Namespace string  `json:"namespace"`
+	Name      string  `json:"name"`
+	Result    Result  `json:"result"`
+	Checks    []Check `json:"checks"`
+}
+
+// PlanReport holds the checks of a plan, the overall result is the worst of all checks
+type PlanReport struct {
+	Kind            string                 `json:"kind"`
+	Namespace       string                 `json:"namespace"`
+	Name            string                 `json:"name"`
+	Result          Result                 `json:"result"`
+	Checks          []Check                `json:"checks"`
+	VirtualMachines []VirtualMachineReport `json:"virtualMachines"`
+}
+
+// Findings returns a line for each check that did not pass, the plan checks first
+func (r *PlanReport) Findings() []string {
+	var findings []string
+	for _, check := range r.Checks {
+		if check.Result != ResultPass {
+			findings = append(findings, fmt.Sprintf("%s %s: %s", check.Result, check.Name, check.Message))
+		}
+	}
+	for _, vm := range r.VirtualMachines {
+		for _, check := range vm.Checks {
+			if check.Result != ResultPass {
+				findings = append(findings, fmt.Sprintf("%s %s %s/%s: %s", check.Result, check.Name, vm.Namespace, vm.Name, check.Message))
+			}
+		}
+	}
+	return findings
+}
+
+// planVM is a virtual machine entry of a plan
+type planVM struct {
+	namespace string
+	name      string
+	pvcs      []planPVC
+}
+
+// planPVC is a targetMigrationPVCs entry of a plan
+type planPVC struct {
+	volumeName   string
+	storageClass string
+	accessModes  []corev1.PersistentVolumeAccessMode
+}
+
+// Analyze evaluates the plan, a VirtualMachineStorageMigrationPlan or MultiNamespaceVirtualMachineStorageMigrationPlan,
+// against the cluster state. Errors are only returned when the cluster cannot be read
+func Analyze(ctx context.Context, c client.Reader, plan *unstructured.Unstructured) (*PlanReport, error) {
+	report := &PlanReport{
+		Kind:      plan.GetKind(),
+		Namespace: plan.GetNamespace(),
+		Name:      plan.GetName(),
+	}
+
+	featureGates, err := checkFeatureGates(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	report.Checks = append(report.Checks, featureGates)
+
+	storageClasses := &storagev1.StorageClassList{}
+	if err := c.List(ctx, storageClasses); err != nil {
+		return nil, fmt.Errorf("unable to list the storage classes; %w", err)
+	}
+	quotas := newQuotaTracker(c)
+
+	for _, vm := range getPlanVMs(plan) {
+		vmReport, err := analyzeVM(ctx, c, vm, storageClasses.Items, quotas)
+		if err != nil {
+			return nil, err
+		}
+		report.VirtualMachines = append(report.VirtualMachines, *vmReport)
+	}
+
+	report.Result = worst(report.Checks)
+	for _, vm := range report.VirtualMachines {
+		report.Result = worse(report.Result, vm.Result)
+	}
+	return report, nil
+}
+
+// CountVirtualMachines returns the number of virtual machines Analyze evaluates for the plan
+func CountVirtualMachines(plan *unstructured.Unstructured) int {
+	return len(getPlanVMs(plan))
+}
+
+func checkFeatureGates(ctx context.Context, c client.Reader) (Check, error) {
+	check := Check{Name: "FeatureGates", Result: ResultPass}
+	kubeVirts := &unstructured.UnstructuredList{}
+	kubeVirts.SetGroupVersionKind(kubeVirtListGVK)
+	if err := c.List(ctx, kubeVirts); err != nil {
+		if isNotInstalled(err) {
+			check.Result, check.Message = ResultFail, "KubeVirt is not installed"
+			return check, nil
+		}
+		return check, fmt.Errorf("unable to list the KubeVirt resources; %w", err)
+	}
+	if len(kubeVirts.Items) == 0 {
+		check.Result, check.Message = ResultFail, "KubeVirt is not installed"
+		return check, nil
+	}
+
+	kubeVirt := kubeVirts.Items[0]
+	gates, _, _ := unstructured.NestedStringSlice(kubeVirt.Object, "spec", "configuration", "developerConfiguration", "featureGates")
+	disabledGates, _, _ := unstructured.NestedStringSlice(kubeVirt.Object,
+		"spec", "configuration", "developerConfiguration", "disabledFeatureGates")
+	enabled := map[string]bool{}
+	for _, gate := range gates {
+		enabled[gate] = true
+	}
+	disabled := map[string]bool{}
+	for _, gate := range disabledGates {
+		disabled[gate] = true
+	}
+	// the deployed version decides which gates are on by default, none before KubeVirt is deployed
+	observed, _, _ := unstructured.NestedString(kubeVirt.Object, "status", "observedKubeVirtVersion")
+	observedVersion, _ := version.ParseGeneric(observed)
+
+	var missing []string
+	for gate, enabledSince := range RequiredFeatureGates {
+		if enabled[gate] {
+			continue
+		}
+		if !disabled[gate] && observedVersion != nil && observedVersion.AtLeast(version.MustParseGeneric(enabledSince)) {
+			continue
+		}
+		missing = append(missing, gate)
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		check.Result = ResultFail
+		check.Message = fmt.Sprintf("KubeVirt %s/%s does not enable the feature gates %s", kubeVirt.GetNamespace(),
+			kubeVirt.GetName(), strings.Join(missing, ", "))
+	}
+	return check, nil
+}
+
+func analyzeVM(ctx context.Context, c client.Reader, vm planVM, storageClasses []storagev1.StorageClass, quotas *quotaTracker) (*VirtualMachineReport, error) {
+	report := &VirtualMachineReport{Namespace: vm.namespace, Name: vm.name}
+	key := client.ObjectKey{Namespace: vm.namespace, Name: vm.name}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(vmGVK)
+	if err := c.Get(ctx, key, obj); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		report.Checks = append(report.Checks, Check{Name: "VirtualMachine", Result: ResultFail, Message: "the virtual machine does not exist"})
+		report.Result = ResultFail
+		return report, nil
+	}
+
+	liveMigratable, err := checkLiveMigratable(ctx, c, key)
+	if err != nil {
+		return nil, err
+	}
+	report.Checks = append(report.Checks, liveMigratable)
+
+	claims := getVolumeClaims(obj)
+	var sources []*corev1.PersistentVolumeClaim
+	var targets []planPVC
+	for _, pvc := range vm.pvcs {
+		claimName, ok := claims[pvc.volumeName]
+		if !ok {
+			report.Checks = append(report.Checks, Check{Name: "SourceVolume", Result: ResultFail,
+				Message: fmt.Sprintf("volume %s is not a PVC or DataVolume volume of the virtual machine", pvc.volumeName)})
+			continue
+		}
+		source := &corev1.PersistentVolumeClaim{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: vm.namespace, Name: claimName}, source); err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, err
+			}
+			report.Checks = append(report.Checks, Check{Name: "SourceVolume", Result: ResultFail,
+				Message: fmt.Sprintf("PVC %s of volume %s does not exist", claimName, pvc.volumeName)})
+			continue
+		}
+
+		storageClass, check := checkTargetStorageClass(pvc, storageClasses)
+		report.Checks = append(report.Checks, check, checkAccessModes(pvc, source))
+		pvc.storageClass = storageClass
+		sources = append(sources, source)
+		targets = append(targets, pvc)
+	}
+
+	quota, err := quotas.reserve(ctx, vm.namespace, sources, targets)
+	if err != nil {
+		return nil, err
+	}
+	report.Checks = append(report.Checks, quota)
+
+	report.Result = worst(report.Checks)
+	return report, nil
+}
+
+func checkLiveMigratable(ctx context.Context, c client.Reader, key client.ObjectKey) (Check, error) {
+	check := Check{Name: "LiveMigratable", Result: ResultPass}
+	vmi := &unstructured.Unstructured{}
+	vmi.SetGroupVersionKind(vmiGVK)
+	if err := c.Get(ctx, key, vmi); err != nil {
+		if !errors.IsNotFound(err) {
+			return check, err
+		}
+		check.Result, check.Message = ResultWarn, "the virtual machine is not running"
+		return check, nil
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(vmi.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != liveMigratableCondition {
+			continue
+		}
+		if condition["status"] != string(corev1.ConditionTrue) {
+			check.Result = ResultFail
+			check.Message = fmt.Sprintf("the virtual machine is not live migratable: %v", condition["message"])
+		}
+		return check, nil
+	}
+	check.Result, check.Message = ResultWarn, "the virtual machine does not report whether it is live migratable"
+	return check, nil
+}
+
+// checkTargetStorageClass returns the storage class the destination PVC gets, the default one when
+// the plan does not name one
+func checkTargetStorageClass(pvc planPVC, storageClasses []storagev1.StorageClass) (string, Check) {
+	check := Check{Name: "TargetStorageClass", Result: ResultPass}
+	if pvc.storageClass == "" {
+		for _, sc := range storageClasses {
+			if sc.Annotations[defaultStorageClassAnnotation] == "true" {
+				return sc.Name, check
+			}
+		}
+		check.Result = ResultFail
+		check.Message = fmt.Sprintf("volume %s has no target storage class and the cluster has no default storage class", pvc.volumeName)
+		return "", check
+	}
+
+	for _, sc := range storageClasses {
+		if sc.Name == pvc.storageClass {
+			return sc.Name, check
+		}
+	}
+	check.Result = ResultFail
+	check.Message = fmt.Sprintf("target storage class %s of volume %s does not exist", pvc.storageClass, pvc.volumeName)
+	return pvc.storageClass, check
+}
+
+// checkAccessModes fails on volumes that cannot be live migrated, a ReadWriteOncePod volume
+// cannot be attached to the source and target pods at once
+func checkAccessModes(pvc planPVC, source *corev1.PersistentVolumeClaim) Check {
+	check := Check{Name: "AccessModes", Result: ResultPass}
+	for _, mode := range append(append([]corev1.PersistentVolumeAccessMode{}, source.Spec.AccessModes...), pvc.accessModes...) {
+		if mode == corev1.ReadWriteOncePod {
+			check.Result = ResultFail
+			check.Message = fmt.Sprintf("volume %s uses the %s access mode, which does not support live migration",
+				pvc.volumeName, corev1.ReadWriteOncePod)
+			return check
+		}
+	}
+	return check
+}
+
+// getVolumeClaims maps the volumes of the virtual machine to their PVCs, DataVolumes own a PVC of the same name
+func getVolumeClaims(vm *unstructured.Unstructured) map[string]string {
+	volumes, _, _ := unstructured.NestedSlice(vm.Object, "spec", "template", "spec", "volumes")
+	claims := map[string]string{}
+	for _, v := range volumes {
+		volume, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(volume, "name")
+		if claim, _, _ := unstructured.NestedString(volume, "persistentVolumeClaim", "claimName"); claim != "" {
+			claims[name] = claim
+		} else if claim, _, _ := unstructured.NestedString(volume, "dataVolume", "name"); claim != "" {
+			claims[name] = claim
+		}
+	}
+	return claims
+}
+
+// getPlanVMs returns the virtual machines of a single or multi namespace plan, sorted by namespace and name
+func getPlanVMs(plan *unstructured.Unstructured) []planVM {
+	var vms []planVM
+	if namespaces, ok, _ := unstructured.NestedSlice(plan.Object, "spec", "namespaces"); ok {
+		for _, n := range namespaces {
+			ns, ok := n.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(ns, "name")
+			entries, _, _ := unstructured.NestedSlice(ns, "virtualMachines")
+			vms = append(vms, getVMs(name, entries)...)
+		}
+	} else {
+		entries, _, _ := unstructured.NestedSlice(plan.Object, "spec", "virtualMachines")
+		vms = getVMs(plan.GetNamespace(), entries)
+	}
+
+	sort.SliceStable(vms, func(i, j int) bool {
+		if vms[i].namespace != vms[j].namespace {
+			return vms[i].namespace < vms[j].namespace
+		}
+		return vms[i].name < vms[j].name
+	})
+	return vms
+}
+
+func getVMs(namespace string, entries []interface{}) []planVM {
+	var vms []planVM
+	for _, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		vm := planVM{namespace: namespace}
+		vm.name, _, _ = unstructured.NestedString(entry, "name")
+		pvcs, _, _ := unstructured.NestedSlice(entry, "targetMigrationPVCs")
+		for _, p := range pvcs {
+			pvc, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			target := planPVC{}
+			target.volumeName, _, _ = unstructured.NestedString(pvc, "volumeName")
+			target.storageClass, _, _ = unstructured.NestedString(pvc, "destinationPVC", "storageClassName")
+			modes, _, _ := unstructured.NestedStringSlice(pvc, "destinationPVC", "accessModes")
+			for _, mode := range modes {
+				target.accessModes = append(target.accessModes, corev1.PersistentVolumeAccessMode(mode))
+			}
+			vm.pvcs = append(vm.pvcs, target)
+		}
+		vms = append(vms, vm)
+	}
+	return vms
+}
+
+func isNotInstalled(err error) bool {
+	return meta.IsNoMatchError(err) || errors.IsNotFound(err)
+}
+
+var severity = map[Result]int{ResultPass: 0, ResultWarn: 1, ResultFail: 2}
+
+func worse(a, b Result) Result {
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
+
+func worst(checks []Check) Result {
+	result := ResultPass
+	for _, check := range checks {
+		result = worse(result, check.Result)
+	}
+	return result
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPreflight(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Preflight Suite")
+}
+
+func newKubeVirt(featureGates ...interface{}) *unstructured.Unstructured {
+	kv := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	kv.SetGroupVersionKind(kubeVirtListGVK.GroupVersion().WithKind("KubeVirt"))
+	kv.SetNamespace("kubevirt")
+	kv.SetName("kubevirt")
+	Expect(unstructured.SetNestedSlice(kv.Object, featureGates,
+		"spec", "configuration", "developerConfiguration", "featureGates")).To(Succeed())
+	return kv
+}
+
+func newDeployedKubeVirt(observedVersion string, disabledFeatureGates ...interface{}) *unstructured.Unstructured {
+	kv := newKubeVirt()
+	Expect(unstructured.SetNestedField(kv.Object, observedVersion, "status", "observedKubeVirtVersion")).To(Succeed())
+	Expect(unstructured.SetNestedSlice(kv.Object, disabledFeatureGates,
+		"spec", "configuration", "developerConfiguration", "disabledFeatureGates")).To(Succeed())
+	return kv
+}
+
+func newVM(namespace, name, claim string) *unstructured.Unstructured {
+	vm := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	vm.SetGroupVersionKind(vmGVK)
+	vm.SetNamespace(namespace)
+	vm.SetName(name)
+	volumes := []interface{}{
+		map[string]interface{}{"name": "root", "dataVolume": map[string]interface{}{"name": claim}},
+	}
+	Expect(unstructured.SetNestedSlice(vm.Object, volumes, "spec", "template", "spec", "volumes")).To(Succeed())
+	return vm
+}
+
+func newVMI(namespace, name string, liveMigratable corev1.ConditionStatus) *unstructured.Unstructured {
+	vmi := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	vmi.SetGroupVersionKind(vmiGVK)
+	vmi.SetNamespace(namespace)
+	vmi.SetName(name)
+	conditions := []interface{}{
+		map[string]interface{}{"type": liveMigratableCondition, "status": string(liveMigratable), "message": "disks are not shared"},
+	}
+	Expect(unstructured.SetNestedSlice(vmi.Object, conditions, "status", "conditions")).To(Succeed())
+	return vmi
+}
+
+func newPVC(namespace, name, size string, accessMode corev1.PersistentVolumeAccessMode) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{accessMode},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+}
+
+func newPlan(namespace string, vms ...string) *unstructured.Unstructured {
+	var entries []interface{}
+	for _, vm := range vms {
+		entries = append(entries, map[string]interface{}{
+			"name": vm,
+			"targetMigrationPVCs": []interface{}{
+				map[string]interface{}{
+					"volumeName":     "root",
+					"destinationPVC": map[string]interface{}{"storageClassName": "fast"},
+				},
+			},
+		})
+	}
+	plan := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	plan.SetAPIVersion("migrations.kubevirt.io/v1alpha1")
+	plan.SetKind("VirtualMachineStorageMigrationPlan")
+	plan.SetNamespace(namespace)
+	plan.SetName("plan")
+	Expect(unstructured.SetNestedSlice(plan.Object, entries, "spec", "virtualMachines")).To(Succeed())
+	return plan
+}
+
+func getVMReport(report *PlanReport, name string) VirtualMachineReport {
+	for _, vm := range report.VirtualMachines {
+		if vm.Name == name {
+			return vm
+		}
+	}
+	Fail("no report for virtual machine " + name)
+	return VirtualMachineReport{}
+}
+
+func getCheck(checks []Check, name string) Check {
+	for _, check := range checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	Fail("no check " + name)
+	return Check{}
+}
+
+var _ = Describe("Preflight", func() {
+	var (
+		objs []client.Object
+		ctx  = context.Background()
+	)
+
+	analyze := func(plan *unstructured.Unstructured) *PlanReport {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		for _, gvk := range []metav1.GroupVersionKind{
+			{Group: "kubevirt.io", Version: "v1", Kind: "KubeVirt"},
+			{Group: vmGVK.Group, Version: vmGVK.Version, Kind: vmGVK.Kind},
+			{Group: vmiGVK.Group, Version: vmiGVK.Version, Kind: vmiGVK.Kind},
+		} {
+			gv := kubeVirtListGVK.GroupVersion()
+			scheme.AddKnownTypeWithName(gv.WithKind(gvk.Kind), &unstructured.Unstructured{})
+			scheme.AddKnownTypeWithName(gv.WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+		report, err := Analyze(ctx, c, plan)
+		Expect(err).ToNot(HaveOccurred())
+		return report
+	}
+
+	BeforeEach(func() {
+		objs = []client.Object{
+			newKubeVirt("VolumesUpdateStrategy", "VolumeMigration"),
+			&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast"}},
+			newVM("ns1", "vm-a", "a-root"),
+			newVMI("ns1", "vm-a", corev1.ConditionTrue),
+			newPVC("ns1", "a-root", "10Gi", corev1.ReadWriteMany),
+			newVM("ns1", "vm-b", "b-root"),
+			newVMI("ns1", "vm-b", corev1.ConditionTrue),
+			newPVC("ns1", "b-root", "10Gi", corev1.ReadWriteMany),
+		}
+	})
+
+	It("should pass plans the cluster can run", func() {
+		report := analyze(newPlan("ns1", "vm-a", "vm-b"))
+		Expect(report.Result).To(Equal(ResultPass))
+		Expect(report.VirtualMachines).To(HaveLen(2))
+		Expect(report.Findings()).To(BeEmpty())
+	})
+
+	It("should fail plans when KubeVirt misses a feature gate", func() {
+		objs[0] = newKubeVirt("VolumesUpdateStrategy")
+		report := analyze(newPlan("ns1", "vm-a"))
+		Expect(report.Result).To(Equal(ResultFail))
+		Expect(getCheck(report.Checks, "FeatureGates").Message).To(ContainSubstring("VolumeMigration"))
+		Expect(getVMReport(report, "vm-a").Result).To(Equal(ResultPass))
+	})
+
+	DescribeTable("should account for the feature gates KubeVirt enables by default",
+		func(kubeVirt *unstructured.Unstructured, result Result, message string) {
+			objs[0] = kubeVirt
+			check := getCheck(analyze(newPlan("ns1", "vm-a")).Checks, "FeatureGates")
+			Expect(check.Result).To(Equal(result))
+			Expect(check.Message).To(Equal(message))
+		},
+		Entry("enabled by default", newDeployedKubeVirt("v1.5.0"), ResultPass, ""),
+		Entry("enabled by default in later versions", newDeployedKubeVirt("v1.6.2-rc.0"), ResultPass, ""),
+		Entry("not enabled by default yet", newDeployedKubeVirt("v1.4.1"), ResultFail,
+			"KubeVirt kubevirt/kubevirt does not enable the feature gates VolumeMigration, VolumesUpdateStrategy"),
+		Entry("disabled", newDeployedKubeVirt("v1.5.0", "VolumeMigration"), ResultFail,
+			"KubeVirt kubevirt/kubevirt does not enable the feature gates VolumeMigration"),
+		Entry("not deployed yet", newKubeVirt(), ResultFail,
+			"KubeVirt kubevirt/kubevirt does not enable the feature gates VolumeMigration, VolumesUpdateStrategy"),
+	)
+
+	It("should report each virtual machine on its own", func() {
+		objs = append(objs,
+			newVM("ns1", "vm-c", "c-root"),
+			newVMI("ns1", "vm-c", corev1.ConditionFalse),
+			newPVC("ns1", "c-root", "10Gi", corev1.ReadWriteOncePod),
+			newVM("ns1", "vm-d", "d-root"),
+			newPVC("ns1", "d-root", "10Gi", corev1.ReadWriteMany),
+		)
+		report := analyze(newPlan("ns1", "vm-a", "vm-c", "vm-d", "vm-e"))
+		Expect(report.Result).To(Equal(ResultFail))
+		Expect(getVMReport(report, "vm-a").Result).To(Equal(ResultPass))
+
+		vmc := getVMReport(report, "vm-c")
+		Expect(vmc.Result).To(Equal(ResultFail))
+		Expect(getCheck(vmc.Checks, "LiveMigratable").Message).To(ContainSubstring("disks are not shared"))
+		Expect(getCheck(vmc.Checks, "AccessModes").Result).To(Equal(ResultFail))
+
+		vmd := getVMReport(report, "vm-d")
+		Expect(vmd.Result).To(Equal(ResultWarn))
+		Expect(getCheck(vmd.Checks, "LiveMigratable").Message).To(Equal("the virtual machine is not running"))
+
+		Expect(getVMReport(report, "vm-e").Checks).To(ConsistOf(
+			Check{Name: "VirtualMachine", Result: ResultFail, Message: "the virtual machine does not exist"}))
+	})
+
+	It("should fail volumes without a target storage class", func() {
+		objs[1] = &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "slow"}}
+		report := analyze(newPlan("ns1", "vm-a"))
+		Expect(getCheck(getVMReport(report, "vm-a").Checks, "TargetStorageClass").Message).To(
+			Equal("target storage class fast of volume root does not exist"))
+	})
+
+	It("should fail the virtual machines exceeding the storage quota", func() {
+		objs = append(objs, &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "storage"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{"fast.storageclass.storage.k8s.io/requests.storage": resource.MustParse("25Gi")},
+				Used: corev1.ResourceList{"fast.storageclass.storage.k8s.io/requests.storage": resource.MustParse("10Gi")},
+			},
+		})
+		report := analyze(newPlan("ns1", "vm-a", "vm-b"))
+		Expect(getCheck(getVMReport(report, "vm-a").Checks, "ResourceQuota").Result).To(Equal(ResultPass))
+		Expect(getCheck(getVMReport(report, "vm-b").Checks, "ResourceQuota").Message).To(ContainSubstring(
+			"storage fast.storageclass.storage.k8s.io/requests.storage (requires 30Gi of 25Gi)"))
+	})
+})
+
+var _ = Describe("KubeVirt installation", func() {
+	DescribeTable("should tell missing KubeVirt APIs from other errors",
+		func(err error, notInstalled bool) {
+			Expect(isNotInstalled(err)).To(Equal(notInstalled))
+		},
+		Entry("no kind match", &meta.NoKindMatchError{GroupKind: kubeVirtListGVK.GroupKind()}, true),
+		Entry("not found", apierrors.NewNotFound(schema.GroupResource{Group: "kubevirt.io", Resource: "kubevirts"}, ""), true),
+		Entry("forbidden", apierrors.NewForbidden(schema.GroupResource{Group: "kubevirt.io", Resource: "kubevirts"}, "",
+			errors.New("no matches for kind KubeVirt")), false),
+	)
+})
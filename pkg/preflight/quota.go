@@ -0,0 +1,111 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const storageClassQuotaSuffix = ".storageclass.storage.k8s.io/"
+
+// quotaTracker accumulates the destination PVCs of the plan per namespace, so the quota check of
+// a virtual machine accounts for the virtual machines checked before it
+type quotaTracker struct {
+	client   client.Reader
+	quotas   map[string][]corev1.ResourceQuota
+	reserved map[string]corev1.ResourceList
+}
+
+func newQuotaTracker(c client.Reader) *quotaTracker {
+	return &quotaTracker{
+		client:   c,
+		quotas:   map[string][]corev1.ResourceQuota{},
+		reserved: map[string]corev1.ResourceList{},
+	}
+}
+
+// reserve adds the destination PVCs of a virtual machine to the namespace and checks the result against
+// the resource quotas. Destination PVCs request the size of their source PVC
+func (t *quotaTracker) reserve(ctx context.Context, namespace string, sources []*corev1.PersistentVolumeClaim, targets []planPVC) (Check, error) {
+	check := Check{Name: "ResourceQuota", Result: ResultPass}
+	quotas, ok := t.quotas[namespace]
+	if !ok {
+		list := &corev1.ResourceQuotaList{}
+		if err := t.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return check, fmt.Errorf("unable to list the resource quotas of namespace %s; %w", namespace, err)
+		}
+		quotas = list.Items
+		t.quotas[namespace] = quotas
+	}
+
+	reserved := t.reserved[namespace]
+	if reserved == nil {
+		reserved = corev1.ResourceList{}
+		t.reserved[namespace] = reserved
+	}
+	for i, source := range sources {
+		size := source.Spec.Resources.Requests[corev1.ResourceStorage]
+		add(reserved, corev1.ResourceRequestsStorage, size)
+		add(reserved, corev1.ResourcePersistentVolumeClaims, resource.MustParse("1"))
+		if sc := targets[i].storageClass; sc != "" {
+			add(reserved, corev1.ResourceName(sc+storageClassQuotaSuffix+string(corev1.ResourceRequestsStorage)), size)
+			add(reserved, corev1.ResourceName(sc+storageClassQuotaSuffix+string(corev1.ResourcePersistentVolumeClaims)), resource.MustParse("1"))
+		}
+	}
+
+	var exceeded []string
+	for _, quota := range quotas {
+		for name, hard := range quota.Status.Hard {
+			if !isStorageQuota(name) {
+				continue
+			}
+			want, ok := reserved[name]
+			if !ok {
+				continue
+			}
+			want = want.DeepCopy()
+			want.Add(quota.Status.Used[name])
+			if want.Cmp(hard) > 0 {
+				exceeded = append(exceeded, fmt.Sprintf("%s %s (requires %s of %s)", quota.Name, name, want.String(), hard.String()))
+			}
+		}
+	}
+	if len(exceeded) > 0 {
+		sort.Strings(exceeded)
+		check.Result = ResultFail
+		check.Message = "the destination PVCs exceed the resource quotas " + strings.Join(exceeded, ", ")
+	}
+	return check, nil
+}
+
+func isStorageQuota(name corev1.ResourceName) bool {
+	return name == corev1.ResourceRequestsStorage || name == corev1.ResourcePersistentVolumeClaims ||
+		strings.Contains(string(name), storageClassQuotaSuffix)
+}
+
+func add(list corev1.ResourceList, name corev1.ResourceName, quantity resource.Quantity) {
+	current := list[name]
+	current.Add(quantity)
+	list[name] = current
+}
@@ -0,0 +1,75 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+const (
+	scopeLabel     = "scope"
+	namespaceLabel = "namespace"
+
+	scopeCluster   = "cluster"
+	scopeNamespace = "namespace"
+)
+
+var (
+	runningMigrations = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kubevirt_migration_operator_running_storage_migrations",
+		Help: "The number of storage migrations running per namespace",
+	}, []string{namespaceLabel})
+	quotaLimits = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kubevirt_migration_operator_storage_migrations_quota",
+		Help: "The maximum number of storage migrations allowed to run at the same time, per namespace or in the cluster",
+	}, []string{scopeLabel})
+	quotaRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kubevirt_migration_operator_storage_migrations_quota_rejections_total",
+		Help: "The number of storage migrations rejected for exceeding the quota per namespace",
+	}, []string{namespaceLabel})
+)
+
+func init() {
+	metrics.Registry.MustRegister(runningMigrations, quotaLimits, quotaRejections)
+}
+
+// ReportUsage publishes the quota and its usage as metrics
+func ReportUsage(quota *v1alpha1.MigControllerQuota, usage Usage) {
+	runningMigrations.Reset()
+	for namespace, running := range usage.Namespaces {
+		runningMigrations.WithLabelValues(namespace).Set(float64(running))
+	}
+
+	quotaLimits.Reset()
+	if quota == nil {
+		return
+	}
+	if quota.MaxRunningPerNamespace != nil {
+		quotaLimits.WithLabelValues(scopeNamespace).Set(float64(*quota.MaxRunningPerNamespace))
+	}
+	if quota.MaxRunning != nil {
+		quotaLimits.WithLabelValues(scopeCluster).Set(float64(*quota.MaxRunning))
+	}
+}
+
+// ReportRejection counts a storage migration rejected for exceeding the quota
+func ReportRejection(namespace string) {
+	quotaRejections.WithLabelValues(namespace).Inc()
+}
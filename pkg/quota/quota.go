@@ -0,0 +1,89 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/migrationstatus"
+)
+
+// Usage holds the number of running storage migrations
+type Usage struct {
+	// Running is the number of storage migrations running in the cluster
+	Running int32
+	// Namespaces holds the number of running storage migrations per namespace
+	Namespaces map[string]int32
+}
+
+// IsRunning tells whether the storage migration counts against the quota, it does from its creation
+// until it reaches a terminal phase or is deleted. A multi namespace migration runs until all its
+// namespaces reached one
+func IsRunning(migration *unstructured.Unstructured) bool {
+	if migration.GetDeletionTimestamp() != nil {
+		return false
+	}
+	return !migrationstatus.IsFinished(migration)
+}
+
+// Count returns the usage of the running storage migrations among migrations, of both the single and the
+// multi namespace kinds. A multi namespace migration counts once, in the namespace it was created in
+func Count(migrations []unstructured.Unstructured) Usage {
+	usage := Usage{Namespaces: map[string]int32{}}
+	for i := range migrations {
+		if IsRunning(&migrations[i]) {
+			usage.Running++
+			usage.Namespaces[migrations[i].GetNamespace()]++
+		}
+	}
+	return usage
+}
+
+// Check returns why one more storage migration in namespace would exceed the quota, empty when it fits
+func Check(quota *v1alpha1.MigControllerQuota, usage Usage, namespace string) string {
+	if quota == nil {
+		return ""
+	}
+	if quota.MaxRunningPerNamespace != nil && usage.Namespaces[namespace] >= *quota.MaxRunningPerNamespace {
+		return fmt.Sprintf("namespace %s already runs %d storage migrations, the limit is %d per namespace",
+			namespace, usage.Namespaces[namespace], *quota.MaxRunningPerNamespace)
+	}
+	if quota.MaxRunning != nil && usage.Running >= *quota.MaxRunning {
+		return fmt.Sprintf("the cluster already runs %d storage migrations, the limit is %d",
+			usage.Running, *quota.MaxRunning)
+	}
+	return ""
+}
+
+// Status converts the usage for the MigController status, namespaces are sorted by name
+func (u Usage) Status() *v1alpha1.MigControllerQuotaUsage {
+	status := &v1alpha1.MigControllerQuotaUsage{Running: u.Running}
+	for namespace, running := range u.Namespaces {
+		status.Namespaces = append(status.Namespaces, v1alpha1.MigControllerNamespaceQuotaUsage{
+			Namespace: namespace,
+			Running:   running,
+		})
+	}
+	sort.Slice(status.Namespaces, func(i, j int) bool {
+		return status.Namespaces[i].Namespace < status.Namespaces[j].Namespace
+	})
+	return status
+}
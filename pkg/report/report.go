@@ -0,0 +1,441 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package report builds reports of the storage migrations run by plans, from the status of the plans
+// and their storage migrations
+package report
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	"kubevirt.io/kubevirt-migration-operator/pkg/migrationstatus"
+)
+
+// Outcome is the outcome of the migration of a virtual machine
+type Outcome string
+
+const (
+	// OutcomeCompleted means the volumes of the virtual machine were migrated
+	OutcomeCompleted Outcome = "Completed"
+	// OutcomeFailed means the migration of the virtual machine failed
+	OutcomeFailed Outcome = "Failed"
+	// OutcomeCancelled means the migration of the virtual machine was cancelled
+	OutcomeCancelled Outcome = "Cancelled"
+	// OutcomeInvalid means the plan entry of the virtual machine is invalid
+	OutcomeInvalid Outcome = "Invalid"
+	// OutcomeRunning means the virtual machine is migrating
+	OutcomeRunning Outcome = "Running"
+	// OutcomePending means the virtual machine was not migrated yet
+	OutcomePending Outcome = "Pending"
+)
+
+// planKinds are the plan kinds along with the kind of their storage migrations and the spec field
+// of the storage migrations referencing them
+var planKinds = []struct {
+	kind          string
+	migrationKind string
+	planRef       string
+}{
+	{"VirtualMachineStorageMigrationPlan", "VirtualMachineStorageMigration", "virtualMachineStorageMigrationPlanRef"},
+	{"MultiNamespaceVirtualMachineStorageMigrationPlan", "MultiNamespaceVirtualMachineStorageMigration",
+		"multiNamespaceVirtualMachineStorageMigrationPlanRef"},
+}
+
+// planStatusOutcomes maps the virtual machine lists of the plan status to the outcome they stand for
+var planStatusOutcomes = []struct {
+	field   string
+	outcome Outcome
+}{
+	{"completedMigrations", OutcomeCompleted},
+	{"failedMigrations", OutcomeFailed},
+	{"invalidMigrations", OutcomeInvalid},
+	{"inProgressMigrations", OutcomeRunning},
+	{"readyMigrations", OutcomePending},
+}
+
+// Options select the plans and storage migrations of a report
+type Options struct {
+	// Namespaces of the plans, all namespaces when empty
+	Namespaces []string
+	// Plan is the name of the only plan to report, all plans when empty
+	Plan string
+	// Since and Until restrict the report to the storage migrations created in between, when set.
+	// Plans without such storage migrations are left out
+	Since time.Time
+	Until time.Time
+}
+
+// MigrationReport holds the plans matching the options
+type MigrationReport struct {
+	GeneratedAt metav1.Time  `json:"generatedAt"`
+	Since       *metav1.Time `json:"since,omitempty"`
+	Until       *metav1.Time `json:"until,omitempty"`
+	Plans       []Plan       `json:"plans"`
+}
+
+// Plan holds the storage migrations of a plan and the outcome of each of its virtual machines
+type Plan struct {
+	Kind            string           `json:"kind"`
+	Namespace       string           `json:"namespace"`
+	Name            string           `json:"name"`
+	CreatedAt       metav1.Time      `json:"createdAt"`
+	Outcomes        map[Outcome]int  `json:"outcomes"`
+	Migrations      []Migration      `json:"migrations"`
+	VirtualMachines []VirtualMachine `json:"virtualMachines"`
+}
+
+// Finished tells whether the last storage migration of the plan is finished
+func (p *Plan) Finished() bool {
+	return len(p.Migrations) > 0 && p.Migrations[len(p.Migrations)-1].FinishedAt != nil
+}
+
+// Failed tells whether a virtual machine of the plan failed or is invalid, or the last storage migration failed
+func (p *Plan) Failed() bool {
+	if p.Outcomes[OutcomeFailed] > 0 || p.Outcomes[OutcomeInvalid] > 0 {
+		return true
+	}
+	return len(p.Migrations) > 0 && p.Migrations[len(p.Migrations)-1].Phase == string(OutcomeFailed)
+}
+
+// Migration is a storage migration run by a plan
+type Migration struct {
+	Name       string           `json:"name"`
+	Phase      string           `json:"phase,omitempty"`
+	StartedAt  metav1.Time      `json:"startedAt"`
+	FinishedAt *metav1.Time     `json:"finishedAt,omitempty"`
+	Duration   *metav1.Duration `json:"duration,omitempty"`
+	Completed  int              `json:"completed"`
+	Cancelled  int              `json:"cancelled"`
+	Errors     []string         `json:"errors,omitempty"`
+}
+
+// VirtualMachine is the outcome of a virtual machine of a plan. The times are the ones of the
+// storage migration that migrated it last
+type VirtualMachine struct {
+	Namespace  string           `json:"namespace"`
+	Name       string           `json:"name"`
+	Outcome    Outcome          `json:"outcome"`
+	Migration  string           `json:"migration,omitempty"`
+	Progress   string           `json:"progress,omitempty"`
+	StartedAt  *metav1.Time     `json:"startedAt,omitempty"`
+	FinishedAt *metav1.Time     `json:"finishedAt,omitempty"`
+	Duration   *metav1.Duration `json:"duration,omitempty"`
+	Volumes    []Volume         `json:"volumes"`
+	Errors     []string         `json:"errors,omitempty"`
+}
+
+// Volume is a migrated volume of a virtual machine
+type Volume struct {
+	Name               string `json:"name"`
+	SourcePVC          string `json:"sourcePVC,omitempty"`
+	SourceStorageClass string `json:"sourceStorageClass,omitempty"`
+	TargetPVC          string `json:"targetPVC,omitempty"`
+	TargetStorageClass string `json:"targetStorageClass,omitempty"`
+}
+
+// Build reads the plans and storage migrations selected by opts and reports them, plans sorted
+// by namespace and name
+func Build(ctx context.Context, c client.Reader, opts Options) (*MigrationReport, error) {
+	report := &MigrationReport{GeneratedAt: metav1.Now(), Plans: []Plan{}}
+	if !opts.Since.IsZero() {
+		report.Since = &metav1.Time{Time: opts.Since}
+	}
+	if !opts.Until.IsZero() {
+		report.Until = &metav1.Time{Time: opts.Until}
+	}
+
+	namespaces := opts.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	for _, kinds := range planKinds {
+		for _, namespace := range namespaces {
+			plans, err := list(ctx, c, kinds.kind, namespace)
+			if err != nil {
+				return nil, err
+			}
+			migrations, err := list(ctx, c, kinds.migrationKind, namespace)
+			if err != nil {
+				return nil, err
+			}
+			for i := range plans {
+				plan := &plans[i]
+				if opts.Plan != "" && plan.GetName() != opts.Plan {
+					continue
+				}
+				planMigrations := selectMigrations(plan, migrations, kinds.planRef, opts)
+				if len(planMigrations) == 0 && (!opts.Since.IsZero() || !opts.Until.IsZero()) {
+					continue
+				}
+				report.Plans = append(report.Plans, buildPlan(plan, planMigrations))
+			}
+		}
+	}
+
+	sort.SliceStable(report.Plans, func(i, j int) bool {
+		if report.Plans[i].Namespace != report.Plans[j].Namespace {
+			return report.Plans[i].Namespace < report.Plans[j].Namespace
+		}
+		return report.Plans[i].Name < report.Plans[j].Name
+	})
+	return report, nil
+}
+
+func list(ctx context.Context, c client.Reader, kind, namespace string) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind(kind + "List"))
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		// nothing to report before the migration CRDs are installed
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to list the %s resources; %w", kind, err)
+	}
+	return list.Items, nil
+}
+
+// selectMigrations returns the storage migrations of the plan created in the time range of opts, oldest first
+func selectMigrations(plan *unstructured.Unstructured, migrations []unstructured.Unstructured, planRef string,
+	opts Options) []*unstructured.Unstructured {
+	var selected []*unstructured.Unstructured
+	for i := range migrations {
+		migration := &migrations[i]
+		name, _, _ := unstructured.NestedString(migration.Object, "spec", planRef, "name")
+		if migration.GetNamespace() != plan.GetNamespace() || name != plan.GetName() {
+			continue
+		}
+		created := migration.GetCreationTimestamp().Time
+		if (!opts.Since.IsZero() && created.Before(opts.Since)) || (!opts.Until.IsZero() && !created.Before(opts.Until)) {
+			continue
+		}
+		selected = append(selected, migration)
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].GetCreationTimestamp().Time.Before(selected[j].GetCreationTimestamp().Time)
+	})
+	return selected
+}
+
+func ptrTime(t metav1.Time) *metav1.Time {
+	return &t
+}
+
+// scope is the part of a plan or storage migration about a single namespace, multi namespace plans
+// and storage migrations have one per namespace
+type scope struct {
+	namespace string
+	fields    map[string]interface{}
+}
+
+// getScopes returns the scopes of the spec or status of a plan or storage migration
+func getScopes(obj *unstructured.Unstructured, field string) []scope {
+	namespaces, found, _ := unstructured.NestedSlice(obj.Object, field, "namespaces")
+	if !found {
+		fields, _, _ := unstructured.NestedMap(obj.Object, field)
+		return []scope{{namespace: obj.GetNamespace(), fields: fields}}
+	}
+	var scopes []scope
+	for _, n := range namespaces {
+		if ns, ok := n.(map[string]interface{}); ok {
+			name, _, _ := unstructured.NestedString(ns, "name")
+			scopes = append(scopes, scope{namespace: name, fields: ns})
+		}
+	}
+	return scopes
+}
+
+func getScope(scopes []scope, namespace string) map[string]interface{} {
+	for _, s := range scopes {
+		if s.namespace == namespace {
+			return s.fields
+		}
+	}
+	return nil
+}
+
+func buildPlan(plan *unstructured.Unstructured, migrations []*unstructured.Unstructured) Plan {
+	report := Plan{
+		Kind:            plan.GetKind(),
+		Namespace:       plan.GetNamespace(),
+		Name:            plan.GetName(),
+		CreatedAt:       plan.GetCreationTimestamp(),
+		Outcomes:        map[Outcome]int{},
+		Migrations:      []Migration{},
+		VirtualMachines: []VirtualMachine{},
+	}
+	for _, migration := range migrations {
+		report.Migrations = append(report.Migrations, buildMigration(migration))
+	}
+
+	statusScopes := getScopes(plan, "status")
+	for _, spec := range getScopes(plan, "spec") {
+		status := getScope(statusScopes, spec.namespace)
+		vms, _, _ := unstructured.NestedSlice(spec.fields, "virtualMachines")
+		for _, v := range vms {
+			entry, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			vm := buildVirtualMachine(spec.namespace, entry, status, migrations, report.Migrations)
+			report.Outcomes[vm.Outcome]++
+			report.VirtualMachines = append(report.VirtualMachines, vm)
+		}
+	}
+	return report
+}
+
+func buildMigration(migration *unstructured.Unstructured) Migration {
+	report := Migration{Name: migration.GetName(), StartedAt: migration.GetCreationTimestamp()}
+	report.Phase, _, _ = unstructured.NestedString(migration.Object, "status", "phase")
+	for _, s := range getScopes(migration, "status") {
+		completed, _, _ := unstructured.NestedStringSlice(s.fields, "completedMigrations")
+		cancelled, _, _ := unstructured.NestedStringSlice(s.fields, "cancelledMigrations")
+		errors, _, _ := unstructured.NestedStringSlice(s.fields, "errors")
+		report.Completed += len(completed)
+		report.Cancelled += len(cancelled)
+		report.Errors = append(report.Errors, errors...)
+	}
+	if migrationstatus.IsFinished(migration) {
+		report.FinishedAt = ptrTime(metav1.NewTime(migrationstatus.FinishedAt(migration)))
+		report.Duration = &metav1.Duration{Duration: report.FinishedAt.Sub(report.StartedAt.Time)}
+	}
+	return report
+}
+
+// buildVirtualMachine reports a plan entry, its outcome comes from the last storage migration listing it,
+// or else from the plan status
+func buildVirtualMachine(namespace string, entry, planStatus map[string]interface{},
+	migrations []*unstructured.Unstructured, reports []Migration) VirtualMachine {
+	vm := VirtualMachine{Namespace: namespace, Outcome: OutcomePending, Volumes: []Volume{}}
+	vm.Name, _, _ = unstructured.NestedString(entry, "name")
+
+	var last *Migration
+	for i := len(migrations) - 1; i >= 0 && last == nil; i-- {
+		status := getScope(getScopes(migrations[i], "status"), namespace)
+		progress := getProgress(status, vm.Name)
+		switch {
+		case contains(status, "completedMigrations", vm.Name):
+			vm.Outcome = OutcomeCompleted
+		case contains(status, "cancelledMigrations", vm.Name):
+			vm.Outcome = OutcomeCancelled
+		case progress != nil:
+			vm.Outcome, vm.Progress = OutcomeRunning, *progress
+		default:
+			continue
+		}
+		last = &reports[i]
+	}
+
+	statusEntry, statusOutcome := findStatusEntry(planStatus, vm.Name)
+	if last == nil {
+		vm.Outcome = statusOutcome
+		if vm.Outcome != OutcomePending && len(reports) > 0 {
+			last = &reports[len(reports)-1]
+		}
+	}
+	if last != nil {
+		vm.Migration = last.Name
+		vm.StartedAt = ptrTime(last.StartedAt)
+		if vm.Outcome != OutcomeRunning {
+			vm.FinishedAt, vm.Duration = last.FinishedAt, last.Duration
+		}
+		for _, err := range last.Errors {
+			if strings.Contains(err, vm.Name) {
+				vm.Errors = append(vm.Errors, err)
+			}
+		}
+	}
+
+	vm.Volumes = getVolumes(entry, statusEntry)
+	return vm
+}
+
+// findStatusEntry returns the entry of the virtual machine in the plan status and the outcome of the list holding it
+func findStatusEntry(status map[string]interface{}, name string) (map[string]interface{}, Outcome) {
+	for _, list := range planStatusOutcomes {
+		entries, _, _ := unstructured.NestedSlice(status, list.field)
+		for _, e := range entries {
+			if entry, ok := e.(map[string]interface{}); ok && entry["name"] == name {
+				return entry, list.outcome
+			}
+		}
+	}
+	return nil, OutcomePending
+}
+
+// getVolumes returns the volumes of the plan entry, the plan status entry adds the source PVCs and
+// the names the destination PVCs got
+func getVolumes(entry, statusEntry map[string]interface{}) []Volume {
+	targets, _, _ := unstructured.NestedSlice(entry, "targetMigrationPVCs")
+	if statusTargets, found, _ := unstructured.NestedSlice(statusEntry, "targetMigrationPVCs"); found {
+		targets = statusTargets
+	}
+	sources, _, _ := unstructured.NestedSlice(statusEntry, "sourcePVCs")
+
+	volumes := []Volume{}
+	for _, t := range targets {
+		target, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		volume := Volume{}
+		volume.Name, _, _ = unstructured.NestedString(target, "volumeName")
+		volume.TargetPVC, _, _ = unstructured.NestedString(target, "destinationPVC", "name")
+		volume.TargetStorageClass, _, _ = unstructured.NestedString(target, "destinationPVC", "storageClassName")
+		for _, s := range sources {
+			source, ok := s.(map[string]interface{})
+			if !ok || source["volumeName"] != volume.Name {
+				continue
+			}
+			volume.SourcePVC, _, _ = unstructured.NestedString(source, "name")
+			volume.SourceStorageClass, _, _ = unstructured.NestedString(source, "sourcePVC", "spec", "storageClassName")
+		}
+		volumes = append(volumes, volume)
+	}
+	return volumes
+}
+
+func contains(status map[string]interface{}, field, name string) bool {
+	names, _, _ := unstructured.NestedStringSlice(status, field)
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getProgress returns the progress of the virtual machine when the storage migration status lists it as running
+func getProgress(status map[string]interface{}, name string) *string {
+	running, _, _ := unstructured.NestedSlice(status, "runningMigrations")
+	for _, r := range running {
+		if entry, ok := r.(map[string]interface{}); ok && entry["name"] == name {
+			progress, _, _ := unstructured.NestedString(entry, "progress")
+			return &progress
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationsv1alpha1 "kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+func TestReport(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Report Suite")
+}
+
+var start = time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+
+func newObject(kind, namespace, name string, created time.Time, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: fields}
+	obj.SetGroupVersionKind(migrationsv1alpha1.GroupVersion.WithKind(kind))
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetCreationTimestamp(metav1.NewTime(created))
+	return obj
+}
+
+func planEntry(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"targetMigrationPVCs": []interface{}{
+			map[string]interface{}{
+				"volumeName":     "root",
+				"destinationPVC": map[string]interface{}{"storageClassName": "fast"},
+			},
+		},
+	}
+}
+
+func statusEntry(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"sourcePVCs": []interface{}{
+			map[string]interface{}{
+				"volumeName": "root",
+				"name":       name + "-root",
+				"namespace":  "ns1",
+				"sourcePVC":  map[string]interface{}{"spec": map[string]interface{}{"storageClassName": "slow"}},
+			},
+		},
+		"targetMigrationPVCs": []interface{}{
+			map[string]interface{}{
+				"volumeName":     "root",
+				"destinationPVC": map[string]interface{}{"name": name + "-root-mig", "storageClassName": "fast"},
+			},
+		},
+	}
+}
+
+func newMigration(name string, created, finished time.Time, completed []interface{}) *unstructured.Unstructured {
+	return newObject("VirtualMachineStorageMigration", "ns1", name, created, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"virtualMachineStorageMigrationPlanRef": map[string]interface{}{"name": "evacuate"},
+		},
+		"status": map[string]interface{}{
+			"phase":               "Failed",
+			"completedMigrations": completed,
+			"errors":              []interface{}{"vm-b: volume migration failed"},
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Failed", "status": "True", "category": "Critical",
+					"lastTransitionTime": finished.Format(time.RFC3339)},
+			},
+		},
+	})
+}
+
+var _ = Describe("Report", func() {
+	var (
+		c   client.Client
+		ctx = context.Background()
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		for _, kinds := range planKinds {
+			for _, kind := range []string{kinds.kind, kinds.migrationKind} {
+				scheme.AddKnownTypeWithName(migrationsv1alpha1.GroupVersion.WithKind(kind), &unstructured.Unstructured{})
+				scheme.AddKnownTypeWithName(migrationsv1alpha1.GroupVersion.WithKind(kind+"List"), &unstructured.UnstructuredList{})
+			}
+		}
+
+		plan := newObject("VirtualMachineStorageMigrationPlan", "ns1", "evacuate", start, map[string]interface{}{
+			"spec": map[string]interface{}{
+				"virtualMachines": []interface{}{planEntry("vm-a"), planEntry("vm-b"), planEntry("vm-c")},
+			},
+			"status": map[string]interface{}{
+				"completedMigrations": []interface{}{statusEntry("vm-a")},
+				"failedMigrations":    []interface{}{statusEntry("vm-b")},
+			},
+		})
+		other := newObject("VirtualMachineStorageMigrationPlan", "ns2", "other", start, map[string]interface{}{
+			"spec": map[string]interface{}{"virtualMachines": []interface{}{planEntry("vm-d")}},
+		})
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(plan, other,
+			newMigration("evacuate-1", start.Add(time.Hour), start.Add(2*time.Hour), nil),
+			newMigration("evacuate-2", start.Add(24*time.Hour), start.Add(25*time.Hour+30*time.Minute), []interface{}{"vm-a"}),
+		).Build()
+	})
+
+	It("should report the outcome, volumes and times of each virtual machine", func() {
+		report, err := Build(ctx, c, Options{Namespaces: []string{"ns1"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Plans).To(HaveLen(1))
+
+		plan := report.Plans[0]
+		Expect(plan.Outcomes).To(Equal(map[Outcome]int{OutcomeCompleted: 1, OutcomeFailed: 1, OutcomePending: 1}))
+		Expect(plan.Migrations).To(HaveLen(2))
+		Expect(plan.Migrations[1].Duration.Duration).To(Equal(90 * time.Minute))
+
+		vmA := plan.VirtualMachines[0]
+		Expect(vmA.Outcome).To(Equal(OutcomeCompleted))
+		Expect(vmA.Migration).To(Equal("evacuate-2"))
+		Expect(vmA.Volumes).To(ConsistOf(Volume{Name: "root", SourcePVC: "vm-a-root", SourceStorageClass: "slow",
+			TargetPVC: "vm-a-root-mig", TargetStorageClass: "fast"}))
+
+		vmB := plan.VirtualMachines[1]
+		Expect(vmB.Outcome).To(Equal(OutcomeFailed))
+		Expect(vmB.Errors).To(ConsistOf("vm-b: volume migration failed"))
+
+		vmC := plan.VirtualMachines[2]
+		Expect(vmC.Outcome).To(Equal(OutcomePending))
+		Expect(vmC.Migration).To(BeEmpty())
+		Expect(vmC.Volumes).To(ConsistOf(Volume{Name: "root", TargetStorageClass: "fast"}))
+	})
+
+	It("should only report the migrations of the time range", func() {
+		report, err := Build(ctx, c, Options{Since: start, Until: start.Add(12 * time.Hour)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Plans).To(HaveLen(1))
+		Expect(report.Plans[0].Migrations).To(HaveLen(1))
+		Expect(report.Plans[0].VirtualMachines[0].Migration).To(Equal("evacuate-1"))
+	})
+
+	It("should report all plans of all namespaces", func() {
+		report, err := Build(ctx, c, Options{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Plans).To(HaveLen(2))
+		Expect(report.Plans[1].Name).To(Equal("other"))
+
+		report, err = Build(ctx, c, Options{Plan: "other"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Plans).To(HaveLen(1))
+	})
+
+	It("should report the progress of running virtual machines", func() {
+		report, err := Build(ctx, c, Options{Plan: "evacuate"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Plans[0].Finished()).To(BeTrue())
+		Expect(report.Plans[0].Failed()).To(BeTrue())
+
+		running := newObject("VirtualMachineStorageMigration", "ns1", "evacuate-3", start.Add(48*time.Hour), map[string]interface{}{
+			"spec": map[string]interface{}{
+				"virtualMachineStorageMigrationPlanRef": map[string]interface{}{"name": "evacuate"},
+			},
+			"status": map[string]interface{}{
+				"phase":             "Running",
+				"runningMigrations": []interface{}{map[string]interface{}{"name": "vm-c", "progress": "42%"}},
+			},
+		})
+		Expect(c.Create(ctx, running)).To(Succeed())
+
+		report, err = Build(ctx, c, Options{Plan: "evacuate"})
+		Expect(err).ToNot(HaveOccurred())
+		plan := report.Plans[0]
+		Expect(plan.Finished()).To(BeFalse())
+		Expect(plan.VirtualMachines[2].Outcome).To(Equal(OutcomeRunning))
+		Expect(plan.VirtualMachines[2].Progress).To(Equal("42%"))
+		Expect(plan.VirtualMachines[2].FinishedAt).To(BeNil())
+	})
+
+	It("should write a CSV row per volume", func() {
+		report, err := Build(ctx, c, Options{Namespaces: []string{"ns1"}})
+		Expect(err).ToNot(HaveOccurred())
+		out := &bytes.Buffer{}
+		Expect(Write(out, report, FormatCSV)).To(Succeed())
+
+		rows, err := csv.NewReader(out).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rows).To(HaveLen(4))
+		Expect(rows[0]).To(Equal(csvHeader))
+		Expect(rows[1]).To(Equal([]string{"ns1", "evacuate", "ns1", "vm-a", "Completed", "evacuate-2", "root",
+			"vm-a-root", "slow", "vm-a-root-mig", "fast", "2026-10-02T10:00:00Z", "2026-10-02T11:30:00Z", "5400", ""}))
+	})
+
+	It("should write a self-contained HTML page", func() {
+		report, err := Build(ctx, c, Options{Namespaces: []string{"ns1"}})
+		Expect(err).ToNot(HaveOccurred())
+		out := &bytes.Buffer{}
+		Expect(Write(out, report, FormatHTML)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("<h2>VirtualMachineStorageMigrationPlan ns1/evacuate</h2>"))
+		Expect(out.String()).To(ContainSubstring(`<td class="failed">Failed</td>`))
+		Expect(out.String()).To(ContainSubstring("<td>1h30m0s</td>"))
+		Expect(out.String()).ToNot(ContainSubstring("<script"))
+	})
+})
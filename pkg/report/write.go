@@ -0,0 +1,131 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Format is an output format of the reports
+type Format string
+
+const (
+	// FormatJSON writes the report as indented JSON
+	FormatJSON Format = "json"
+	// FormatCSV writes a row per volume of each virtual machine
+	FormatCSV Format = "csv"
+	// FormatHTML writes a self-contained HTML page
+	FormatHTML Format = "html"
+)
+
+// Formats are the supported output formats
+var Formats = []Format{FormatJSON, FormatCSV, FormatHTML}
+
+var csvHeader = []string{
+	"plan_namespace", "plan", "namespace", "virtual_machine", "outcome", "migration", "volume",
+	"source_pvc", "source_storage_class", "target_pvc", "target_storage_class",
+	"started_at", "finished_at", "duration_seconds", "errors",
+}
+
+//go:embed report.html
+var htmlTemplateText string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"time":     formatTime,
+	"duration": formatDuration,
+	"lower":    func(o Outcome) string { return strings.ToLower(string(o)) },
+	"join":     strings.Join,
+}).Parse(htmlTemplateText))
+
+// Write writes the report to w in format
+func Write(w io.Writer, report *MigrationReport, format Format) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, report)
+	case FormatCSV:
+		return writeCSV(w, report)
+	case FormatHTML:
+		return htmlTemplate.Execute(w, report)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+func writeJSON(w io.Writer, report *MigrationReport) error {
+	bytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", bytes)
+	return err
+}
+
+// writeCSV writes a row per volume, virtual machines without volumes get a single row without volume columns
+func writeCSV(w io.Writer, report *MigrationReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, plan := range report.Plans {
+		for _, vm := range plan.VirtualMachines {
+			volumes := vm.Volumes
+			if len(volumes) == 0 {
+				volumes = []Volume{{}}
+			}
+			for _, volume := range volumes {
+				duration := ""
+				if vm.Duration != nil {
+					duration = strconv.FormatInt(int64(vm.Duration.Seconds()), 10)
+				}
+				row := []string{
+					plan.Namespace, plan.Name, vm.Namespace, vm.Name, string(vm.Outcome), vm.Migration, volume.Name,
+					volume.SourcePVC, volume.SourceStorageClass, volume.TargetPVC, volume.TargetStorageClass,
+					formatTime(vm.StartedAt), formatTime(vm.FinishedAt), duration, strings.Join(vm.Errors, "; "),
+				}
+				if err := writer.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatTime(t *metav1.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatDuration(d *metav1.Duration) string {
+	if d == nil {
+		return ""
+	}
+	return d.Duration.Round(time.Second).String()
+}
@@ -27,12 +27,13 @@ import (
 
 	"kubevirt.io/kubevirt-migration-operator/pkg/common"
 	"kubevirt.io/kubevirt-migration-operator/pkg/resources"
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources/namespaced"
 	utils "kubevirt.io/kubevirt-migration-operator/pkg/resources/utils"
 )
 
 func createControllerResources(args *FactoryArgs) []client.Object {
 	return []client.Object{
-		createControllerClusterRole(),
+		createControllerClusterRole(args.WatchNamespaces),
 		createControllerClusterRoleBinding(args.Namespace),
 	}
 }
@@ -47,35 +48,15 @@ func createControllerClusterRoleBinding(namespace string) *rbacv1.ClusterRoleBin
 }
 
 func getControllerClusterPolicyRules() []rbacv1.PolicyRule {
+	return append(namespaced.GetControllerWatchNamespacePolicyRules(), getControllerClusterScopedPolicyRules()...)
+}
+
+// getControllerClusterScopedPolicyRules returns the rules the controller needs cluster-wide even when it is
+// restricted to a set of namespaces
+func getControllerClusterScopedPolicyRules() []rbacv1.PolicyRule {
 	// TODO: Figure out a way to read the RBAC rules from the controller config/rbac/role.yaml
 	// file in the kubevirt-migration-controller project.
 	return []rbacv1.PolicyRule{
-		{
-			APIGroups: []string{
-				"",
-			},
-			Resources: []string{
-				"events",
-			},
-			Verbs: []string{
-				"create",
-				"patch",
-			},
-		},
-		{
-			APIGroups: []string{
-				"",
-			},
-			Resources: []string{
-				"persistentvolumeclaims",
-			},
-			Verbs: []string{
-				"list",
-				"watch",
-				"update",
-				"delete",
-			},
-		},
 		{
 			APIGroups: []string{
 				"",
@@ -88,19 +69,6 @@ func getControllerClusterPolicyRules() []rbacv1.PolicyRule {
 				"watch",
 			},
 		},
-		{
-			APIGroups: []string{
-				"",
-			},
-			Resources: []string{
-				"pods",
-			},
-			Verbs: []string{
-				"list",
-				"watch",
-				"delete",
-			},
-		},
 		{
 			APIGroups: []string{
 				"kubevirt.io",
@@ -113,114 +81,6 @@ func getControllerClusterPolicyRules() []rbacv1.PolicyRule {
 				"watch",
 			},
 		},
-		{
-			APIGroups: []string{
-				"kubevirt.io",
-			},
-			Resources: []string{
-				"virtualmachineinstances",
-			},
-			Verbs: []string{
-				"list",
-				"watch",
-				"get",
-			},
-		},
-		{
-			APIGroups: []string{
-				"kubevirt.io",
-			},
-			Resources: []string{
-				"virtualmachines",
-			},
-			Verbs: []string{
-				"list",
-				"watch",
-				"get",
-				"patch",
-			},
-		},
-		{
-			APIGroups: []string{
-				"kubevirt.io",
-			},
-			Resources: []string{
-				"virtualmachineinstancemigrations",
-			},
-			Verbs: []string{
-				"list",
-				"watch",
-				"get",
-				"delete",
-			},
-		},
-		{
-			APIGroups: []string{
-				"cdi.kubevirt.io",
-			},
-			Resources: []string{
-				"datavolumes",
-			},
-			Verbs: []string{
-				"list",
-				"watch",
-				"get",
-				"create",
-				"update",
-				"patch",
-				"delete",
-			},
-		},
-		{
-			APIGroups: []string{
-				"migrations.kubevirt.io",
-			},
-			Resources: []string{
-				"virtualmachinestoragemigrations",
-				"virtualmachinestoragemigrationplans",
-				"multinamespacevirtualmachinestoragemigrations",
-				"multinamespacevirtualmachinestoragemigrationplans",
-			},
-			Verbs: []string{
-				"list",
-				"watch",
-				"get",
-				"patch",
-				"update",
-				"create",
-				"delete",
-			},
-		},
-		{
-			APIGroups: []string{
-				"migrations.kubevirt.io",
-			},
-			Resources: []string{
-				"virtualmachinestoragemigrations/status",
-				"virtualmachinestoragemigrationplans/status",
-				"multinamespacevirtualmachinestoragemigrations/status",
-				"multinamespacevirtualmachinestoragemigrationplans/status",
-			},
-			Verbs: []string{
-				"get",
-				"patch",
-				"update",
-			},
-		},
-		{
-			APIGroups: []string{
-				"migrations.kubevirt.io",
-			},
-			Resources: []string{
-				"virtualmachinestoragemigrations/finalizers",
-				"virtualmachinestoragemigrationplans/finalizers",
-				"multinamespacevirtualmachinestoragemigrations/finalizers",
-				"multinamespacevirtualmachinestoragemigrationplans/finalizers",
-			},
-			Verbs: []string{
-				"update",
-			},
-		},
 		{
 			APIGroups: []string{
 				"migrations.kubevirt.io",
@@ -275,7 +135,11 @@ func getControllerClusterPolicyRules() []rbacv1.PolicyRule {
 	}
 }
 
-func createControllerClusterRole() *rbacv1.ClusterRole {
+func createControllerClusterRole(watchNamespaces []string) *rbacv1.ClusterRole {
+	if watchNamespaces != nil {
+		// the rights in the watched namespaces are granted by the namespaced controller resources
+		return utils.ResourceBuilder.CreateClusterRole(common.ControllerResourceName, getControllerClusterScopedPolicyRules())
+	}
 	return utils.ResourceBuilder.CreateClusterRole(common.ControllerResourceName, getControllerClusterPolicyRules())
 }
 
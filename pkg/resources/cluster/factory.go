@@ -27,6 +27,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	utils "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/resources"
+
+	"kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
 )
 
 // FactoryArgs contains the required parameters to generate all cluster-scoped resources
@@ -34,6 +36,18 @@ type FactoryArgs struct {
 	Namespace string
 	Client    client.Client
 	Logger    logr.Logger
+	// WatchNamespaces restricts the controller to the listed namespaces, nil means all namespaces and empty none
+	WatchNamespaces []string
+	// OLMManagedCRDs leaves the storage migration CRDs to OLM, which installs them from the bundle
+	OLMManagedCRDs bool
+	// AggregateToNamespaceRoles lets namespace admins and editors create single namespace storage migrations
+	AggregateToNamespaceRoles bool
+	// StorageMigrators are bound to the single namespace storage migration role
+	StorageMigrators []rbacv1.Subject
+	// MultiNamespaceStorageMigrators are bound to the multi namespace storage migration role
+	MultiNamespaceStorageMigrators []rbacv1.Subject
+	// PlanPolicies are rendered as ValidatingAdmissionPolicies
+	PlanPolicies *v1alpha1.MigControllerPlanPolicies
 }
 
 type factoryFunc func(*FactoryArgs) []client.Object
@@ -41,19 +55,25 @@ type factoryFunc func(*FactoryArgs) []client.Object
 type factoryFuncMap map[string]factoryFunc
 
 var staticFactoryFunctions = factoryFuncMap{
-	"controller-rbac":      createControllerResources,
-	"crd-resources":        createCRDResources,
-	"aggregate-roles":      createAggregateClusterRoles,
-	"storagemigrate-roles": createStorageMigrationClusterRoles,
+	"controller-rbac":         createControllerResources,
+	"crd-resources":           createCRDResources,
+	"aggregate-roles":         createAggregateClusterRoles,
+	"storagemigrate-roles":    createStorageMigrationClusterRoles,
+	"storagemigrate-bindings": createStorageMigrationClusterRoleBindings,
 }
 
 func createCRDResources(args *FactoryArgs) []client.Object {
-	return []client.Object{
-		createVirtualMachineStorageMigrationCRD(),
-		createVirtualMachineStorageMigrationPlanCRD(),
-		createMultinamespaceVirtualMachineStorageMigrationCRD(),
-		createMultinamespaceVirtualMachineStorageMigrationPlanCRD(),
+	var resources []client.Object
+	if !args.OLMManagedCRDs {
+		resources = append(resources,
+			createVirtualMachineStorageMigrationCRD(),
+			createVirtualMachineStorageMigrationPlanCRD(),
+			createMultinamespaceVirtualMachineStorageMigrationCRD(),
+			createMultinamespaceVirtualMachineStorageMigrationPlanCRD(),
+		)
 	}
+	// the policies constrain objects of the CRDs above
+	return append(resources, createPlanPolicies(args)...)
 }
 
 // CreateAllStaticResources creates all static cluster-wide resources
@@ -0,0 +1,323 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+	utils "kubevirt.io/kubevirt-migration-operator/pkg/resources/utils"
+)
+
+const (
+	planPolicyPrefix = "kubevirt-migration-"
+
+	// DeleteSourcePolicyName is the policy enforcing spec.planPolicies.restrictDeleteSource
+	DeleteSourcePolicyName = planPolicyPrefix + "restrict-delete-source"
+	// MaxVirtualMachinesPolicyName is the policy enforcing spec.planPolicies.maxVirtualMachinesPerPlan
+	MaxVirtualMachinesPolicyName = planPolicyPrefix + "max-vms-per-plan"
+	// StorageClassPolicyPrefix prefixes the names of the policies enforcing spec.planPolicies.storageClasses
+	StorageClassPolicyPrefix = planPolicyPrefix + "storageclass-"
+	// MultiNamespacePolicySuffix suffixes the names of the policies enforcing a namespace dependent plan policy
+	// on multi namespace plans
+	MultiNamespacePolicySuffix = "-multins"
+
+	planResource               = "virtualmachinestoragemigrationplans"
+	multiNamespacePlanResource = "multinamespacevirtualmachinestoragemigrationplans"
+)
+
+// createPlanPolicies renders the MigController plan policies as ValidatingAdmissionPolicies,
+// every policy is bound under the same name
+func createPlanPolicies(args *FactoryArgs) []client.Object {
+	policies := args.PlanPolicies
+	if policies == nil {
+		return nil
+	}
+
+	var objs []client.Object
+	for _, storageClasses := range policies.StorageClasses {
+		objs = append(objs, createStorageClassPolicy(storageClasses)...)
+	}
+	if policies.RestrictDeleteSource {
+		objs = append(objs, createDeleteSourcePolicy()...)
+	}
+	if policies.MaxVirtualMachinesPerPlan != nil {
+		objs = append(objs, createMaxVirtualMachinesPolicy(*policies.MaxVirtualMachinesPerPlan)...)
+	}
+	return objs
+}
+
+func createStorageClassPolicy(policy v1alpha1.MigControllerStorageClassPolicy) []client.Object {
+	var validations namespacePlanValidations
+	if len(policy.Allowed) > 0 {
+		allowed := celStringList(policy.Allowed)
+		validations.add(
+			allTargetStorageClasses(func(sc string) string { return "!has(" + sc + ") || " + sc + " in " + allowed }),
+			fmt.Sprintf("plans migrating %%s may only target the storage classes %s", strings.Join(policy.Allowed, ", ")),
+		)
+	}
+	if len(policy.Denied) > 0 {
+		denied := celStringList(policy.Denied)
+		validations.add(
+			allTargetStorageClasses(func(sc string) string { return "!has(" + sc + ") || !(" + sc + " in " + denied + ")" }),
+			fmt.Sprintf("plans migrating %%s may not target the storage classes %s", strings.Join(policy.Denied, ", ")),
+		)
+	}
+	if len(validations.single) == 0 {
+		// nothing to enforce, a policy needs at least one validation
+		return nil
+	}
+	// an omitted storage class falls back to the default one, which is not known at admission
+	validations.add(
+		allTargetStorageClasses(func(sc string) string { return "has(" + sc + ")" }),
+		"plans migrating %s must name the storage class of every destination PVC",
+	)
+
+	return createNamespacePlanPolicies(StorageClassPolicyPrefix+policy.Name, validations, policy.NamespaceSelector)
+}
+
+func createDeleteSourcePolicy() []client.Object {
+	notDeleteSource := func(obj string) string {
+		return fmt.Sprintf("(!has(%[1]s.retentionPolicy) || %[1]s.retentionPolicy != 'deleteSource')", obj)
+	}
+	var validations namespacePlanValidations
+	validations.add(
+		namespacePlanExpressions{
+			single: notDeleteSource("object.spec"),
+			multi:  notDeleteSource("object.spec") + " && " + notDeleteSource("ns"),
+		},
+		fmt.Sprintf("the deleteSource retention policy requires %%s to be labeled %s=true", v1alpha1.AllowDeleteSourceLabel),
+	)
+
+	// namespaces that opted in are not selected
+	return createNamespacePlanPolicies(DeleteSourcePolicyName, validations, &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      v1alpha1.AllowDeleteSourceLabel,
+				Operator: metav1.LabelSelectorOpNotIn,
+				Values:   []string{"true"},
+			},
+		},
+	})
+}
+
+func createMaxVirtualMachinesPolicy(maxVMs int32) []client.Object {
+	validations := createPlanValidations(
+		planExpressions{
+			single: fmt.Sprintf("!has(object.spec.virtualMachines) || size(object.spec.virtualMachines) <= %d", maxVMs),
+			multi: fmt.Sprintf("!has(object.spec.namespaces) || "+
+				"object.spec.namespaces.map(ns, has(ns.virtualMachines) ? size(ns.virtualMachines) : 0).sum() <= %d", maxVMs),
+		},
+		fmt.Sprintf("a plan may migrate at most %d virtual machines", maxVMs),
+	)
+
+	return []client.Object{
+		createPlanPolicy(MaxVirtualMachinesPolicyName, []string{planResource, multiNamespacePlanResource}, validations),
+		createPlanPolicyBinding(MaxVirtualMachinesPolicyName, nil),
+	}
+}
+
+// planExpressions holds a CEL expression for each plan kind, the plans list their virtual machines differently
+type planExpressions struct {
+	single string
+	multi  string
+}
+
+// namespacePlanExpressions hold the CEL expressions of a policy depending on the namespace migrated in. The single
+// namespace plan expression applies to the plan, the multi namespace plan one to each of its namespace entries ns
+type namespacePlanExpressions planExpressions
+
+// namespacePlanValidations are the validations of the policies for each plan kind. The multi namespace plan
+// validations are rendered once it is known whether the policy selects namespaces
+type namespacePlanValidations struct {
+	single []admissionregistrationv1.Validation
+	multi  []namespacePlanValidation
+}
+
+// namespacePlanValidation is the multi namespace plan expression checking a namespace entry ns, along with the
+// message format getting the namespaces failing it
+type namespacePlanValidation struct {
+	expression    string
+	messageFormat string
+}
+
+// add appends the validations of the expressions, the message format gets the namespace the plan migrates in
+func (v *namespacePlanValidations) add(expressions namespacePlanExpressions, messageFormat string) {
+	v.single = append(v.single, admissionregistrationv1.Validation{
+		Expression: expressions.single,
+		Message:    fmt.Sprintf(messageFormat, "this namespace"),
+	})
+	v.multi = append(v.multi, namespacePlanValidation{expression: expressions.multi, messageFormat: messageFormat})
+}
+
+// selectedNamespaceValidations renders the multi namespace plan validations of a policy evaluated once per
+// selected namespace passed as params, only the entries of that namespace are checked
+func (v *namespacePlanValidations) selectedNamespaceValidations() []admissionregistrationv1.Validation {
+	var validations []admissionregistrationv1.Validation
+	for _, validation := range v.multi {
+		before, after, _ := strings.Cut(validation.messageFormat, "%s")
+		validations = append(validations, admissionregistrationv1.Validation{
+			Expression: "!has(object.spec.namespaces) || object.spec.namespaces.all(ns, " +
+				"ns.name != params.metadata.name || " + validation.expression + ")",
+			MessageExpression: strconv.Quote(before+"namespace ") + " + params.metadata.name + " + strconv.Quote(after),
+		})
+	}
+	return validations
+}
+
+// allNamespaceValidations renders the multi namespace plan validations of a policy applying to every namespace,
+// all entries are checked without reading the namespaces
+func (v *namespacePlanValidations) allNamespaceValidations() []admissionregistrationv1.Validation {
+	var validations []admissionregistrationv1.Validation
+	for _, validation := range v.multi {
+		before, after, _ := strings.Cut(validation.messageFormat, "%s")
+		validations = append(validations, admissionregistrationv1.Validation{
+			Expression: "!has(object.spec.namespaces) || object.spec.namespaces.all(ns, " + validation.expression + ")",
+			MessageExpression: strconv.Quote(before+"namespaces ") + " + object.spec.namespaces.filter(ns, !(" +
+				validation.expression + ")).map(ns, ns.name).join(\", \") + " + strconv.Quote(after),
+		})
+	}
+	return validations
+}
+
+// allTargetStorageClasses renders expressions checking cond for the storage class field of every destination PVC
+// of a plan, cond has to handle PVCs omitting the field
+func allTargetStorageClasses(cond func(sc string) string) namespacePlanExpressions {
+	vms := func(list string) string {
+		return fmt.Sprintf("%s.all(vm, !has(vm.targetMigrationPVCs) || vm.targetMigrationPVCs.all(pvc, %s))",
+			list, cond("pvc.destinationPVC.storageClassName"))
+	}
+	return namespacePlanExpressions{
+		single: "!has(object.spec.virtualMachines) || " + vms("object.spec.virtualMachines"),
+		multi:  "!has(ns.virtualMachines) || " + vms("ns.virtualMachines"),
+	}
+}
+
+// createPlanValidations guards the expressions so each only applies to its plan kind
+func createPlanValidations(expressions planExpressions, message string) []admissionregistrationv1.Validation {
+	return []admissionregistrationv1.Validation{
+		{
+			Expression: fmt.Sprintf("request.resource.resource != '%s' || %s", planResource, expressions.single),
+			Message:    message,
+		},
+		{
+			Expression: fmt.Sprintf("request.resource.resource != '%s' || %s", multiNamespacePlanResource, expressions.multi),
+			Message:    message,
+		},
+	}
+}
+
+// createNamespacePlanPolicies renders a policy applying to the plans migrating in the selected namespaces. Single
+// namespace plans are matched by their namespace. Multi namespace plans live in any namespace and migrate in the
+// ones they list, so their policy gets each selected namespace as params and checks the entries of that namespace.
+// Without a namespace selector no namespace is read, the multi namespace plan policy checks all entries
+func createNamespacePlanPolicies(name string, validations namespacePlanValidations, namespaceSelector *metav1.LabelSelector) []client.Object {
+	multiName := name + MultiNamespacePolicySuffix
+	multiBinding := createPlanPolicyBinding(multiName, nil)
+	var multiPolicy *admissionregistrationv1.ValidatingAdmissionPolicy
+	if namespaceSelector == nil {
+		multiPolicy = createPlanPolicy(multiName, []string{multiNamespacePlanResource}, validations.allNamespaceValidations())
+	} else {
+		multiPolicy = createPlanPolicy(multiName, []string{multiNamespacePlanResource}, validations.selectedNamespaceValidations())
+		multiPolicy.Spec.ParamKind = &admissionregistrationv1.ParamKind{APIVersion: "v1", Kind: "Namespace"}
+		multiBinding.Spec.ParamRef = &admissionregistrationv1.ParamRef{
+			Selector: namespaceSelector.DeepCopy(),
+			// no namespace selected, nothing to enforce
+			ParameterNotFoundAction: ptr.To(admissionregistrationv1.AllowAction),
+		}
+	}
+
+	return []client.Object{
+		createPlanPolicy(name, []string{planResource}, validations.single),
+		createPlanPolicyBinding(name, namespaceSelector),
+		multiPolicy,
+		multiBinding,
+	}
+}
+
+// createPlanPolicy renders a policy checking the plans on creation only. Plans created before the policy, or
+// breaking a tightened one, are left alone so the controller keeps updating and finalizing them
+func createPlanPolicy(name string, resources []string, validations []admissionregistrationv1.Validation) *admissionregistrationv1.ValidatingAdmissionPolicy {
+	return &admissionregistrationv1.ValidatingAdmissionPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+			Kind:       "ValidatingAdmissionPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: utils.ResourceBuilder.WithCommonLabels(nil),
+		},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicySpec{
+			FailurePolicy: ptr.To(admissionregistrationv1.Fail),
+			MatchConstraints: &admissionregistrationv1.MatchResources{
+				ResourceRules: []admissionregistrationv1.NamedRuleWithOperations{
+					{
+						RuleWithOperations: admissionregistrationv1.RuleWithOperations{
+							Operations: []admissionregistrationv1.OperationType{
+								admissionregistrationv1.Create,
+							},
+							Rule: admissionregistrationv1.Rule{
+								APIGroups:   []string{v1alpha1.GroupVersion.Group},
+								APIVersions: []string{v1alpha1.GroupVersion.Version},
+								Resources:   resources,
+							},
+						},
+					},
+				},
+			},
+			Validations: validations,
+		},
+	}
+}
+
+func createPlanPolicyBinding(name string, namespaceSelector *metav1.LabelSelector) *admissionregistrationv1.ValidatingAdmissionPolicyBinding {
+	binding := &admissionregistrationv1.ValidatingAdmissionPolicyBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+			Kind:       "ValidatingAdmissionPolicyBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: utils.ResourceBuilder.WithCommonLabels(nil),
+		},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicyBindingSpec{
+			PolicyName:        name,
+			ValidationActions: []admissionregistrationv1.ValidationAction{admissionregistrationv1.Deny},
+		},
+	}
+	if namespaceSelector != nil {
+		binding.Spec.MatchResources = &admissionregistrationv1.MatchResources{
+			NamespaceSelector: namespaceSelector.DeepCopy(),
+		}
+	}
+	return binding
+}
+
+func celStringList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
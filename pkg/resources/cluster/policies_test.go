@@ -0,0 +1,333 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/google/cel-go/cel"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apiserver/pkg/cel/environment"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+// evaluatePolicy runs the validations of the policy the way the API server does for a request creating the object,
+// it returns the message of the first failing validation or an empty string when the object is admitted
+func evaluatePolicy(policy *admissionregistrationv1.ValidatingAdmissionPolicy, resource string, object, params map[string]interface{}) string {
+	envSet, err := environment.MustBaseEnvSet(environment.DefaultCompatibilityVersion(), true).Extend(environment.VersionedOptions{
+		IntroducedVersion: version.MajorMinor(1, 0),
+		EnvOptions: []cel.EnvOption{
+			cel.Variable("object", cel.DynType),
+			cel.Variable("request", cel.DynType),
+			cel.Variable("params", cel.DynType),
+		},
+	})
+	Expect(err).ToNot(HaveOccurred())
+	env := envSet.StoredExpressionsEnv()
+	activation := map[string]interface{}{
+		"object":  object,
+		"request": map[string]interface{}{"resource": map[string]interface{}{"resource": resource}},
+		"params":  params,
+	}
+	eval := func(expression string) interface{} {
+		ast, issues := env.Compile(expression)
+		Expect(issues.Err()).ToNot(HaveOccurred(), expression)
+		program, err := env.Program(ast)
+		Expect(err).ToNot(HaveOccurred())
+		result, _, err := program.Eval(activation)
+		Expect(err).ToNot(HaveOccurred(), expression)
+		return result.Value()
+	}
+
+	for _, validation := range policy.Spec.Validations {
+		if eval(validation.Expression) == true {
+			continue
+		}
+		if validation.MessageExpression != "" {
+			return eval(validation.MessageExpression).(string)
+		}
+		return validation.Message
+	}
+	return ""
+}
+
+func namespaceParams(name string) map[string]interface{} {
+	return map[string]interface{}{"metadata": map[string]interface{}{"name": name}}
+}
+
+// newPlanVM creates a plan entry targeting the given storage class, an empty one is omitted
+func newPlanVM(storageClass string) interface{} {
+	destinationPVC := map[string]interface{}{}
+	if storageClass != "" {
+		destinationPVC["storageClassName"] = storageClass
+	}
+	return map[string]interface{}{
+		"name": "vm",
+		"targetMigrationPVCs": []interface{}{
+			map[string]interface{}{
+				"volumeName":     "disk",
+				"destinationPVC": destinationPVC,
+			},
+		},
+	}
+}
+
+func newSinglePlan(retentionPolicy string, storageClasses ...string) map[string]interface{} {
+	var vms []interface{}
+	for _, storageClass := range storageClasses {
+		vms = append(vms, newPlanVM(storageClass))
+	}
+	spec := map[string]interface{}{"virtualMachines": vms}
+	if retentionPolicy != "" {
+		spec["retentionPolicy"] = retentionPolicy
+	}
+	return map[string]interface{}{"spec": spec}
+}
+
+// newMultiPlan creates a multi namespace plan with a virtual machine per namespace targeting the given storage class
+func newMultiPlan(retentionPolicy string, namespaces map[string]string) map[string]interface{} {
+	var entries []interface{}
+	for namespace, storageClass := range namespaces {
+		entries = append(entries, map[string]interface{}{
+			"name":            namespace,
+			"virtualMachines": []interface{}{newPlanVM(storageClass)},
+		})
+	}
+	spec := map[string]interface{}{"namespaces": entries}
+	if retentionPolicy != "" {
+		spec["retentionPolicy"] = retentionPolicy
+	}
+	return map[string]interface{}{"spec": spec}
+}
+
+func getPolicy(objs []client.Object, name string) *admissionregistrationv1.ValidatingAdmissionPolicy {
+	for _, obj := range objs {
+		if policy, ok := obj.(*admissionregistrationv1.ValidatingAdmissionPolicy); ok && policy.Name == name {
+			return policy
+		}
+	}
+	return nil
+}
+
+func getPolicyBinding(objs []client.Object, name string) *admissionregistrationv1.ValidatingAdmissionPolicyBinding {
+	for _, obj := range objs {
+		if binding, ok := obj.(*admissionregistrationv1.ValidatingAdmissionPolicyBinding); ok && binding.Name == name {
+			return binding
+		}
+	}
+	return nil
+}
+
+var _ = Describe("Plan policies", func() {
+	restricted := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "restricted"}}
+
+	It("should not render anything without plan policies", func() {
+		Expect(createPlanPolicies(&FactoryArgs{})).To(BeEmpty())
+		Expect(createPlanPolicies(&FactoryArgs{PlanPolicies: &v1alpha1.MigControllerPlanPolicies{
+			StorageClasses: []v1alpha1.MigControllerStorageClassPolicy{{Name: "empty"}},
+		}})).To(BeEmpty())
+	})
+
+	Context("restricting storage classes", func() {
+		var objs []client.Object
+
+		BeforeEach(func() {
+			objs = createPlanPolicies(&FactoryArgs{PlanPolicies: &v1alpha1.MigControllerPlanPolicies{
+				StorageClasses: []v1alpha1.MigControllerStorageClassPolicy{
+					{Name: "gold", NamespaceSelector: restricted, Allowed: []string{"gold"}, Denied: []string{"silver"}},
+				},
+			}})
+		})
+
+		It("should bind the single namespace plan policy to the selected namespaces", func() {
+			Expect(objs).To(HaveLen(4))
+			policy := getPolicy(objs, StorageClassPolicyPrefix+"gold")
+			Expect(policy.Spec.ParamKind).To(BeNil())
+			Expect(policy.Spec.MatchConstraints.ResourceRules[0].Resources).To(ConsistOf(planResource))
+			binding := getPolicyBinding(objs, StorageClassPolicyPrefix+"gold")
+			Expect(binding.Spec.PolicyName).To(Equal(policy.Name))
+			Expect(binding.Spec.MatchResources.NamespaceSelector).To(Equal(restricted))
+			Expect(binding.Spec.ParamRef).To(BeNil())
+		})
+
+		It("should pass the selected namespaces as params to the multi namespace plan policy", func() {
+			name := StorageClassPolicyPrefix + "gold" + MultiNamespacePolicySuffix
+			policy := getPolicy(objs, name)
+			Expect(policy.Spec.ParamKind).To(Equal(&admissionregistrationv1.ParamKind{APIVersion: "v1", Kind: "Namespace"}))
+			Expect(policy.Spec.MatchConstraints.ResourceRules[0].Resources).To(ConsistOf(multiNamespacePlanResource))
+			binding := getPolicyBinding(objs, name)
+			Expect(binding.Spec.PolicyName).To(Equal(name))
+			Expect(binding.Spec.MatchResources).To(BeNil())
+			Expect(binding.Spec.ParamRef.Selector).To(Equal(restricted))
+			Expect(binding.Spec.ParamRef.Namespace).To(BeEmpty())
+			Expect(binding.Spec.ParamRef.ParameterNotFoundAction).To(Equal(ptr.To(admissionregistrationv1.AllowAction)))
+		})
+
+		It("should check every namespace of multi namespace plans without reading namespaces when unselected", func() {
+			objs = createPlanPolicies(&FactoryArgs{PlanPolicies: &v1alpha1.MigControllerPlanPolicies{
+				StorageClasses: []v1alpha1.MigControllerStorageClassPolicy{{Name: "all", Denied: []string{"silver"}}},
+			}})
+			Expect(getPolicyBinding(objs, StorageClassPolicyPrefix+"all").Spec.MatchResources).To(BeNil())
+			name := StorageClassPolicyPrefix + "all" + MultiNamespacePolicySuffix
+			Expect(getPolicyBinding(objs, name).Spec.ParamRef).To(BeNil())
+			policy := getPolicy(objs, name)
+			Expect(policy.Spec.ParamKind).To(BeNil())
+
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource,
+				newMultiPlan("", map[string]string{"ns1": "gold", "ns2": "bronze"}), nil)).To(BeEmpty())
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource,
+				newMultiPlan("", map[string]string{"ns1": "gold", "ns2": "silver"}), nil)).
+				To(Equal("plans migrating namespaces ns2 may not target the storage classes silver"))
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource,
+				newMultiPlan("", map[string]string{"ns1": "gold", "ns2": ""}), nil)).
+				To(Equal("plans migrating namespaces ns2 must name the storage class of every destination PVC"))
+		})
+
+		It("should only check plans on creation", func() {
+			for _, obj := range objs {
+				if policy, ok := obj.(*admissionregistrationv1.ValidatingAdmissionPolicy); ok {
+					Expect(policy.Spec.MatchConstraints.ResourceRules[0].Operations).
+						To(ConsistOf(admissionregistrationv1.Create), policy.Name)
+				}
+			}
+		})
+
+		It("should check the storage classes of single namespace plans", func() {
+			policy := getPolicy(objs, StorageClassPolicyPrefix+"gold")
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan("", "gold"), nil)).To(BeEmpty())
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan(""), nil)).To(BeEmpty())
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan("", "gold", "bronze"), nil)).
+				To(Equal("plans migrating this namespace may only target the storage classes gold"))
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan("", "silver"), nil)).
+				To(Equal("plans migrating this namespace may only target the storage classes gold"))
+		})
+
+		It("should check the storage classes of the selected namespaces of multi namespace plans", func() {
+			policy := getPolicy(objs, StorageClassPolicyPrefix+"gold"+MultiNamespacePolicySuffix)
+			plan := newMultiPlan("", map[string]string{"ns1": "gold", "ns2": "silver"})
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource, plan, namespaceParams("ns1"))).To(BeEmpty())
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource, plan, namespaceParams("ns2"))).
+				To(Equal("plans migrating namespace ns2 may only target the storage classes gold"))
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource, plan, namespaceParams("ns3"))).To(BeEmpty())
+
+			plan = newMultiPlan("", map[string]string{"ns1": "gold", "ns2": "bronze"})
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource, plan, namespaceParams("ns2"))).
+				To(Equal("plans migrating namespace ns2 may only target the storage classes gold"))
+		})
+
+		It("should check the denied storage classes", func() {
+			objs = createPlanPolicies(&FactoryArgs{PlanPolicies: &v1alpha1.MigControllerPlanPolicies{
+				StorageClasses: []v1alpha1.MigControllerStorageClassPolicy{{Name: "deny", Denied: []string{"silver"}}},
+			}})
+			policy := getPolicy(objs, StorageClassPolicyPrefix+"deny")
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan("", "bronze"), nil)).To(BeEmpty())
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan("", "silver"), nil)).
+				To(Equal("plans migrating this namespace may not target the storage classes silver"))
+		})
+
+		It("should reject destination PVCs omitting the storage class", func() {
+			policy := getPolicy(objs, StorageClassPolicyPrefix+"gold")
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan("", "gold", ""), nil)).
+				To(Equal("plans migrating this namespace must name the storage class of every destination PVC"))
+
+			policy = getPolicy(objs, StorageClassPolicyPrefix+"gold"+MultiNamespacePolicySuffix)
+			plan := newMultiPlan("", map[string]string{"ns1": "gold", "ns2": ""})
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource, plan, namespaceParams("ns1"))).To(BeEmpty())
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource, plan, namespaceParams("ns2"))).
+				To(Equal("plans migrating namespace ns2 must name the storage class of every destination PVC"))
+
+			objs = createPlanPolicies(&FactoryArgs{PlanPolicies: &v1alpha1.MigControllerPlanPolicies{
+				StorageClasses: []v1alpha1.MigControllerStorageClassPolicy{{Name: "deny", Denied: []string{"silver"}}},
+			}})
+			policy = getPolicy(objs, StorageClassPolicyPrefix+"deny")
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan("", ""), nil)).
+				To(Equal("plans migrating this namespace must name the storage class of every destination PVC"))
+		})
+	})
+
+	Context("restricting the deleteSource retention policy", func() {
+		var objs []client.Object
+
+		BeforeEach(func() {
+			objs = createPlanPolicies(&FactoryArgs{PlanPolicies: &v1alpha1.MigControllerPlanPolicies{RestrictDeleteSource: true}})
+		})
+
+		It("should select the namespaces that did not opt in", func() {
+			Expect(objs).To(HaveLen(4))
+			selector := getPolicyBinding(objs, DeleteSourcePolicyName).Spec.MatchResources.NamespaceSelector
+			Expect(selector.MatchExpressions).To(ConsistOf(metav1.LabelSelectorRequirement{
+				Key:      v1alpha1.AllowDeleteSourceLabel,
+				Operator: metav1.LabelSelectorOpNotIn,
+				Values:   []string{"true"},
+			}))
+			Expect(getPolicyBinding(objs, DeleteSourcePolicyName+MultiNamespacePolicySuffix).Spec.ParamRef.Selector).To(Equal(selector))
+		})
+
+		It("should reject deleting the source of single namespace plans", func() {
+			policy := getPolicy(objs, DeleteSourcePolicyName)
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan(""), nil)).To(BeEmpty())
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan("keepSource"), nil)).To(BeEmpty())
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan("deleteSource"), nil)).
+				To(Equal("the deleteSource retention policy requires this namespace to be labeled " + v1alpha1.AllowDeleteSourceLabel + "=true"))
+		})
+
+		It("should reject deleting the source of multi namespace plans in the selected namespaces only", func() {
+			policy := getPolicy(objs, DeleteSourcePolicyName+MultiNamespacePolicySuffix)
+			plan := newMultiPlan("deleteSource", map[string]string{"ns1": "gold"})
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource, plan, namespaceParams("ns1"))).
+				To(Equal("the deleteSource retention policy requires namespace ns1 to be labeled " + v1alpha1.AllowDeleteSourceLabel + "=true"))
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource, plan, namespaceParams("ns2"))).To(BeEmpty())
+
+			plan = newMultiPlan("", map[string]string{"ns1": "gold", "ns2": "gold"})
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource, plan, namespaceParams("ns1"))).To(BeEmpty())
+			entries, _ := plan["spec"].(map[string]interface{})["namespaces"].([]interface{})
+			for _, entry := range entries {
+				if entry.(map[string]interface{})["name"] == "ns2" {
+					entry.(map[string]interface{})["retentionPolicy"] = "deleteSource"
+				}
+			}
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource, plan, namespaceParams("ns1"))).To(BeEmpty())
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource, plan, namespaceParams("ns2"))).ToNot(BeEmpty())
+		})
+	})
+
+	Context("capping the virtual machines per plan", func() {
+		It("should count the virtual machines of both plan kinds", func() {
+			objs := createPlanPolicies(&FactoryArgs{PlanPolicies: &v1alpha1.MigControllerPlanPolicies{
+				MaxVirtualMachinesPerPlan: ptr.To[int32](1),
+			}})
+			Expect(objs).To(HaveLen(2))
+			Expect(getPolicyBinding(objs, MaxVirtualMachinesPolicyName).Spec.MatchResources).To(BeNil())
+			policy := getPolicy(objs, MaxVirtualMachinesPolicyName)
+			Expect(policy.Spec.MatchConstraints.ResourceRules[0].Resources).To(ConsistOf(planResource, multiNamespacePlanResource))
+
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan("", "gold"), nil)).To(BeEmpty())
+			Expect(evaluatePolicy(policy, planResource, newSinglePlan("", "gold", "gold"), nil)).
+				To(Equal("a plan may migrate at most 1 virtual machines"))
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource, newMultiPlan("", map[string]string{"ns1": "gold"}), nil)).
+				To(BeEmpty())
+			Expect(evaluatePolicy(policy, multiNamespacePlanResource,
+				newMultiPlan("", map[string]string{"ns1": "gold", "ns2": "gold"}), nil)).
+				To(Equal("a plan may migrate at most 1 virtual machines"))
+		})
+	})
+})
@@ -17,6 +17,8 @@ limitations under the License.
 package cluster
 
 import (
+	"slices"
+
 	rbacv1 "k8s.io/api/rbac/v1"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -24,32 +26,80 @@ import (
 	utils "kubevirt.io/kubevirt-migration-operator/pkg/resources/utils"
 )
 
-func createAggregateClusterRoles(_ *FactoryArgs) []client.Object {
+const (
+	// StorageMigrateRoleName is the ClusterRole allowing single namespace storage migrations
+	StorageMigrateRoleName = "migrations.kubevirt.io:storagemigrate"
+	// StorageMigrateMultinsRoleName is the ClusterRole allowing multi namespace storage migrations
+	StorageMigrateMultinsRoleName = "migrations.kubevirt.io:storagemigrate-multins"
+)
+
+func createAggregateClusterRoles(args *FactoryArgs) []client.Object {
+	// aggregating would grant access in every namespace, when the controller is restricted to some namespaces
+	// the roles are left for binding in those
+	aggregate := args.WatchNamespaces == nil
+	// opting in to namespace roles still aggregates admin and edit, the storage migration rules then apply
+	// in every namespace but storage migrations created outside the watched namespaces are not processed
+	aggregateStorageMigrations := aggregate || args.AggregateToNamespaceRoles
 	return []client.Object{
-		utils.ResourceBuilder.CreateAggregateClusterRole("migrations.kubevirt.io:admin", "admin", getAdminPolicyRules()),
-		utils.ResourceBuilder.CreateAggregateClusterRole("migrations.kubevirt.io:edit", "edit", getEditPolicyRules()),
-		utils.ResourceBuilder.CreateAggregateClusterRole("migrations.kubevirt.io:view", "view", getViewPolicyRules()),
+		createAggregateClusterRole("migrations.kubevirt.io:admin", "admin", getAdminPolicyRules(args.AggregateToNamespaceRoles), aggregateStorageMigrations),
+		createAggregateClusterRole("migrations.kubevirt.io:edit", "edit", getEditPolicyRules(args.AggregateToNamespaceRoles), aggregateStorageMigrations),
+		createAggregateClusterRole("migrations.kubevirt.io:view", "view", getViewPolicyRules(), aggregate),
 	}
 }
 
+func createAggregateClusterRole(name, aggregateTo string, rules []rbacv1.PolicyRule, aggregate bool) *rbacv1.ClusterRole {
+	role := utils.ResourceBuilder.CreateAggregateClusterRole(name, aggregateTo, rules)
+	if !aggregate {
+		// disabled rather than removed, labels of existing roles are merged
+		role.Labels["rbac.authorization.k8s.io/aggregate-to-"+aggregateTo] = "false"
+	}
+	return role
+}
+
 func createStorageMigrationClusterRoles(_ *FactoryArgs) []client.Object {
 	return []client.Object{
-		utils.ResourceBuilder.CreateClusterRole(
-			"migrations.kubevirt.io:storagemigrate", getStorageMigratePolicyRules()),
-		utils.ResourceBuilder.CreateClusterRole(
-			"migrations.kubevirt.io:storagemigrate-multins", getStorageMigrateMultinsPolicyRules()),
+		utils.ResourceBuilder.CreateClusterRole(StorageMigrateRoleName, getStorageMigratePolicyRules()),
+		utils.ResourceBuilder.CreateClusterRole(StorageMigrateMultinsRoleName, getStorageMigrateMultinsPolicyRules()),
 	}
 }
 
-func getAdminPolicyRules() []rbacv1.PolicyRule {
-	// leaving this here so we have optionality in the future for other resources
-	// currently we follow the kubevirt model where only admin can migrate
+// createStorageMigrationClusterRoleBindings binds the configured subjects to the storage migration roles,
+// a binding without subjects is not created
+func createStorageMigrationClusterRoleBindings(args *FactoryArgs) []client.Object {
+	var bindings []client.Object
+	if len(args.StorageMigrators) > 0 {
+		bindings = append(bindings, createSubjectsClusterRoleBinding(StorageMigrateRoleName, args.StorageMigrators))
+	}
+	if len(args.MultiNamespaceStorageMigrators) > 0 {
+		bindings = append(bindings, createSubjectsClusterRoleBinding(StorageMigrateMultinsRoleName, args.MultiNamespaceStorageMigrators))
+	}
+	return bindings
+}
+
+func createSubjectsClusterRoleBinding(roleName string, subjects []rbacv1.Subject) *rbacv1.ClusterRoleBinding {
+	binding := utils.ResourceBuilder.CreateClusterRoleBinding(roleName, roleName, "", "")
+	binding.Subjects = make([]rbacv1.Subject, len(subjects))
+	for i, subject := range subjects {
+		// defaulted by the API server, set here so the binding does not look changed on every reconcile
+		if subject.APIGroup == "" && (subject.Kind == rbacv1.UserKind || subject.Kind == rbacv1.GroupKind) {
+			subject.APIGroup = rbacv1.GroupName
+		}
+		binding.Subjects[i] = subject
+	}
+	return binding
+}
+
+func getAdminPolicyRules(allowStorageMigrations bool) []rbacv1.PolicyRule {
+	// we follow the kubevirt model where only admin can migrate unless namespace roles are opted in
+	if allowStorageMigrations {
+		return getStorageMigratePolicyRules()
+	}
 	return []rbacv1.PolicyRule{}
 }
 
-func getEditPolicyRules() []rbacv1.PolicyRule {
+func getEditPolicyRules(allowStorageMigrations bool) []rbacv1.PolicyRule {
 	// diff between admin and edit ClusterRoles is minimal
-	return getAdminPolicyRules()
+	return getAdminPolicyRules(allowStorageMigrations)
 }
 
 func getViewPolicyRules() []rbacv1.PolicyRule {
@@ -73,6 +123,17 @@ func getViewPolicyRules() []rbacv1.PolicyRule {
 	}
 }
 
+// GetStorageMigrateResources returns the resources the single namespace storage migration role allows creating
+func GetStorageMigrateResources() []string {
+	var resources []string
+	for _, rule := range getStorageMigratePolicyRules() {
+		if slices.Contains(rule.Verbs, "create") {
+			resources = append(resources, rule.Resources...)
+		}
+	}
+	return resources
+}
+
 func getStorageMigratePolicyRules() []rbacv1.PolicyRule {
 	return []rbacv1.PolicyRule{
 		{
@@ -0,0 +1,105 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestCluster(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cluster Resources Suite")
+}
+
+func getClusterRole(resources []client.Object, name string) *rbacv1.ClusterRole {
+	for _, resource := range resources {
+		if role, ok := resource.(*rbacv1.ClusterRole); ok && role.Name == name {
+			return role
+		}
+	}
+	return nil
+}
+
+var _ = Describe("Aggregate cluster roles", func() {
+	DescribeTable("should aggregate the storage migration rules into the namespace roles",
+		func(args *FactoryArgs, aggregateView, aggregateStorageMigrations bool) {
+			resources := createAggregateClusterRoles(args)
+			Expect(resources).To(HaveLen(3))
+
+			view := getClusterRole(resources, "migrations.kubevirt.io:view")
+			Expect(view.Labels).To(HaveKeyWithValue("rbac.authorization.k8s.io/aggregate-to-view", strconv.FormatBool(aggregateView)))
+			Expect(view.Rules).To(Equal(getViewPolicyRules()))
+
+			for _, aggregateTo := range []string{"admin", "edit"} {
+				role := getClusterRole(resources, "migrations.kubevirt.io:"+aggregateTo)
+				Expect(role.Labels).To(HaveKeyWithValue("rbac.authorization.k8s.io/aggregate-to-"+aggregateTo,
+					strconv.FormatBool(aggregateStorageMigrations)))
+				if args.AggregateToNamespaceRoles {
+					Expect(role.Rules).To(Equal(getStorageMigratePolicyRules()))
+				} else {
+					Expect(role.Rules).To(BeEmpty())
+				}
+			}
+		},
+		Entry("all namespaces", &FactoryArgs{}, true, true),
+		Entry("all namespaces opted in", &FactoryArgs{AggregateToNamespaceRoles: true}, true, true),
+		Entry("restricted namespaces", &FactoryArgs{WatchNamespaces: []string{"ns1"}}, false, false),
+		Entry("restricted namespaces opted in",
+			&FactoryArgs{WatchNamespaces: []string{"ns1"}, AggregateToNamespaceRoles: true}, false, true),
+	)
+})
+
+var _ = Describe("Storage migration cluster role bindings", func() {
+	It("should not bind without subjects", func() {
+		Expect(createStorageMigrationClusterRoleBindings(&FactoryArgs{})).To(BeEmpty())
+	})
+
+	It("should bind the subjects to the storage migration roles", func() {
+		resources := createStorageMigrationClusterRoleBindings(&FactoryArgs{
+			StorageMigrators: []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "tenants"}},
+			MultiNamespaceStorageMigrators: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: "migrator", Namespace: "ns1"},
+			},
+		})
+		Expect(resources).To(HaveLen(2))
+
+		binding := resources[0].(*rbacv1.ClusterRoleBinding)
+		Expect(binding.Name).To(Equal(StorageMigrateRoleName))
+		Expect(binding.RoleRef.Name).To(Equal(StorageMigrateRoleName))
+		Expect(binding.Subjects).To(ConsistOf(rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "tenants"}))
+
+		binding = resources[1].(*rbacv1.ClusterRoleBinding)
+		Expect(binding.Name).To(Equal(StorageMigrateMultinsRoleName))
+		Expect(binding.RoleRef.Name).To(Equal(StorageMigrateMultinsRoleName))
+		Expect(binding.Subjects).To(ConsistOf(rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "migrator", Namespace: "ns1"}))
+	})
+})
+
+var _ = Describe("Storage migrate resources", func() {
+	It("should list the resources the storage migration role allows creating", func() {
+		Expect(GetStorageMigrateResources()).To(ConsistOf(
+			"virtualmachinestoragemigrations",
+			"virtualmachinestoragemigrationplans",
+		))
+	})
+})
@@ -0,0 +1,42 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// NewCRDs returns the storage migration CRDs the operator installs, sorted by name
+func NewCRDs() ([]*extv1.CustomResourceDefinition, error) {
+	var crds []*extv1.CustomResourceDefinition
+	for key, content := range MigrationControllerCRDs {
+		crd := &extv1.CustomResourceDefinition{}
+		if err := k8syaml.NewYAMLToJSONDecoder(strings.NewReader(content)).Decode(crd); err != nil {
+			return nil, fmt.Errorf("unable to decode the %s CRD; %w", key, err)
+		}
+		crds = append(crds, crd)
+	}
+	sort.Slice(crds, func(i, j int) bool {
+		return crds[i].Name < crds[j].Name
+	})
+	return crds, nil
+}
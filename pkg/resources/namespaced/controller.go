@@ -35,18 +35,29 @@ import (
 )
 
 func createControllerResources(args *FactoryArgs) []client.Object {
-	return []client.Object{
+	deployment := createControllerDeployment(
+		args.ControllerImage,
+		args.Verbosity,
+		args.PullPolicy,
+		args.PriorityClassName,
+		args.InfraNodePlacement)
+	resources := []client.Object{
 		createControllerServiceAccount(),
 		createControllerRoleBinding(),
 		createControllerRole(),
-		createControllerDeployment(
-			args.ControllerImage,
-			args.Verbosity,
-			args.PullPolicy,
-			args.PriorityClassName,
-			args.InfraNodePlacement),
+		deployment,
 		createPrometheusService(),
 	}
+	if args.WatchNamespaces != nil {
+		if len(args.WatchNamespaces) == 0 {
+			// an empty WATCH_NAMESPACES means all namespaces, a controller restricted to none is not run instead
+			deployment.Spec.Replicas = ptr.To[int32](0)
+		} else {
+			setWatchNamespacesEnv(deployment, args.WatchNamespaces)
+			resources = append(resources, createWatchNamespaceResources(args.Namespace, args.WatchNamespaces)...)
+		}
+	}
+	return resources
 }
 
 func createControllerRoleBinding() *rbacv1.RoleBinding {
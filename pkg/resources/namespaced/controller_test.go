@@ -21,7 +21,9 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
 )
 
@@ -93,6 +95,80 @@ var _ = Describe("Controller Deployment", func() {
 		}),
 	)
 
+	Context("watch namespaces", func() {
+		findEnv := func(container corev1.Container, name string) *corev1.EnvVar {
+			for i := range container.Env {
+				if container.Env[i].Name == name {
+					return &container.Env[i]
+				}
+			}
+			return nil
+		}
+
+		It("should not restrict the controller by default", func() {
+			resources := createControllerResources(&FactoryArgs{Namespace: "kubevirt"})
+
+			for _, resource := range resources {
+				Expect(resource.GetNamespace()).To(BeEmpty())
+				if deployment, ok := resource.(*appsv1.Deployment); ok {
+					Expect(findEnv(deployment.Spec.Template.Spec.Containers[0], WatchNamespacesEnv)).To(BeNil())
+				}
+			}
+		})
+
+		It("should grant the controller rights in the watched namespaces only", func() {
+			resources := createControllerResources(&FactoryArgs{
+				Namespace:       "kubevirt",
+				WatchNamespaces: []string{"ns1", "ns2"},
+			})
+
+			var roles []*rbacv1.Role
+			var roleBindings []*rbacv1.RoleBinding
+			for _, resource := range resources {
+				switch obj := resource.(type) {
+				case *appsv1.Deployment:
+					env := findEnv(obj.Spec.Template.Spec.Containers[0], WatchNamespacesEnv)
+					Expect(env).ToNot(BeNil())
+					Expect(env.Value).To(Equal("ns1,ns2"))
+				case *rbacv1.Role:
+					if obj.Namespace != "" {
+						roles = append(roles, obj)
+					}
+				case *rbacv1.RoleBinding:
+					if obj.Namespace != "" {
+						roleBindings = append(roleBindings, obj)
+					}
+				}
+			}
+
+			Expect(roles).To(HaveLen(2))
+			Expect(roleBindings).To(HaveLen(2))
+			for i, namespace := range []string{"ns1", "ns2"} {
+				Expect(roles[i].Namespace).To(Equal(namespace))
+				Expect(roles[i].Rules).To(Equal(GetControllerWatchNamespacePolicyRules()))
+				Expect(roleBindings[i].Namespace).To(Equal(namespace))
+				Expect(roleBindings[i].RoleRef.Name).To(Equal(roles[i].Name))
+				Expect(roleBindings[i].Subjects).To(HaveLen(1))
+				Expect(roleBindings[i].Subjects[0].Namespace).To(Equal("kubevirt"))
+			}
+		})
+
+		It("should not run the controller restricted to no namespace", func() {
+			resources := createControllerResources(&FactoryArgs{
+				Namespace:       "kubevirt",
+				WatchNamespaces: []string{},
+			})
+
+			for _, resource := range resources {
+				Expect(resource.GetNamespace()).To(BeEmpty())
+				if deployment, ok := resource.(*appsv1.Deployment); ok {
+					Expect(deployment.Spec.Replicas).To(HaveValue(BeZero()))
+					Expect(findEnv(deployment.Spec.Template.Spec.Containers[0], WatchNamespacesEnv)).To(BeNil())
+				}
+			}
+		})
+	})
+
 	Context("kubevirtCAVolume function", func() {
 		It("should return a properly configured volume", func() {
 			volume := kubevirtCAVolume()
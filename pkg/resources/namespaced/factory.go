@@ -37,6 +37,8 @@ type FactoryArgs struct {
 	PriorityClassName      string
 	Namespace              string
 	InfraNodePlacement     *sdkapi.NodePlacement
+	// WatchNamespaces restricts the controller to the listed namespaces, nil means all namespaces and empty none
+	WatchNamespaces []string
 }
 
 type factoryFunc func(*FactoryArgs) []client.Object
@@ -0,0 +1,216 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespaced
+
+import (
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+	utils "kubevirt.io/kubevirt-migration-operator/pkg/resources/utils"
+)
+
+const (
+	// WatchNamespacesEnv passes the namespaces the controller is restricted to as a comma separated list
+	WatchNamespacesEnv = "WATCH_NAMESPACES"
+
+	watchNamespaceResourceName = common.ControllerResourceName + "-namespace"
+)
+
+// createWatchNamespaceResources grants the controller its rights in each of the namespaces it is restricted to
+func createWatchNamespaceResources(serviceAccountNamespace string, watchNamespaces []string) []client.Object {
+	var resources []client.Object
+	for _, namespace := range watchNamespaces {
+		role := utils.ResourceBuilder.CreateRole(watchNamespaceResourceName, GetControllerWatchNamespacePolicyRules())
+		role.Namespace = namespace
+		roleBinding := utils.ResourceBuilder.CreateRoleBinding(
+			watchNamespaceResourceName,
+			watchNamespaceResourceName,
+			common.ControllerServiceAccountName,
+			serviceAccountNamespace,
+		)
+		roleBinding.Namespace = namespace
+		resources = append(resources, role, roleBinding)
+	}
+	return resources
+}
+
+func setWatchNamespacesEnv(deployment *appsv1.Deployment, watchNamespaces []string) {
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name:  WatchNamespacesEnv,
+		Value: strings.Join(watchNamespaces, ","),
+	})
+}
+
+// GetControllerWatchNamespacePolicyRules returns the rules the controller needs in the namespaces it watches
+func GetControllerWatchNamespacePolicyRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{
+				"",
+			},
+			Resources: []string{
+				"events",
+			},
+			Verbs: []string{
+				"create",
+				"patch",
+			},
+		},
+		{
+			APIGroups: []string{
+				"",
+			},
+			Resources: []string{
+				"persistentvolumeclaims",
+			},
+			Verbs: []string{
+				"list",
+				"watch",
+				"update",
+				"delete",
+			},
+		},
+		{
+			APIGroups: []string{
+				"",
+			},
+			Resources: []string{
+				"pods",
+			},
+			Verbs: []string{
+				"list",
+				"watch",
+				"delete",
+			},
+		},
+		{
+			APIGroups: []string{
+				"kubevirt.io",
+			},
+			Resources: []string{
+				"virtualmachineinstances",
+			},
+			Verbs: []string{
+				"list",
+				"watch",
+				"get",
+			},
+		},
+		{
+			APIGroups: []string{
+				"kubevirt.io",
+			},
+			Resources: []string{
+				"virtualmachines",
+			},
+			Verbs: []string{
+				"list",
+				"watch",
+				"get",
+				"patch",
+			},
+		},
+		{
+			APIGroups: []string{
+				"kubevirt.io",
+			},
+			Resources: []string{
+				"virtualmachineinstancemigrations",
+			},
+			Verbs: []string{
+				"list",
+				"watch",
+				"get",
+				"delete",
+			},
+		},
+		{
+			APIGroups: []string{
+				"cdi.kubevirt.io",
+			},
+			Resources: []string{
+				"datavolumes",
+			},
+			Verbs: []string{
+				"list",
+				"watch",
+				"get",
+				"create",
+				"update",
+				"patch",
+				"delete",
+			},
+		},
+		{
+			APIGroups: []string{
+				"migrations.kubevirt.io",
+			},
+			Resources: []string{
+				"virtualmachinestoragemigrations",
+				"virtualmachinestoragemigrationplans",
+				"multinamespacevirtualmachinestoragemigrations",
+				"multinamespacevirtualmachinestoragemigrationplans",
+			},
+			Verbs: []string{
+				"list",
+				"watch",
+				"get",
+				"patch",
+				"update",
+				"create",
+				"delete",
+			},
+		},
+		{
+			APIGroups: []string{
+				"migrations.kubevirt.io",
+			},
+			Resources: []string{
+				"virtualmachinestoragemigrations/status",
+				"virtualmachinestoragemigrationplans/status",
+				"multinamespacevirtualmachinestoragemigrations/status",
+				"multinamespacevirtualmachinestoragemigrationplans/status",
+			},
+			Verbs: []string{
+				"get",
+				"patch",
+				"update",
+			},
+		},
+		{
+			APIGroups: []string{
+				"migrations.kubevirt.io",
+			},
+			Resources: []string{
+				"virtualmachinestoragemigrations/finalizers",
+				"virtualmachinestoragemigrationplans/finalizers",
+				"multinamespacevirtualmachinestoragemigrations/finalizers",
+				"multinamespacevirtualmachinestoragemigrationplans/finalizers",
+			},
+			Verbs: []string{
+				"update",
+			},
+		},
+	}
+}
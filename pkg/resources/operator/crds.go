@@ -0,0 +1,212 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	csvv1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources"
+)
+
+const descriptorPrefix = "urn:alm:descriptor:"
+
+// ownedCRDs holds the console names of the storage migration CRDs the operator installs,
+// keyed like resources.MigrationControllerCRDs
+var ownedCRDs = map[string]struct {
+	displayName string
+	description string
+}{
+	"virtualmachinestoragemigrationplan": {
+		displayName: "Virtual Machine Storage Migration Plan",
+		description: "Plans the migration of the volumes of virtual machines in a namespace to new storage",
+	},
+	"virtualmachinestoragemigration": {
+		displayName: "Virtual Machine Storage Migration",
+		description: "Runs a virtual machine storage migration plan",
+	},
+	"multinamespacevirtualmachinestoragemigrationplan": {
+		displayName: "Multi Namespace Virtual Machine Storage Migration Plan",
+		description: "Plans the migration of the volumes of virtual machines in several namespaces to new storage",
+	},
+	"multinamespacevirtualmachinestoragemigration": {
+		displayName: "Multi Namespace Virtual Machine Storage Migration",
+		description: "Runs a multi namespace virtual machine storage migration plan",
+	},
+}
+
+// createOwnedCRDDescriptions describes the storage migration CRDs in the CSV, the descriptors
+// are derived from the schema and the printer columns of the storage version
+func createOwnedCRDDescriptions() ([]csvv1.CRDDescription, error) {
+	var descriptions []csvv1.CRDDescription
+	for key := range resources.MigrationControllerCRDs {
+		if _, ok := ownedCRDs[key]; !ok {
+			return nil, fmt.Errorf("CRD %s has no CSV description", key)
+		}
+	}
+	crds, err := resources.NewCRDs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, crd := range crds {
+		owned := ownedCRDs[strings.ToLower(crd.Spec.Names.Kind)]
+		version := getStorageVersion(crd)
+		if version == nil {
+			return nil, fmt.Errorf("CRD %s has no storage version", crd.Name)
+		}
+		descriptions = append(descriptions, csvv1.CRDDescription{
+			Name:              crd.Name,
+			Version:           version.Name,
+			Kind:              crd.Spec.Names.Kind,
+			DisplayName:       owned.displayName,
+			Description:       owned.description,
+			SpecDescriptors:   createSpecDescriptors(version),
+			StatusDescriptors: createStatusDescriptors(version),
+		})
+	}
+
+	return descriptions, nil
+}
+
+func getStorageVersion(crd *extv1.CustomResourceDefinition) *extv1.CustomResourceDefinitionVersion {
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Storage {
+			return &crd.Spec.Versions[i]
+		}
+	}
+	return nil
+}
+
+func createSpecDescriptors(version *extv1.CustomResourceDefinitionVersion) []csvv1.SpecDescriptor {
+	var descriptors []csvv1.SpecDescriptor
+	for _, field := range getSchemaFields(version, "spec") {
+		descriptors = append(descriptors, csvv1.SpecDescriptor{
+			Path:         field.path,
+			DisplayName:  field.displayName,
+			Description:  field.schema.Description,
+			XDescriptors: getSpecXDescriptors(field.schema),
+		})
+	}
+	return descriptors
+}
+
+func createStatusDescriptors(version *extv1.CustomResourceDefinitionVersion) []csvv1.StatusDescriptor {
+	var descriptors []csvv1.StatusDescriptor
+	for _, field := range getSchemaFields(version, "status") {
+		descriptors = append(descriptors, csvv1.StatusDescriptor{
+			Path:         field.path,
+			DisplayName:  field.displayName,
+			Description:  field.schema.Description,
+			XDescriptors: getStatusXDescriptors(field.path, field.schema),
+		})
+	}
+	return descriptors
+}
+
+type schemaField struct {
+	path        string
+	displayName string
+	schema      extv1.JSONSchemaProps
+}
+
+// getSchemaFields returns the top level fields of the spec or status schema sorted by path,
+// the fields shown in a printer column are named like the column
+func getSchemaFields(version *extv1.CustomResourceDefinitionVersion, section string) []schemaField {
+	if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+		return nil
+	}
+	properties := version.Schema.OpenAPIV3Schema.Properties[section].Properties
+
+	columns := map[string]string{}
+	for _, column := range version.AdditionalPrinterColumns {
+		path, ok := strings.CutPrefix(column.JSONPath, "."+section+".")
+		if !ok {
+			continue
+		}
+		// columns of nested fields, e.g. .spec.planRef.name, name the top level field
+		name, _, _ := strings.Cut(path, ".")
+		if _, exists := columns[name]; !exists && !strings.ContainsAny(name, "[]") {
+			columns[name] = column.Name
+		}
+	}
+
+	var fields []schemaField
+	for name, schema := range properties {
+		displayName, ok := columns[name]
+		if !ok {
+			displayName = getDisplayName(name)
+		}
+		fields = append(fields, schemaField{path: name, displayName: displayName, schema: schema})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].path < fields[j].path
+	})
+	return fields
+}
+
+func getSpecXDescriptors(schema extv1.JSONSchemaProps) []string {
+	switch {
+	case len(schema.Enum) > 0:
+		var xDescriptors []string
+		for _, value := range schema.Enum {
+			xDescriptors = append(xDescriptors, descriptorPrefix+"com.tectonic.ui:select:"+strings.Trim(string(value.Raw), `"`))
+		}
+		return xDescriptors
+	case schema.Type == "boolean":
+		return []string{descriptorPrefix + "com.tectonic.ui:booleanSwitch"}
+	case schema.Type == "integer" || schema.Type == "number":
+		return []string{descriptorPrefix + "com.tectonic.ui:number"}
+	case schema.Type == "string":
+		return []string{descriptorPrefix + "com.tectonic.ui:text"}
+	}
+	return nil
+}
+
+func getStatusXDescriptors(path string, schema extv1.JSONSchemaProps) []string {
+	switch {
+	case path == "conditions":
+		return []string{descriptorPrefix + "io.kubernetes.conditions"}
+	case path == "phase":
+		return []string{descriptorPrefix + "io.kubernetes.phase"}
+	case schema.Type == "string":
+		return []string{descriptorPrefix + "text"}
+	}
+	return nil
+}
+
+// getDisplayName turns a camel case field name into words, e.g. retentionPolicy into Retention Policy
+func getDisplayName(name string) string {
+	var words []string
+	start := 0
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			words = append(words, name[start:i])
+			start = i
+		}
+	}
+	words = append(words, name[start:])
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -11,11 +11,13 @@ import (
 type ClusterServiceVersionData struct {
 	CsvVersion         string
 	ReplacesCsvVersion string
-	Namespace          string
-	ImagePullPolicy    string
-	ImagePullSecrets   []corev1.LocalObjectReference
-	IconBase64         string
-	Verbosity          string
+	// SkipRange is the olm.skipRange annotation, the semver range of versions the CSV can upgrade from directly
+	SkipRange        string
+	Namespace        string
+	ImagePullPolicy  string
+	ImagePullSecrets []corev1.LocalObjectReference
+	IconBase64       string
+	Verbosity        string
 
 	OperatorVersion string
 
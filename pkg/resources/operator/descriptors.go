@@ -0,0 +1,69 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"kubevirt.io/kubevirt-migration-operator/api/v1alpha1"
+)
+
+// checkDescriptors fails when a MigController spec or status field has no CSV descriptor,
+// descriptors_generated.go has to be regenerated with descriptor-generator then
+func checkDescriptors() error {
+	described := map[string]bool{}
+	for _, d := range migControllerSpecDescriptors {
+		described["spec."+d.Path] = true
+	}
+	for _, d := range migControllerStatusDescriptors {
+		described["status."+d.Path] = true
+	}
+
+	var missing []string
+	for _, path := range append(getJSONFields("spec", reflect.TypeOf(v1alpha1.MigControllerSpec{})),
+		getJSONFields("status", reflect.TypeOf(v1alpha1.MigControllerStatus{}))...) {
+		if !described[path] {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("MigController fields without CSV descriptor: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// getJSONFields returns the JSON paths of the fields of t, the fields of inline structs included
+func getJSONFields(prefix string, t reflect.Type) []string {
+	var paths []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch {
+		case name == "-" || !field.IsExported():
+			continue
+		case name == "" && field.Anonymous && opts == "inline":
+			paths = append(paths, getJSONFields(prefix, field.Type)...)
+			continue
+		case name == "":
+			name = field.Name
+		}
+		paths = append(paths, prefix+"."+name)
+	}
+	return paths
+}
@@ -0,0 +1,157 @@
+// Code generated by descriptor-generator.go. DO NOT EDIT.
+
+package operator
+
+import csvv1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+// migControllerSpecDescriptors are generated from the descriptor markers of the MigController API
+var migControllerSpecDescriptors = []csvv1.SpecDescriptor{
+	{
+		Path:         "priorityClass",
+		DisplayName:  "Priority Class",
+		Description:  "PriorityClass of the control plane",
+		XDescriptors: []string{"urn:alm:descriptor:text"},
+	},
+	{
+		Path:         "imagePullPolicy",
+		DisplayName:  "Image Pull Policy",
+		Description:  "PullPolicy describes a policy for if/when to pull a container image",
+		XDescriptors: []string{"urn:alm:descriptor:io.kubernetes:imagePullPolicy"},
+	},
+	{
+		Path:         "infra",
+		DisplayName:  "Infrastructure Placement",
+		Description:  "Rules on which nodes infrastructure pods will be scheduled",
+		XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:advanced"},
+	},
+	{
+		Path:         "tlsSecurityProfile",
+		DisplayName:  "TLS Security Profile",
+		Description:  "TLSSecurityProfile is used by operators to apply cluster-wide TLS security settings to operands.",
+		XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:advanced"},
+	},
+	{
+		Path:         "upgradeStrategy",
+		DisplayName:  "Upgrade Strategy",
+		Description:  "UpgradeStrategy configures how operand upgrades are supervised",
+		XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:advanced"},
+	},
+	{
+		Path:         "rolloutStrategy",
+		DisplayName:  "Rollout Strategy",
+		Description:  "RolloutStrategy configures how changes to the controller pods are rolled out",
+		XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:advanced"},
+	},
+	{
+		Path:         "paused",
+		DisplayName:  "Paused",
+		Description:  "Paused stops the operator from creating, updating and deleting operand resources while status keeps being reported",
+		XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"},
+	},
+	{
+		Path:         "watchNamespaces",
+		DisplayName:  "Watch Namespaces",
+		Description:  "WatchNamespaces restricts the controller to the listed namespaces, together with the ones matching NamespaceSelector",
+		XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:advanced"},
+	},
+	{
+		Path:         "namespaceSelector",
+		DisplayName:  "Namespace Selector",
+		Description:  "NamespaceSelector restricts the controller to the matching namespaces, together with the ones in WatchNamespaces",
+		XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:selector:core:v1:Namespace", "urn:alm:descriptor:com.tectonic.ui:advanced"},
+	},
+	{
+		Path:         "rbac",
+		DisplayName:  "RBAC",
+		Description:  "RBAC configures which tenants may run storage migrations",
+		XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:advanced"},
+	},
+	{
+		Path:         "planPolicies",
+		DisplayName:  "Plan Policies",
+		Description:  "PlanPolicies constrains the storage migration plans tenants can create, enforced with ValidatingAdmissionPolicies",
+		XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:advanced"},
+	},
+	{
+		Path:         "quota",
+		DisplayName:  "Quota",
+		Description:  "Quota limits how many storage migrations, single and multi namespace, may run at the same time, enforced by the operator webhook",
+		XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:advanced"},
+	},
+	{
+		Path:         "planDefaults",
+		DisplayName:  "Plan Defaults",
+		Description:  "PlanDefaults are filled into storage migration plans that leave them unset, unless their namespace sets its own defaults with the DefaultStorageClassAnnotation annotation",
+		XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:advanced"},
+	},
+	{
+		Path:         "history",
+		DisplayName:  "History",
+		Description:  "History configures the garbage collection of finished storage migrations, they are kept forever when unset",
+		XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:advanced"},
+	},
+}
+
+// migControllerStatusDescriptors are generated from the descriptor markers of the MigController API
+var migControllerStatusDescriptors = []csvv1.StatusDescriptor{
+	{
+		Path:         "phase",
+		DisplayName:  "Phase",
+		Description:  "The deployment phase.",
+		XDescriptors: []string{"urn:alm:descriptor:io.kubernetes.phase"},
+	},
+	{
+		Path:         "conditions",
+		DisplayName:  "Conditions",
+		Description:  "Explanation for the current status of the kubevirt migration controller deployment.",
+		XDescriptors: []string{"urn:alm:descriptor:io.kubernetes.conditions"},
+	},
+	{
+		Path:         "operatorVersion",
+		DisplayName:  "kubevirt migration controller Operator Version",
+		Description:  "The version of the kubevirt migration controller Operator",
+		XDescriptors: []string{"urn:alm:descriptor:text"},
+	},
+	{
+		Path:         "targetVersion",
+		DisplayName:  "Target kubevirt migration controller Version",
+		Description:  "The targeted version of the kubevirt migration controller deployment.",
+		XDescriptors: []string{"urn:alm:descriptor:text"},
+	},
+	{
+		Path:         "observedVersion",
+		DisplayName:  "Observed kubevirt migration controller Version",
+		Description:  "The observed version of the kubevirt migration controller deployment.",
+		XDescriptors: []string{"urn:alm:descriptor:text"},
+	},
+	{
+		Path:        "upgradeHistory",
+		DisplayName: "Upgrade History",
+		Description: "UpgradeHistory records the most recent operand upgrades, newest first",
+	},
+	{
+		Path:        "unmanagedResources",
+		DisplayName: "Unmanaged Resources",
+		Description: "UnmanagedResources lists the operand resources that are fully or partially excluded from reconciliation",
+	},
+	{
+		Path:        "drift",
+		DisplayName: "Drift",
+		Description: "Drift lists the changes reconciliation would make to the operand, reported while reconciliation is paused",
+	},
+	{
+		Path:        "watchedNamespaces",
+		DisplayName: "Watched Namespaces",
+		Description: "WatchedNamespaces lists the namespaces the controller is restricted to, empty when it watches all namespaces or, with the NoWatchedNamespaces condition, none",
+	},
+	{
+		Path:        "activePolicies",
+		DisplayName: "Active Policies",
+		Description: "ActivePolicies lists the ValidatingAdmissionPolicies enforcing the plan policies",
+	},
+	{
+		Path:        "quotaUsage",
+		DisplayName: "Quota Usage",
+		Description: "QuotaUsage reports the running storage migrations counted against the quota",
+	},
+}
@@ -24,13 +24,18 @@ import (
 	"github.com/operator-framework/api/pkg/lib/version"
 	csvv1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
 	namespaced "kubevirt.io/kubevirt-migration-operator/pkg/resources/namespaced"
 	utils "kubevirt.io/kubevirt-migration-operator/pkg/resources/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -39,64 +44,78 @@ const (
 	clusterRoleName    = roleName + "-cluster"
 )
 
-// FactoryArgs contains the required parameters to generate all cluster-scoped resources
+// FactoryArgs contains the required parameters to generate the resources installing the operator without OLM
 type FactoryArgs struct {
 	NamespacedArgs namespaced.FactoryArgs
 	Image          string
+	// Rules and ClusterRules are granted to the operator service account, like the CSV permissions
+	Rules        []rbacv1.PolicyRule
+	ClusterRules []rbacv1.PolicyRule
 }
 
-// func createClusterRole() *rbacv1.ClusterRole {
-// 	return utils.ResourceBuilder.CreateOperatorClusterRole(clusterRoleName, getClusterPolicyRules())
-// }
-
-// func createClusterRoleBinding(namespace string) *rbacv1.ClusterRoleBinding {
-// 	return utils.ResourceBuilder.CreateOperatorClusterRoleBinding(serviceAccountName,
-//  clusterRoleName, serviceAccountName, namespace)
-// }
-
-// func createClusterRBAC(args *FactoryArgs) []client.Object {
-// 	return []client.Object{
-// 		createClusterRole(),
-// 		createClusterRoleBinding(args.NamespacedArgs.Namespace),
-// 	}
-// }
-
-// func createServiceAccount(namespace string) *corev1.ServiceAccount {
-// 	return utils.ResourceBuilder.CreateOperatorServiceAccount(serviceAccountName, namespace)
-// }
-
-// func createNamespacedRole(namespace string) *rbacv1.Role {
-// 	role := utils.ResourceBuilder.CreateRole(roleName, getNamespacedPolicyRules())
-// 	role.Namespace = namespace
-// 	return role
-// }
-
-// func createNamespacedRoleBinding(namespace string) *rbacv1.RoleBinding {
-// 	roleBinding := utils.ResourceBuilder.CreateRoleBinding(serviceAccountName, roleName, serviceAccountName, namespace)
-// 	roleBinding.Namespace = namespace
-// 	return roleBinding
-// }
-
-// func createNamespacedRBAC(args *FactoryArgs) []client.Object {
-// 	return []client.Object{
-// 		createServiceAccount(args.NamespacedArgs.Namespace),
-// 		createNamespacedRole(args.NamespacedArgs.Namespace),
-// 		createNamespacedRoleBinding(args.NamespacedArgs.Namespace),
-// 	}
-// }
-
-// func createDeployment(args *FactoryArgs) []client.Object {
-// 	return []client.Object{
-// 		createOperatorDeployment(args.NamespacedArgs.OperatorVersion,
-// 			args.NamespacedArgs.Namespace,
-// 			args.NamespacedArgs.DeployClusterResources,
-// 			args.Image,
-// 			args.NamespacedArgs.ControllerImage,
-// 			args.NamespacedArgs.Verbosity,
-// 			args.NamespacedArgs.PullPolicy,
-// 		),
-// 	}
-// }
+// NewOperatorResources returns the service account, RBAC and deployment OLM creates from the CSV,
+// for clusters installing the operator without OLM
+func NewOperatorResources(args *FactoryArgs) []client.Object {
+	objs := createNamespacedRBAC(args)
+	objs = append(objs, createClusterRBAC(args)...)
+	return append(objs, createDeployment(args)...)
+}
+
+func createClusterRole(rules []rbacv1.PolicyRule) *rbacv1.ClusterRole {
+	return utils.ResourceBuilder.CreateOperatorClusterRole(clusterRoleName, rules)
+}
+
+func createClusterRoleBinding(namespace string) *rbacv1.ClusterRoleBinding {
+	return utils.ResourceBuilder.CreateOperatorClusterRoleBinding(serviceAccountName,
+		clusterRoleName, serviceAccountName, namespace)
+}
+
+func createClusterRBAC(args *FactoryArgs) []client.Object {
+	return []client.Object{
+		createClusterRole(args.ClusterRules),
+		createClusterRoleBinding(args.NamespacedArgs.Namespace),
+	}
+}
+
+func createServiceAccount(namespace string) *corev1.ServiceAccount {
+	return utils.ResourceBuilder.CreateOperatorServiceAccount(serviceAccountName, namespace)
+}
+
+func createNamespacedRole(namespace string, rules []rbacv1.PolicyRule) *rbacv1.Role {
+	role := utils.ResourceBuilder.CreateRole(roleName, rules)
+	role.Namespace = namespace
+	return role
+}
+
+func createNamespacedRoleBinding(namespace string) *rbacv1.RoleBinding {
+	roleBinding := utils.ResourceBuilder.CreateRoleBinding(serviceAccountName, roleName, serviceAccountName, namespace)
+	roleBinding.Namespace = namespace
+	return roleBinding
+}
+
+func createNamespacedRBAC(args *FactoryArgs) []client.Object {
+	return []client.Object{
+		createServiceAccount(args.NamespacedArgs.Namespace),
+		createNamespacedRole(args.NamespacedArgs.Namespace, args.Rules),
+		createNamespacedRoleBinding(args.NamespacedArgs.Namespace),
+	}
+}
+
+func createDeployment(args *FactoryArgs) []client.Object {
+	deployment := createOperatorDeployment(args.NamespacedArgs.OperatorVersion,
+		args.NamespacedArgs.Namespace,
+		args.NamespacedArgs.DeployClusterResources,
+		args.Image,
+		args.NamespacedArgs.ControllerImage,
+		args.NamespacedArgs.Verbosity,
+		args.NamespacedArgs.PullPolicy,
+	)
+	deployment.Spec.Template.Spec.PriorityClassName = args.NamespacedArgs.PriorityClassName
+	// without OLM nobody provides the webhook server certificates
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.Env = append(container.Env, corev1.EnvVar{Name: "ENABLE_WEBHOOKS", Value: "false"})
+	return []client.Object{deployment}
+}
 
 func createOperatorEnvVar(operatorVersion,
 	deployClusterResources,
@@ -134,9 +153,54 @@ func createOperatorEnvVar(operatorVersion,
 			Name:  "OPERATOR_IMAGE",
 			Value: operatorImage,
 		},
+		{
+			Name: common.TargetNamespacesEnv,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: targetNamespacesFieldPath,
+				},
+			},
+		},
 	}
 }
 
+// targetNamespacesFieldPath is the downward API field of the OperatorGroup target namespaces OLM annotates
+// the operator deployment with
+const targetNamespacesFieldPath = "metadata.annotations['" + common.TargetNamespacesAnnotation + "']"
+
+// createInstallModes declares the OLM install modes the operator deployment supports. All namespaces are always
+// supported. A subset of namespaces is supported when the operator reads the OperatorGroup target namespaces
+// in TargetNamespacesEnv, it restricts the operand and its RBAC to them, and no webhook is a conversion webhook,
+// OLM only installs those for all namespaces. OLM scopes the admission webhooks to the target namespaces
+func createInstallModes(deployment *appsv1.Deployment, webhooks []csvv1.WebhookDescription) []csvv1.InstallMode {
+	targetsNamespaces := readsTargetNamespaces(deployment)
+	for _, webhook := range webhooks {
+		if webhook.Type == csvv1.ConversionWebhook {
+			targetsNamespaces = false
+		}
+	}
+
+	return []csvv1.InstallMode{
+		{Type: csvv1.InstallModeTypeOwnNamespace, Supported: targetsNamespaces},
+		{Type: csvv1.InstallModeTypeSingleNamespace, Supported: targetsNamespaces},
+		{Type: csvv1.InstallModeTypeMultiNamespace, Supported: targetsNamespaces},
+		{Type: csvv1.InstallModeTypeAllNamespaces, Supported: true},
+	}
+}
+
+// readsTargetNamespaces checks whether a container of the deployment gets the OperatorGroup target namespaces
+func readsTargetNamespaces(deployment *appsv1.Deployment) bool {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		for _, env := range container.Env {
+			if env.Name == common.TargetNamespacesEnv && env.ValueFrom != nil && env.ValueFrom.FieldRef != nil &&
+				env.ValueFrom.FieldRef.FieldPath == targetNamespacesFieldPath {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func createOperatorDeployment(operatorVersion, namespace, deployClusterResources, operatorImage, controllerImage,
 	verbosity, pullPolicy string) *appsv1.Deployment {
 	deployment := utils.CreateOperatorDeployment("kubevirt-migration-operator", namespace, "name",
@@ -195,14 +259,151 @@ func createOperatorPorts() []corev1.ContainerPort {
 			ContainerPort: 8081,
 			Protocol:      "TCP",
 		},
+		{
+			Name:          "webhook-server",
+			ContainerPort: common.WebhookServerPort,
+			Protocol:      "TCP",
+		},
 	}
 }
 
-//go:embed migControllerExample.json
-var migControllerExample string
+// createWebhookDefinitions declares the webhooks served by the operator, OLM creates the service,
+// certificates and webhook configurations for them
+func createWebhookDefinitions() []csvv1.WebhookDescription {
+	failurePolicy := admissionregistrationv1.Fail
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	return []csvv1.WebhookDescription{
+		{
+			GenerateName:            "vmultinamespacevirtualmachinestoragemigrationplan-v1alpha1.kb.io",
+			Type:                    csvv1.ValidatingAdmissionWebhook,
+			DeploymentName:          "kubevirt-migration-operator",
+			ContainerPort:           443,
+			TargetPort:              ptr.To(intstr.FromInt32(common.WebhookServerPort)),
+			FailurePolicy:           &failurePolicy,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1"},
+			WebhookPath:             ptr.To(common.MultiNamespacePlanValidatePath),
+			Rules: []admissionregistrationv1.RuleWithOperations{
+				{
+					Operations: []admissionregistrationv1.OperationType{
+						admissionregistrationv1.Create,
+						admissionregistrationv1.Update,
+					},
+					Rule: admissionregistrationv1.Rule{
+						APIGroups:   []string{"migrations.kubevirt.io"},
+						APIVersions: []string{"v1alpha1"},
+						Resources:   []string{"multinamespacevirtualmachinestoragemigrationplans"},
+					},
+				},
+			},
+		},
+		{
+			GenerateName:            "vvirtualmachinestoragemigration-v1alpha1.kb.io",
+			Type:                    csvv1.ValidatingAdmissionWebhook,
+			DeploymentName:          "kubevirt-migration-operator",
+			ContainerPort:           443,
+			TargetPort:              ptr.To(intstr.FromInt32(common.WebhookServerPort)),
+			FailurePolicy:           &failurePolicy,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1"},
+			WebhookPath:             ptr.To(common.MigrationQuotaValidatePath),
+			Rules: []admissionregistrationv1.RuleWithOperations{
+				{
+					Operations: []admissionregistrationv1.OperationType{
+						admissionregistrationv1.Create,
+					},
+					Rule: admissionregistrationv1.Rule{
+						APIGroups:   []string{"migrations.kubevirt.io"},
+						APIVersions: []string{"v1alpha1"},
+						Resources: []string{
+							"virtualmachinestoragemigrations",
+							"multinamespacevirtualmachinestoragemigrations",
+						},
+					},
+				},
+			},
+		},
+		{
+			GenerateName:            "vpreflight-v1alpha1.kb.io",
+			Type:                    csvv1.ValidatingAdmissionWebhook,
+			DeploymentName:          "kubevirt-migration-operator",
+			ContainerPort:           443,
+			TargetPort:              ptr.To(intstr.FromInt32(common.WebhookServerPort)),
+			FailurePolicy:           &failurePolicy,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1"},
+			WebhookPath:             ptr.To(common.PlanPreflightValidatePath),
+			Rules: []admissionregistrationv1.RuleWithOperations{
+				{
+					Operations: []admissionregistrationv1.OperationType{
+						admissionregistrationv1.Create,
+						admissionregistrationv1.Update,
+					},
+					Rule: admissionregistrationv1.Rule{
+						APIGroups:   []string{"migrations.kubevirt.io"},
+						APIVersions: []string{"v1alpha1"},
+						Resources: []string{
+							"multinamespacevirtualmachinestoragemigrationplans",
+							"virtualmachinestoragemigrationplans",
+						},
+					},
+				},
+			},
+		},
+		{
+			GenerateName:            "mvirtualmachinestoragemigrationplan-v1alpha1.kb.io",
+			Type:                    csvv1.MutatingAdmissionWebhook,
+			DeploymentName:          "kubevirt-migration-operator",
+			ContainerPort:           443,
+			TargetPort:              ptr.To(intstr.FromInt32(common.WebhookServerPort)),
+			FailurePolicy:           &failurePolicy,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1"},
+			WebhookPath:             ptr.To(common.PlanDefaultsMutatePath),
+			Rules: []admissionregistrationv1.RuleWithOperations{
+				{
+					Operations: []admissionregistrationv1.OperationType{
+						admissionregistrationv1.Create,
+					},
+					Rule: admissionregistrationv1.Rule{
+						APIGroups:   []string{"migrations.kubevirt.io"},
+						APIVersions: []string{"v1alpha1"},
+						Resources: []string{
+							"virtualmachinestoragemigrationplans",
+							"multinamespacevirtualmachinestoragemigrationplans",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+//go:embed almExamples.json
+var almExamples string
+
+// createRelatedImages lists the images the operator deploys, so disconnected installs can mirror them
+func createRelatedImages(data *ClusterServiceVersionData) []csvv1.RelatedImage {
+	var images []csvv1.RelatedImage
+	if data.OperatorImage != "" {
+		images = append(images, csvv1.RelatedImage{Name: "kubevirt-migration-operator", Image: data.OperatorImage})
+	}
+	if data.ControllerImage != "" {
+		images = append(images, csvv1.RelatedImage{Name: "kubevirt-migration-controller", Image: data.ControllerImage})
+	}
+	return images
+}
 
 // nolint
 func createClusterServiceVersion(data *ClusterServiceVersionData) (*csvv1.ClusterServiceVersion, error) {
+	if err := checkDescriptors(); err != nil {
+		return nil, err
+	}
+	ownedCRDs, err := createOwnedCRDDescriptions()
+	if err != nil {
+		return nil, err
+	}
+
 	description := `
 The Kubevirt Migration Controller is an extension that provides extra capabilities capitalizing on kubevirt VM migration methods.
 `
@@ -218,6 +419,7 @@ The Kubevirt Migration Controller is an extension that provides extra capabiliti
 	)
 
 	deployment.Spec.Template.Spec.PriorityClassName = utils.PriorityClassDefault
+	webhooks := createWebhookDefinitions()
 
 	strategySpec := csvv1.StrategyDetailsDeployment{
 		Permissions: []csvv1.StrategyDeploymentPermissions{
@@ -240,21 +442,25 @@ The Kubevirt Migration Controller is an extension that provides extra capabiliti
 		},
 	}
 
+	annotations := map[string]string{
+		"capabilities": "Full Lifecycle",
+		"categories":   "Storage,Virtualization",
+		"alm-examples": almExamples,
+		"description":  "Creates and maintains kubevirt migration controller deployments",
+	}
+	if data.SkipRange != "" {
+		annotations["olm.skipRange"] = data.SkipRange
+	}
+
 	return &csvv1.ClusterServiceVersion{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "ClusterServiceVersion",
 			APIVersion: "operators.coreos.com/v1alpha1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "kubevirtmigrationoperator." + data.CsvVersion,
-			Namespace: data.Namespace,
-			Annotations: map[string]string{
-
-				"capabilities": "Full Lifecycle",
-				"categories":   "Storage,Virtualization",
-				"alm-examples": migControllerExample,
-				"description":  "Creates and maintains kubevirt migration controller deployments",
-			},
+			Name:        "kubevirtmigrationoperator." + data.CsvVersion,
+			Namespace:   data.Namespace,
+			Annotations: annotations,
 		},
 
 		Spec: csvv1.ClusterServiceVersionSpec{
@@ -295,92 +501,26 @@ The Kubevirt Migration Controller is an extension that provides extra capabiliti
 					"operated-by":   "kubevirt-migration-operator",
 				},
 			},
-			InstallModes: []csvv1.InstallMode{
-				{
-					Type:      csvv1.InstallModeTypeOwnNamespace,
-					Supported: true,
-				},
-				{
-					Type:      csvv1.InstallModeTypeSingleNamespace,
-					Supported: true,
-				},
-				{
-					Type:      csvv1.InstallModeTypeMultiNamespace,
-					Supported: true,
-				},
-				{
-					Type:      csvv1.InstallModeTypeAllNamespaces,
-					Supported: true,
-				},
-			},
+			InstallModes:       createInstallModes(deployment, webhooks),
+			WebhookDefinitions: webhooks,
+			RelatedImages:      createRelatedImages(data),
 			InstallStrategy: csvv1.NamedInstallStrategy{
 				StrategyName: "deployment",
 				StrategySpec: strategySpec,
 			},
 			CustomResourceDefinitions: csvv1.CustomResourceDefinitions{
 
-				Owned: []csvv1.CRDDescription{
+				Owned: append([]csvv1.CRDDescription{
 					{
-						Name:        "migcontrollers.migrations.kubevirt.io",
-						Version:     "v1alpha1",
-						Kind:        "MigController",
-						DisplayName: "KubeVirt Migration Controller deployment",
-						Description: "Represents a kubevirt migration controller deployment",
-						SpecDescriptors: []csvv1.SpecDescriptor{
-
-							{
-								Description:  "The ImageRegistry to use for the kubevirt migration controller components.",
-								DisplayName:  "ImageRegistry",
-								Path:         "imageRegistry",
-								XDescriptors: []string{"urn:alm:descriptor:text"},
-							},
-							{
-								Description:  "The ImageTag to use for the kubevirt migration controller components.",
-								DisplayName:  "ImageTag",
-								Path:         "imageTag",
-								XDescriptors: []string{"urn:alm:descriptor:text"},
-							},
-							{
-								Description:  "The ImagePullPolicy to use for the kubevirt migration controller components.",
-								DisplayName:  "ImagePullPolicy",
-								Path:         "imagePullPolicy",
-								XDescriptors: []string{"urn:alm:descriptor:io.kubernetes:imagePullPolicy"},
-							},
-						},
-						StatusDescriptors: []csvv1.StatusDescriptor{
-							{
-								Description:  "The deployment phase.",
-								DisplayName:  "Phase",
-								Path:         "phase",
-								XDescriptors: []string{"urn:alm:descriptor:io.kubernetes.phase"},
-							},
-							{
-								Description:  "Explanation for the current status of the kubevirt migration controller deployment.",
-								DisplayName:  "Conditions",
-								Path:         "conditions",
-								XDescriptors: []string{"urn:alm:descriptor:io.kubernetes.conditions"},
-							},
-							{
-								Description:  "The observed version of the kubevirt migration controller deployment.",
-								DisplayName:  "Observed kubevirt migration controller Version",
-								Path:         "observedVersion",
-								XDescriptors: []string{"urn:alm:descriptor:text"},
-							},
-							{
-								Description:  "The targeted version of the kubevirt migration controller deployment.",
-								DisplayName:  "Target kubevirt migration controller Version",
-								Path:         "targetVersion",
-								XDescriptors: []string{"urn:alm:descriptor:text"},
-							},
-							{
-								Description:  "The version of the kubevirt migration controller Operator",
-								DisplayName:  "kubevirt migration controller Operator Version",
-								Path:         "operatorVersion",
-								XDescriptors: []string{"urn:alm:descriptor:text"},
-							},
-						},
+						Name:              "migcontrollers.migrations.kubevirt.io",
+						Version:           "v1alpha1",
+						Kind:              "MigController",
+						DisplayName:       "KubeVirt Migration Controller deployment",
+						Description:       "Represents a kubevirt migration controller deployment",
+						SpecDescriptors:   migControllerSpecDescriptors,
+						StatusDescriptors: migControllerStatusDescriptors,
 					},
-				},
+				}, ownedCRDs...),
 			},
 		},
 	}, nil
@@ -0,0 +1,82 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	csvv1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"kubevirt.io/kubevirt-migration-operator/pkg/common"
+)
+
+func TestOperator(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Operator Resources Suite")
+}
+
+var _ = Describe("Install modes", func() {
+	var deployment *appsv1.Deployment
+
+	getSupported := func(installModes []csvv1.InstallMode) []csvv1.InstallModeType {
+		supported := []csvv1.InstallModeType{}
+		for _, installMode := range installModes {
+			if installMode.Supported {
+				supported = append(supported, installMode.Type)
+			}
+		}
+		return supported
+	}
+
+	BeforeEach(func() {
+		deployment = createOperatorDeployment("0.0.1", "kubevirt", "true", "operator:latest", "controller:latest",
+			"1", string(corev1.PullIfNotPresent))
+	})
+
+	It("should support every install mode when the operator reads the target namespaces", func() {
+		installModes := createInstallModes(deployment, createWebhookDefinitions())
+		Expect(installModes).To(HaveLen(4))
+		Expect(getSupported(installModes)).To(ConsistOf(
+			csvv1.InstallModeTypeOwnNamespace,
+			csvv1.InstallModeTypeSingleNamespace,
+			csvv1.InstallModeTypeMultiNamespace,
+			csvv1.InstallModeTypeAllNamespaces,
+		))
+	})
+
+	It("should only support all namespaces when the operator does not read the target namespaces", func() {
+		container := &deployment.Spec.Template.Spec.Containers[0]
+		var env []corev1.EnvVar
+		for _, envVar := range container.Env {
+			if envVar.Name != common.TargetNamespacesEnv {
+				env = append(env, envVar)
+			}
+		}
+		container.Env = env
+		Expect(getSupported(createInstallModes(deployment, createWebhookDefinitions()))).
+			To(ConsistOf(csvv1.InstallModeTypeAllNamespaces))
+	})
+
+	It("should only support all namespaces with a conversion webhook", func() {
+		webhooks := append(createWebhookDefinitions(), csvv1.WebhookDescription{Type: csvv1.ConversionWebhook})
+		Expect(getSupported(createInstallModes(deployment, webhooks))).To(ConsistOf(csvv1.InstallModeTypeAllNamespaces))
+	})
+})
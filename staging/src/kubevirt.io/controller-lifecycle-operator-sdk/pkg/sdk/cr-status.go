@@ -10,12 +10,33 @@ import (
 	"kubevirt.io/controller-lifecycle-operator-sdk/api"
 )
 
+const (
+	// ConditionUpgradeFailed is set when an upgrade did not complete within the allotted time
+	ConditionUpgradeFailed v1.ConditionType = "UpgradeFailed"
+
+	// UpgradeTimedOutReason is the UpgradeFailed reason when the new version never became ready
+	UpgradeTimedOutReason = "UpgradeTimedOut"
+	// UpgradeRolledBackReason is the UpgradeFailed reason when the previous version was restored
+	UpgradeRolledBackReason = "UpgradeRolledBack"
+)
+
 // IsUpgrading checks whether cr status represents upgrade in progress
 func IsUpgrading(crStatus *api.Status) bool {
 	deploying := crStatus.Phase == api.PhaseDeploying
 	return (crStatus.ObservedVersion != "" || !deploying) && crStatus.ObservedVersion != crStatus.TargetVersion
 }
 
+// IsUpgradeFailed checks whether cr status represents a failed upgrade
+func IsUpgradeFailed(crStatus *api.Status) bool {
+	return v1.IsStatusConditionTrue(crStatus.Conditions, ConditionUpgradeFailed)
+}
+
+// IsUpgradeRolledBack checks whether cr status represents a failed upgrade that was rolled back
+func IsUpgradeRolledBack(crStatus *api.Status) bool {
+	cond := v1.FindStatusCondition(crStatus.Conditions, ConditionUpgradeFailed)
+	return cond != nil && cond.Status == corev1.ConditionTrue && cond.Reason == UpgradeRolledBackReason
+}
+
 // GetConditionValues gets the conditions and put them into a map for easy comparison
 func GetConditionValues(conditionList []v1.Condition) map[v1.ConditionType]corev1.ConditionStatus {
 	result := make(map[v1.ConditionType]corev1.ConditionStatus)
@@ -153,3 +174,28 @@ func MarkCrDeploying(cr runtime.Object, crStatus *api.Status, reason, message st
 	})
 	recorder.Event(cr, corev1.EventTypeNormal, reason, message)
 }
+
+// MarkCrUpgradeFailed marks the passed CR as failed to upgrade. The CR object needs to be updated by the caller afterwards.
+// UpgradeFailed means the following status conditions are set:
+// UpgradeFailed: true
+// Progressing: false
+// Degraded: true
+func MarkCrUpgradeFailed(cr client.Object, crStatus *api.Status, reason, message string, recorder record.EventRecorder) {
+	v1.SetStatusCondition(&crStatus.Conditions, v1.Condition{
+		Type:    ConditionUpgradeFailed,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	v1.SetStatusCondition(&crStatus.Conditions, v1.Condition{
+		Type:   v1.ConditionProgressing,
+		Status: corev1.ConditionFalse,
+	})
+	v1.SetStatusCondition(&crStatus.Conditions, v1.Condition{
+		Type:    v1.ConditionDegraded,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	recorder.Event(cr, corev1.EventTypeWarning, reason, message)
+}
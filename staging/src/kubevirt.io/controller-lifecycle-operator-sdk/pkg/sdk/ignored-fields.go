@@ -0,0 +1,174 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IsUnmanaged checks whether the object is annotated to be left alone by the reconciler
+func IsUnmanaged(obj metav1.Object, unmanagedAnnotation string) bool {
+	if unmanagedAnnotation == "" {
+		return false
+	}
+	return strings.ToLower(obj.GetAnnotations()[unmanagedAnnotation]) == "true"
+}
+
+// GetIgnoredFields returns the JSON pointers (RFC 6901) listed in the ignored fields annotation of the object.
+// The annotation value is a JSON array, e.g. ["/spec/replicas"]
+func GetIgnoredFields(obj metav1.Object, ignoredFieldsAnnotation string) ([]string, error) {
+	if ignoredFieldsAnnotation == "" {
+		return nil, nil
+	}
+	v, ok := obj.GetAnnotations()[ignoredFieldsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	var pointers []string
+	if err := json.Unmarshal([]byte(v), &pointers); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation; %w", ignoredFieldsAnnotation, err)
+	}
+	for _, pointer := range pointers {
+		if !strings.HasPrefix(pointer, "/") {
+			return nil, fmt.Errorf("invalid %s annotation; %q is not a JSON pointer", ignoredFieldsAnnotation, pointer)
+		}
+	}
+
+	return pointers, nil
+}
+
+// RestoreIgnoredFields copies the fields the JSON pointers refer to from the original object into the merged one,
+// so whatever someone else set there is kept. Fields missing in the original are removed from the merged object
+func RestoreIgnoredFields(original, merged client.Object, pointers []string) (client.Object, error) {
+	originalDoc, err := toJSONDocument(original)
+	if err != nil {
+		return nil, err
+	}
+	mergedDoc, err := toJSONDocument(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pointer := range pointers {
+		tokens := parseJSONPointer(pointer)
+		if value, ok := getJSONPointer(originalDoc, tokens); ok {
+			if err := setJSONPointer(mergedDoc, tokens, value); err != nil {
+				return nil, fmt.Errorf("unable to restore %s; %w", pointer, err)
+			}
+		} else {
+			removeJSONPointer(mergedDoc, tokens)
+		}
+	}
+
+	bytes, err := json.Marshal(mergedDoc)
+	if err != nil {
+		return nil, err
+	}
+	result := NewDefaultInstance(merged)
+	if err = json.Unmarshal(bytes, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func toJSONDocument(obj client.Object) (map[string]interface{}, error) {
+	bytes, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err = json.Unmarshal(bytes, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func parseJSONPointer(pointer string) []string {
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+func getJSONPointer(doc interface{}, tokens []string) (interface{}, bool) {
+	current := doc
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func setJSONPointer(doc map[string]interface{}, tokens []string, value interface{}) error {
+	parent, ok := getJSONPointer(doc, tokens[:len(tokens)-1])
+	if !ok {
+		// create missing parent objects
+		if err := setJSONPointer(doc, tokens[:len(tokens)-1], map[string]interface{}{}); err != nil {
+			return err
+		}
+		parent, _ = getJSONPointer(doc, tokens[:len(tokens)-1])
+	}
+
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+	case []interface{}:
+		i, err := strconv.Atoi(last)
+		if err != nil || i < 0 || i >= len(node) {
+			return fmt.Errorf("index %s out of range", last)
+		}
+		node[i] = value
+	default:
+		return fmt.Errorf("%s is not an object or array", strings.Join(tokens[:len(tokens)-1], "/"))
+	}
+	return nil
+}
+
+func removeJSONPointer(doc map[string]interface{}, tokens []string) {
+	parent, ok := getJSONPointer(doc, tokens[:len(tokens)-1])
+	if !ok {
+		return
+	}
+	// array elements are left in place, removing them would shift the indexes of the others
+	if node, ok := parent.(map[string]interface{}); ok {
+		delete(node, tokens[len(tokens)-1])
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	unmanagedAnnotation     = "unmanaged"
+	ignoredFieldsAnnotation = "ignoredFields"
+)
+
+var _ = Describe("IsUnmanaged", func() {
+	DescribeTable("Should check the unmanaged annotation", func(annotations map[string]string, annotation string, expected bool) {
+		pod := createPod("pod", nil, annotations)
+		Expect(IsUnmanaged(pod, annotation)).To(Equal(expected))
+	},
+		Entry("annotation set", map[string]string{unmanagedAnnotation: "true"}, unmanagedAnnotation, true),
+		Entry("annotation set, different case", map[string]string{unmanagedAnnotation: "True"}, unmanagedAnnotation, true),
+		Entry("annotation false", map[string]string{unmanagedAnnotation: "false"}, unmanagedAnnotation, false),
+		Entry("annotation missing", nil, unmanagedAnnotation, false),
+		Entry("annotation not configured", map[string]string{unmanagedAnnotation: "true"}, "", false),
+	)
+})
+
+var _ = Describe("GetIgnoredFields", func() {
+	It("Should return JSON pointers", func() {
+		pod := createPod("pod", nil, map[string]string{ignoredFieldsAnnotation: `["/spec/replicas","/metadata/labels/a~1b"]`})
+		fields, err := GetIgnoredFields(pod, ignoredFieldsAnnotation)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fields).To(ConsistOf("/spec/replicas", "/metadata/labels/a~1b"))
+	})
+
+	DescribeTable("Should fail on invalid annotation", func(value string) {
+		pod := createPod("pod", nil, map[string]string{ignoredFieldsAnnotation: value})
+		_, err := GetIgnoredFields(pod, ignoredFieldsAnnotation)
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("not a list", "/spec/replicas"),
+		Entry("not a pointer", `["spec.replicas"]`),
+	)
+})
+
+var _ = Describe("RestoreIgnoredFields", func() {
+	createDeployment := func(replicas int32, memory string, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "deployment", Labels: labels},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name: "container",
+							Resources: corev1.ResourceRequirements{
+								Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)},
+							},
+						}},
+					},
+				},
+			},
+		}
+	}
+
+	It("Should keep the original value of ignored fields", func() {
+		original := createDeployment(3, "1Gi", nil)
+		merged := createDeployment(1, "500Mi", map[string]string{"app": "test"})
+
+		result, err := RestoreIgnoredFields(original, merged, []string{"/spec/replicas", "/spec/template/spec/containers/0/resources"})
+		Expect(err).ToNot(HaveOccurred())
+		deployment := result.(*appsv1.Deployment)
+		Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(3))
+		Expect(deployment.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().String()).To(Equal("1Gi"))
+		Expect(deployment.Labels).To(HaveKeyWithValue("app", "test"))
+	})
+
+	It("Should remove ignored fields missing in the original", func() {
+		original := createDeployment(1, "500Mi", nil)
+		merged := createDeployment(1, "500Mi", map[string]string{"app": "test", "a/b": "c"})
+
+		result, err := RestoreIgnoredFields(original, merged, []string{"/metadata/labels/a~1b"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.GetLabels()).To(Equal(map[string]string{"app": "test"}))
+	})
+
+	It("Should add ignored fields missing in the merged object", func() {
+		original := createDeployment(1, "500Mi", map[string]string{"app": "test"})
+		merged := createDeployment(1, "500Mi", nil)
+
+		result, err := RestoreIgnoredFields(original, merged, []string{"/metadata/labels/app"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.GetLabels()).To(Equal(map[string]string{"app": "test"}))
+	})
+})
@@ -32,6 +32,10 @@ func NewReconciler(crManager CrManager, log logr.Logger, client client.Client, c
 		checkSanity:                   checkSanity,
 		watch:                         watch,
 		preCreate:                     preCreate,
+		getUpgradeTimeout:             getUpgradeTimeout,
+		handleUpgradeFailure:          handleUpgradeFailure,
+		isRolloutPending:              isRolloutPending,
+		filterDesiredResources:        filterDesiredResources,
 		subresourceEnabled:            subresourceEnabled,
 	}
 }
@@ -87,6 +91,42 @@ func (r *Reconciler) WithPreCreateHook(preCreate PreCreateHook) *Reconciler {
 	return r
 }
 
+// WithUpgradeTimeoutGetter sets UpgradeTimeoutGetter
+func (r *Reconciler) WithUpgradeTimeoutGetter(getUpgradeTimeout UpgradeTimeoutGetter) *Reconciler {
+	r.getUpgradeTimeout = getUpgradeTimeout
+	return r
+}
+
+// WithUpgradeFailureHandler sets UpgradeFailureHandler
+func (r *Reconciler) WithUpgradeFailureHandler(handleUpgradeFailure UpgradeFailureHandler) *Reconciler {
+	r.handleUpgradeFailure = handleUpgradeFailure
+	return r
+}
+
+// WithRolloutPendingChecker sets RolloutPendingChecker
+func (r *Reconciler) WithRolloutPendingChecker(isRolloutPending RolloutPendingChecker) *Reconciler {
+	r.isRolloutPending = isRolloutPending
+	return r
+}
+
+// WithDesiredResourcesFilter sets DesiredResourcesFilter
+func (r *Reconciler) WithDesiredResourcesFilter(filterDesiredResources DesiredResourcesFilter) *Reconciler {
+	r.filterDesiredResources = filterDesiredResources
+	return r
+}
+
+// WithUnmanagedAnnotation sets the annotation that excludes a managed resource from reconciliation when set to "true"
+func (r *Reconciler) WithUnmanagedAnnotation(annotation string) *Reconciler {
+	r.unmanagedAnnotation = annotation
+	return r
+}
+
+// WithIgnoredFieldsAnnotation sets the annotation listing JSON pointers to fields of a managed resource that are not reconciled
+func (r *Reconciler) WithIgnoredFieldsAnnotation(annotation string) *Reconciler {
+	r.ignoredFieldsAnnotation = annotation
+	return r
+}
+
 func preCreate(_ client.Object) error {
 	return nil
 }
@@ -106,3 +146,19 @@ func updateControllerConfiguration(_ client.Object) error {
 func syncPerishables(cr client.Object, logger logr.Logger) error {
 	return nil
 }
+
+func getUpgradeTimeout(_ client.Object) time.Duration {
+	return 0
+}
+
+func handleUpgradeFailure(_ client.Object, _ logr.Logger) (bool, error) {
+	return false, nil
+}
+
+func isRolloutPending(_ client.Object) bool {
+	return false
+}
+
+func filterDesiredResources(_ client.Object, resources []client.Object) ([]client.Object, error) {
+	return resources, nil
+}
@@ -0,0 +1,122 @@
+package reconciler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+// DriftOperation is the change reconciliation would make to a managed resource
+type DriftOperation string
+
+const (
+	// DriftCreate means the resource is missing and would be created
+	DriftCreate DriftOperation = "Create"
+	// DriftUpdate means the resource differs from its desired state and would be updated
+	DriftUpdate DriftOperation = "Update"
+	// DriftDelete means the resource is no longer desired and would be deleted on upgrade
+	DriftDelete DriftOperation = "Delete"
+)
+
+// ResourceDrift describes a change reconciliation would make to a managed resource
+type ResourceDrift struct {
+	// Object is the desired resource for creates, the current one otherwise
+	Object client.Object
+	// Operation is the change that would be made
+	Operation DriftOperation
+	// ChangedPaths are JSON pointers to the fields that would change on update
+	ChangedPaths []string
+}
+
+// DryRun computes the changes reconciliation would make to the managed resources without applying them.
+// Unused resources are only deleted on completing an upgrade, so they are reported while an upgrade to
+// operatorVersion is in progress or about to start
+func (r *Reconciler) DryRun(logger logr.Logger, cr client.Object, operatorVersion string) ([]ResourceDrift, error) {
+	var result []ResourceDrift
+
+	resources, err := r.crManager.GetAllResources(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, desiredObj := range resources {
+		currentObj := sdk.NewDefaultInstance(desiredObj)
+		if err = r.client.Get(context.TODO(), client.ObjectKeyFromObject(desiredObj), currentObj); err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, err
+			}
+			result = append(result, ResourceDrift{Object: desiredObj, Operation: DriftCreate})
+			continue
+		}
+
+		if sdk.IsUnmanaged(currentObj, r.unmanagedAnnotation) {
+			continue
+		}
+
+		ignoredFields, err := sdk.GetIgnoredFields(currentObj, r.ignoredFieldsAnnotation)
+		if err != nil {
+			return nil, err
+		}
+		currentObjCopy, mergedObj, err := r.mergeDesiredObject(cr, desiredObj, currentObj, ignoredFields)
+		if err != nil {
+			return nil, err
+		}
+		paths, err := sdk.GetJSONDiffPaths(currentObjCopy, mergedObj)
+		if err != nil {
+			return nil, err
+		}
+		paths = r.withoutLastAppliedConfiguration(paths)
+		if len(paths) > 0 {
+			result = append(result, ResourceDrift{Object: currentObjCopy, Operation: DriftUpdate, ChangedPaths: paths})
+		}
+	}
+
+	upgrading, err := r.isUpgradePending(cr, operatorVersion)
+	if err != nil {
+		return nil, err
+	}
+	if upgrading {
+		unusedResources, err := r.getUnusedResources(logger, cr)
+		if err != nil {
+			return nil, err
+		}
+		for _, unusedObj := range unusedResources {
+			result = append(result, ResourceDrift{Object: unusedObj, Operation: DriftDelete})
+		}
+	}
+
+	logger.V(3).Info("Dry run finished", "drift", len(result))
+	return result, nil
+}
+
+// isUpgradePending tells whether reconciliation would complete an upgrade to operatorVersion, in which case
+// the unused resources get deleted
+func (r *Reconciler) isUpgradePending(cr client.Object, operatorVersion string) (bool, error) {
+	status := r.status(cr)
+	if sdk.IsUpgrading(status) {
+		return !sdk.IsUpgradeRolledBack(status), nil
+	}
+	if sdk.IsUpgradeFailed(status) {
+		return false, nil
+	}
+	return ShouldTakeUpdatePath(operatorVersion, status.ObservedVersion, status.Phase == sdkapi.PhaseDeploying)
+}
+
+// withoutLastAppliedConfiguration drops the last applied configuration annotation, it changes along with any other field
+func (r *Reconciler) withoutLastAppliedConfiguration(paths []string) []string {
+	annotationPath := "/metadata/annotations/" + strings.ReplaceAll(strings.ReplaceAll(r.lastAppliedConfigAnnotation, "~", "~0"), "/", "~1")
+
+	var result []string
+	for _, path := range paths {
+		if path != annotationPath {
+			result = append(result, path)
+		}
+	}
+	return result
+}
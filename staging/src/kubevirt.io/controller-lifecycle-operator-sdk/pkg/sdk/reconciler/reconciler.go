@@ -59,6 +59,21 @@ type WatchRegistrator func() error
 // PreCreateHook is expected to perform custom actions before the creation of the managed resources is initiated
 type PreCreateHook func(cr client.Object) error
 
+// UpgradeTimeoutGetter is expected to return how long an upgrade may take before it is considered failed, zero disables the check
+type UpgradeTimeoutGetter func(cr client.Object) time.Duration
+
+// UpgradeFailureHandler is expected to restore the last known good state of the managed resources when an upgrade fails.
+// It returns true if the managed resources were rolled back
+type UpgradeFailureHandler func(cr client.Object, logger logr.Logger) (bool, error)
+
+// RolloutPendingChecker is expected to report whether changes to the managed resources are being held back.
+// An upgrade is not completed while they are
+type RolloutPendingChecker func(cr client.Object) bool
+
+// DesiredResourcesFilter is expected to adjust the managed resources right before they are applied, e.g. to hold back
+// some of the changes. It is not involved when the managed resources are only inspected
+type DesiredResourcesFilter func(cr client.Object, resources []client.Object) ([]client.Object, error)
+
 // CrManager defines interface that needs to be provided for the reconciler to operate
 type CrManager interface {
 	// IsCreating checks whether creation of the managed resources will be executed
@@ -112,6 +127,22 @@ type Reconciler struct {
 	checkSanity                   SanityChecker
 	watch                         WatchRegistrator
 	preCreate                     PreCreateHook
+	getUpgradeTimeout             UpgradeTimeoutGetter
+	handleUpgradeFailure          UpgradeFailureHandler
+	isRolloutPending              RolloutPendingChecker
+	filterDesiredResources        DesiredResourcesFilter
+	unmanagedAnnotation           string
+	ignoredFieldsAnnotation       string
+}
+
+// UnmanagedResource describes a managed resource that is fully or partially left alone by the reconciler
+type UnmanagedResource struct {
+	// Object is the resource as found in the cluster
+	Object client.Object
+	// Unmanaged is true when the resource is not reconciled at all
+	Unmanaged bool
+	// IgnoredFields are JSON pointers to the fields of the resource that are not reconciled
+	IgnoredFields []string
 }
 
 // Reconcile performs request reconciliation
@@ -210,6 +241,10 @@ func (r *Reconciler) ReconcileUpdate(logger logr.Logger, cr client.Object, opera
 	if err != nil {
 		return reconcile.Result{}, err
 	}
+	resources, err = r.filterDesiredResources(cr, resources)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 
 	var allErrors []error
 	for _, desiredObj := range resources {
@@ -230,7 +265,7 @@ func (r *Reconciler) ReconcileUpdate(logger logr.Logger, cr client.Object, opera
 			sdk.SetLabel(r.createVersionLabel, operatorVersion, desiredObj)
 			r.setRecommendedLabels(cr, desiredObj)
 
-			if desiredObj.GetNamespace() != "" {
+			if desiredObj.GetNamespace() != "" && r.canOwn(cr, desiredObj) {
 				if err = controllerutil.SetControllerReference(cr, desiredObj, r.scheme); err != nil {
 					r.recorder.Event(cr, corev1.EventTypeWarning, createResourceFailed, fmt.Sprintf("Failed to create resource %s, %v", desiredObj.GetName(), err))
 					return reconcile.Result{}, err
@@ -263,31 +298,31 @@ func (r *Reconciler) ReconcileUpdate(logger logr.Logger, cr client.Object, opera
 				"type", fmt.Sprintf("%T", desiredObj))
 			r.recorder.Event(cr, corev1.EventTypeNormal, createResourceSuccess, fmt.Sprintf("Successfully created resource %T %s", desiredObj, desiredObj.GetName()))
 		} else {
+			if sdk.IsUnmanaged(currentObj, r.unmanagedAnnotation) {
+				logger.V(3).Info("Resource unmanaged",
+					"namespace", desiredObj.GetNamespace(),
+					"name", desiredObj.GetName(),
+					"type", fmt.Sprintf("%T", desiredObj))
+				continue
+			}
+
 			// POST_READ callback
 			if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStatePostRead, desiredObj, currentObj, r.recorder); err != nil {
 				return reconcile.Result{}, err
 			}
 
-			currentObj, err = sdk.StripStatusFromObject(currentObj)
+			// keep the fields owned by someone else, a resource with an invalid list is skipped
+			ignoredFields, err := sdk.GetIgnoredFields(currentObj, r.ignoredFieldsAnnotation)
 			if err != nil {
-				return reconcile.Result{}, err
+				logger.Error(err, "", "name", desiredObj.GetName())
+				allErrors = append(allErrors, err)
+				r.recorder.Event(cr, corev1.EventTypeWarning, updateResourceFailed, fmt.Sprintf("Failed to update resource %s, %v", desiredObj.GetName(), err))
+				continue
 			}
-			currentObjCopy := currentObj.DeepCopyObject().(client.Object)
-
-			// allow users to add new annotations (but not change ours)
-			sdk.MergeLabelsAndAnnotations(desiredObj, currentObj)
 
-			// recommended label values can change by installer, set on update as well
-			r.setRecommendedLabels(cr, currentObj)
-
-			if !sdk.IsMutable(currentObj) {
-				r.setLastAppliedConfiguration(desiredObj)
-
-				// overwrite currentRuntimeObj
-				currentObj, err = sdk.MergeObject(desiredObj, currentObj, r.lastAppliedConfigAnnotation)
-				if err != nil {
-					return reconcile.Result{}, err
-				}
+			currentObjCopy, currentObj, err := r.mergeDesiredObject(cr, desiredObj, currentObj, ignoredFields)
+			if err != nil {
+				return reconcile.Result{}, err
 			}
 
 			if !reflect.DeepEqual(currentObjCopy, currentObj) {
@@ -341,6 +376,10 @@ func (r *Reconciler) ReconcileUpdate(logger logr.Logger, cr client.Object, opera
 	}
 
 	status := r.status(cr)
+	if degraded && sdk.IsUpgrading(status) && !sdk.IsUpgradeFailed(status) && r.upgradeTimedOut(cr, status) {
+		return r.failUpgrade(logger, cr)
+	}
+
 	if status.Phase != sdkapi.PhaseDeployed && !sdk.IsUpgrading(status) && !degraded {
 		//We are not moving to Deployed phase until new operator deployment is ready in case of Upgrade
 		status.ObservedVersion = operatorVersion
@@ -352,7 +391,7 @@ func (r *Reconciler) ReconcileUpdate(logger logr.Logger, cr client.Object, opera
 		logger.Info("Successfully entered Deployed state")
 	}
 
-	if !degraded && sdk.IsUpgrading(status) {
+	if !degraded && sdk.IsUpgrading(status) && !sdk.IsUpgradeRolledBack(status) && !r.isRolloutPending(cr) {
 		logger.Info("Completing upgrade process...")
 
 		if err = r.completeUpgrade(logger, cr, operatorVersion); err != nil {
@@ -363,6 +402,42 @@ func (r *Reconciler) ReconcileUpdate(logger logr.Logger, cr client.Object, opera
 	return reconcile.Result{RequeueAfter: r.perishablesSyncInterval}, nil
 }
 
+// mergeDesiredObject merges the desired state of a managed resource into its current state, leaving the ignored fields alone.
+// It returns the current state stripped of status along with the merge result
+func (r *Reconciler) mergeDesiredObject(cr, desiredObj, currentObj client.Object, ignoredFields []string) (client.Object, client.Object, error) {
+	currentObj, err := sdk.StripStatusFromObject(currentObj)
+	if err != nil {
+		return nil, nil, err
+	}
+	currentObjCopy := currentObj.DeepCopyObject().(client.Object)
+
+	// allow users to add new annotations (but not change ours)
+	sdk.MergeLabelsAndAnnotations(desiredObj, currentObj)
+
+	// recommended label values can change by installer, set on update as well
+	r.setRecommendedLabels(cr, currentObj)
+
+	if !sdk.IsMutable(currentObj) {
+		r.setLastAppliedConfiguration(desiredObj)
+
+		// overwrite currentRuntimeObj
+		currentObj, err = sdk.MergeObject(desiredObj, currentObj, r.lastAppliedConfigAnnotation)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// keep the fields owned by someone else
+		if len(ignoredFields) > 0 {
+			currentObj, err = sdk.RestoreIgnoredFields(currentObjCopy, currentObj, ignoredFields)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return currentObjCopy, currentObj, nil
+}
+
 // CheckForOrphans checks whether there are any orphaned resources (ones that exist in the cluster but shouldn't)
 func (r *Reconciler) CheckForOrphans(logger logr.Logger, cr client.Object) (bool, error) {
 	resources, err := r.crManager.GetAllResources(cr)
@@ -543,6 +618,41 @@ func (r *Reconciler) GetAllDeployments(cr client.Object) ([]*appsv1.Deployment,
 	return result, nil
 }
 
+// GetUnmanagedResources returns the managed resources that are annotated to be fully or partially left alone
+func (r *Reconciler) GetUnmanagedResources(cr client.Object) ([]UnmanagedResource, error) {
+	var result []UnmanagedResource
+
+	resources, err := r.crManager.GetAllResources(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resource := range resources {
+		currentObj := sdk.NewDefaultInstance(resource)
+		if err = r.client.Get(context.TODO(), client.ObjectKeyFromObject(resource), currentObj); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		unmanaged := sdk.IsUnmanaged(currentObj, r.unmanagedAnnotation)
+		ignoredFields, err := sdk.GetIgnoredFields(currentObj, r.ignoredFieldsAnnotation)
+		if err != nil {
+			return nil, err
+		}
+		if unmanaged || len(ignoredFields) > 0 {
+			result = append(result, UnmanagedResource{
+				Object:        currentObj,
+				Unmanaged:     unmanaged,
+				IgnoredFields: ignoredFields,
+			})
+		}
+	}
+
+	return result, nil
+}
+
 // WatchCR registers watch for the managed CR
 func (r *Reconciler) WatchCR() error {
 	// Watch for changes to managed CR
@@ -596,6 +706,8 @@ func (r *Reconciler) CheckUpgrade(logger logr.Logger, cr client.Object, targetVe
 	if status.OperatorVersion != targetVersion {
 		status.OperatorVersion = targetVersion
 		status.TargetVersion = targetVersion
+		// a new version gets a fresh upgrade attempt
+		conditions.RemoveStatusCondition(&status.Conditions, sdk.ConditionUpgradeFailed)
 		if err := r.CrUpdateStatus(status.Phase, cr); err != nil {
 			return err
 		}
@@ -608,7 +720,7 @@ func (r *Reconciler) CheckUpgrade(logger logr.Logger, cr client.Object, targetVe
 		return err
 	}
 
-	if isUpgrade && status.Phase != sdkapi.PhaseUpgrading {
+	if isUpgrade && status.Phase != sdkapi.PhaseUpgrading && !sdk.IsUpgradeFailed(status) {
 		logger.Info("Observed version is not target version. Begin upgrade", "Observed version ", status.ObservedVersion, "TargetVersion", targetVersion)
 		sdk.MarkCrUpgradeHealingDegraded(cr, status, "UpgradeStarted", fmt.Sprintf("Started upgrade to version %s", targetVersion), r.recorder)
 		status.TargetVersion = targetVersion
@@ -625,17 +737,54 @@ func (r *Reconciler) CleanupUnusedResources(logger logr.Logger, cr client.Object
 	//Iterate over installed resources of
 	//Deployment/CRDs/Services etc and delete all resources that
 	//do not exist in current version
+	unusedResources, err := r.getUnusedResources(logger, cr)
+	if err != nil {
+		return err
+	}
+
+	for _, observedObj := range unusedResources {
+		observedMetaObj := observedObj.(metav1.Object)
+
+		//Invoke pre delete callback
+		if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStatePreDelete, nil, observedObj, r.recorder); err != nil {
+			r.recorder.Event(cr, corev1.EventTypeWarning, deleteResourceFailed, fmt.Sprintf("Failed deleting resource %s, %v", observedMetaObj.GetName(), err))
+			return err
+		}
+
+		logger.Info("Deleting  ", "type", reflect.TypeOf(observedObj), "Name", observedMetaObj.GetName())
+		err = r.client.Delete(context.TODO(), observedObj, &client.DeleteOptions{
+			PropagationPolicy: &[]metav1.DeletionPropagation{metav1.DeletePropagationForeground}[0],
+		})
+		if err != nil && !errors.IsNotFound(err) {
+			r.recorder.Event(cr, corev1.EventTypeWarning, deleteResourceFailed, fmt.Sprintf("Failed deleting resource %s, %v", observedMetaObj.GetName(), err))
+			return err
+		}
+
+		//invoke post delete callback
+		if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStatePostDelete, nil, observedObj, r.recorder); err != nil {
+			r.recorder.Event(cr, corev1.EventTypeWarning, deleteResourceFailed, fmt.Sprintf("Failed deleting resource %s, %v", observedMetaObj.GetName(), err))
+			return err
+		}
+		r.recorder.Event(cr, corev1.EventTypeNormal, deleteResourceSuccess, fmt.Sprintf("Successfully deleted resource %T %s", observedMetaObj, observedMetaObj.GetName()))
+	}
+
+	return nil
+}
+
+// getUnusedResources returns the installed resources that are no longer desired and would be removed on cleanup
+func (r *Reconciler) getUnusedResources(logger logr.Logger, cr client.Object) ([]client.Object, error) {
+	var result []client.Object
 
 	desiredResources, err := r.crManager.GetAllResources(cr)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	listTypes := r.crManager.GetDependantResourcesListObjects()
 
 	ls, err := labels.Parse(r.createVersionLabel)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, lt := range listTypes {
@@ -643,7 +792,7 @@ func (r *Reconciler) CleanupUnusedResources(logger logr.Logger, cr client.Object
 
 		if err := r.client.List(context.TODO(), lt, lo); err != nil {
 			logger.Error(err, "Error listing resources")
-			return err
+			return nil, err
 		}
 
 		sv := reflect.ValueOf(lt).Elem()
@@ -661,33 +810,18 @@ func (r *Reconciler) CleanupUnusedResources(logger logr.Logger, cr client.Object
 				}
 			}
 
-			if !found && (metav1.IsControlledBy(observedMetaObj, cr) || observedMetaObj.GetNamespace() == "") {
-				//Invoke pre delete callback
-				if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStatePreDelete, nil, observedObj, r.recorder); err != nil {
-					r.recorder.Event(cr, corev1.EventTypeWarning, deleteResourceFailed, fmt.Sprintf("Failed deleting resource %s, %v", observedMetaObj.GetName(), err))
-					return err
-				}
-
-				logger.Info("Deleting  ", "type", reflect.TypeOf(observedObj), "Name", observedMetaObj.GetName())
-				err = r.client.Delete(context.TODO(), observedObj, &client.DeleteOptions{
-					PropagationPolicy: &[]metav1.DeletionPropagation{metav1.DeletePropagationForeground}[0],
-				})
-				if err != nil && !errors.IsNotFound(err) {
-					r.recorder.Event(cr, corev1.EventTypeWarning, deleteResourceFailed, fmt.Sprintf("Failed deleting resource %s, %v", observedMetaObj.GetName(), err))
-					return err
-				}
+			if !found && sdk.IsUnmanaged(observedMetaObj, r.unmanagedAnnotation) {
+				logger.Info("Keeping unmanaged resource", "type", reflect.TypeOf(observedObj), "Name", observedMetaObj.GetName())
+				continue
+			}
 
-				//invoke post delete callback
-				if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStatePostDelete, nil, observedObj, r.recorder); err != nil {
-					r.recorder.Event(cr, corev1.EventTypeWarning, deleteResourceFailed, fmt.Sprintf("Failed deleting resource %s, %v", observedMetaObj.GetName(), err))
-					return err
-				}
-				r.recorder.Event(cr, corev1.EventTypeNormal, deleteResourceSuccess, fmt.Sprintf("Successfully deleted resource %T %s", observedMetaObj, observedMetaObj.GetName()))
+			if !found && (metav1.IsControlledBy(observedMetaObj, cr) || observedMetaObj.GetNamespace() == "" || !r.canOwn(cr, observedObj)) {
+				result = append(result, observedObj)
 			}
 		}
 	}
 
-	return nil
+	return result, nil
 }
 
 // ReconcileDelete executes Delete operation
@@ -751,6 +885,12 @@ func (r *Reconciler) CrInit(cr client.Object, operatorVersion string) error {
 	return r.CrUpdate(cr)
 }
 
+// canOwn checks whether the CR can be set as owner of the object, owner references across namespaces are not allowed
+// so such objects are tracked by their labels only
+func (r *Reconciler) canOwn(cr, obj client.Object) bool {
+	return !r.namespacedCR || cr.GetNamespace() == obj.GetNamespace()
+}
+
 // GetCr retrieves the CR
 func (r *Reconciler) GetCr(name types.NamespacedName) (client.Object, error) {
 	cr := r.crManager.Create()
@@ -781,6 +921,7 @@ func (r *Reconciler) completeUpgrade(logger logr.Logger, cr client.Object, opera
 	status := r.status(cr)
 	previousVersion := status.ObservedVersion
 	status.ObservedVersion = operatorVersion
+	conditions.RemoveStatusCondition(&status.Conditions, sdk.ConditionUpgradeFailed)
 
 	sdk.MarkCrHealthyMessage(cr, status, "DeployCompleted", "Deployment Completed", r.recorder)
 	if err := r.CrUpdateStatus(sdkapi.PhaseDeployed, cr); err != nil {
@@ -792,6 +933,52 @@ func (r *Reconciler) completeUpgrade(logger logr.Logger, cr client.Object, opera
 	return nil
 }
 
+// upgradeTimedOut checks whether the upgrade in progress has exceeded the configured timeout
+func (r *Reconciler) upgradeTimedOut(cr client.Object, status *sdkapi.Status) bool {
+	timeout := r.getUpgradeTimeout(cr)
+	if timeout <= 0 {
+		return false
+	}
+
+	// Progressing turns true when the upgrade starts and stays so until it completes
+	progressing := conditions.FindStatusCondition(status.Conditions, conditions.ConditionProgressing)
+	if progressing == nil || progressing.Status != corev1.ConditionTrue {
+		return false
+	}
+
+	return time.Since(progressing.LastTransitionTime.Time) > timeout
+}
+
+func (r *Reconciler) failUpgrade(logger logr.Logger, cr client.Object) (reconcile.Result, error) {
+	status := r.status(cr)
+	logger.Info("Upgrade did not complete in time", "Observed version", status.ObservedVersion, "TargetVersion", status.TargetVersion)
+
+	rolledBack, err := r.handleUpgradeFailure(cr, logger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// without a rollback the upgrade may still complete once the new version becomes ready
+	phase := sdkapi.PhaseUpgrading
+	reason := sdk.UpgradeTimedOutReason
+	message := fmt.Sprintf("Upgrade to version %s did not complete in time", status.TargetVersion)
+	if rolledBack {
+		phase = sdkapi.PhaseError
+		reason = sdk.UpgradeRolledBackReason
+		message = fmt.Sprintf("Upgrade to version %s did not complete in time, rolled back to version %s", status.TargetVersion, status.ObservedVersion)
+	}
+
+	sdk.MarkCrUpgradeFailed(cr, status, reason, message, r.recorder)
+	if err = r.CrUpdateStatus(phase, cr); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	logger.Info("Upgrade failed", "reason", reason, "rolled back", rolledBack)
+
+	// requeue right away so the restored resources get applied
+	return reconcile.Result{Requeue: true}, nil
+}
+
 func (r *Reconciler) setRecommendedLabels(cr client.Object, obj metav1.Object) {
 	labels := sdk.GetRecommendedLabelsFromCr(cr)
 
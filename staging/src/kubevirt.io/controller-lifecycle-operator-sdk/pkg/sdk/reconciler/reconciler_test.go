@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -211,6 +212,243 @@ var _ = Describe("Reconciler", func() {
 		})
 	})
 
+	Describe("Unmanaged resources", func() {
+		const (
+			unmanagedAnnotation     = "unmanaged"
+			ignoredFieldsAnnotation = "ignored-fields"
+		)
+
+		modifyOperatorDeployment := func(args *args, modify func(deployment *appsv1.Deployment)) {
+			deployment := &appsv1.Deployment{}
+			key := client.ObjectKey{Namespace: testcr.Namespace, Name: testcr.OperatorDeploymentName}
+			Expect(args.client.Get(context.TODO(), key, deployment)).To(Succeed())
+			modify(deployment)
+			Expect(args.client.Update(context.TODO(), deployment)).To(Succeed())
+		}
+
+		getOperatorDeployment := func(args *args) *appsv1.Deployment {
+			deployment := &appsv1.Deployment{}
+			key := client.ObjectKey{Namespace: testcr.Namespace, Name: testcr.OperatorDeploymentName}
+			Expect(args.client.Get(context.TODO(), key, deployment)).To(Succeed())
+			return deployment
+		}
+
+		debugEnv := corev1.EnvVar{Name: "DEBUG", Value: "true"}
+
+		It("should not reconcile unmanaged resource", func() {
+			args := createArgs(version)
+			args.reconciler.WithUnmanagedAnnotation(unmanagedAnnotation)
+			doReconcile(args)
+
+			modifyOperatorDeployment(args, func(deployment *appsv1.Deployment) {
+				deployment.Annotations[unmanagedAnnotation] = "true"
+				containers := deployment.Spec.Template.Spec.Containers
+				containers[0].Env = append(containers[0].Env, debugEnv)
+			})
+			doReconcile(args)
+
+			deployment := getOperatorDeployment(args)
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ContainElement(debugEnv))
+
+			unmanaged, err := args.reconciler.GetUnmanagedResources(args.config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unmanaged).To(HaveLen(1))
+			Expect(unmanaged[0].Object.GetName()).To(Equal(testcr.OperatorDeploymentName))
+			Expect(unmanaged[0].Unmanaged).To(BeTrue())
+			Expect(unmanaged[0].IgnoredFields).To(BeEmpty())
+		})
+
+		It("should keep ignored fields", func() {
+			args := createArgs(version)
+			args.reconciler.WithIgnoredFieldsAnnotation(ignoredFieldsAnnotation)
+			doReconcile(args)
+
+			modifyOperatorDeployment(args, func(deployment *appsv1.Deployment) {
+				deployment.Annotations[ignoredFieldsAnnotation] = `["/spec/replicas"]`
+				replicas := int32(3)
+				deployment.Spec.Replicas = &replicas
+				containers := deployment.Spec.Template.Spec.Containers
+				containers[0].Env = append(containers[0].Env, debugEnv)
+			})
+			doReconcile(args)
+
+			deployment := getOperatorDeployment(args)
+			Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(3))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).ToNot(ContainElement(debugEnv))
+
+			unmanaged, err := args.reconciler.GetUnmanagedResources(args.config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unmanaged).To(HaveLen(1))
+			Expect(unmanaged[0].Unmanaged).To(BeFalse())
+			Expect(unmanaged[0].IgnoredFields).To(ConsistOf("/spec/replicas"))
+		})
+
+		It("should fail on invalid ignored fields", func() {
+			args := createArgs(version)
+			args.reconciler.WithIgnoredFieldsAnnotation(ignoredFieldsAnnotation)
+			doReconcile(args)
+
+			modifyOperatorDeployment(args, func(deployment *appsv1.Deployment) {
+				deployment.Annotations[ignoredFieldsAnnotation] = "spec.replicas"
+			})
+			doReconcileError(args)
+		})
+	})
+
+	Describe("Reconcile errors", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testcr.Namespace, Name: "after-the-deployment"},
+		}
+		withConfigMap := func(_ client.Object, resources []client.Object) ([]client.Object, error) {
+			return append(resources, configMap.DeepCopy()), nil
+		}
+
+		It("should abort when a resource cannot be merged", func() {
+			args := createArgs(version)
+			doReconcile(args)
+
+			deployment := &appsv1.Deployment{}
+			key := client.ObjectKey{Namespace: testcr.Namespace, Name: testcr.OperatorDeploymentName}
+			Expect(args.client.Get(context.TODO(), key, deployment)).To(Succeed())
+			deployment.Annotations["last-applied-config"] = "{"
+			Expect(args.client.Update(context.TODO(), deployment)).To(Succeed())
+
+			args.reconciler.WithDesiredResourcesFilter(withConfigMap)
+			_, err := args.reconciler.Reconcile(reconcileRequest(args.config.Name), args.version, log)
+			Expect(err).To(HaveOccurred())
+
+			_, err = getObject(args.client, configMap)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should skip a resource with invalid ignored fields and apply the others", func() {
+			args := createArgs(version)
+			args.reconciler.WithIgnoredFieldsAnnotation("ignored-fields")
+			doReconcile(args)
+
+			deployment := &appsv1.Deployment{}
+			key := client.ObjectKey{Namespace: testcr.Namespace, Name: testcr.OperatorDeploymentName}
+			Expect(args.client.Get(context.TODO(), key, deployment)).To(Succeed())
+			deployment.Annotations["ignored-fields"] = "spec.replicas"
+			Expect(args.client.Update(context.TODO(), deployment)).To(Succeed())
+
+			args.reconciler.WithDesiredResourcesFilter(withConfigMap)
+			_, err := args.reconciler.Reconcile(reconcileRequest(args.config.Name), args.version, log)
+			Expect(err).To(MatchError("reconcile encountered 1 errors"))
+
+			_, err = getObject(args.client, configMap)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Describe("Dry run", func() {
+		It("should report resources to create", func() {
+			args := createArgs(version)
+
+			drift, err := args.reconciler.DryRun(log, args.config, args.version)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drift).To(HaveLen(len(getAllResources(args.config))))
+			for _, d := range drift {
+				Expect(d.Operation).To(Equal(reconciler.DriftCreate))
+				_, err := getObject(args.client, d.Object)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			}
+		})
+
+		It("should report no drift once reconciled", func() {
+			args := createArgs(version)
+			doReconcile(args)
+
+			drift, err := args.reconciler.DryRun(log, args.config, args.version)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drift).To(BeEmpty())
+		})
+
+		It("should report changed paths without updating", func() {
+			args := createArgs(version)
+			doReconcile(args)
+
+			deployment := &appsv1.Deployment{}
+			key := client.ObjectKey{Namespace: testcr.Namespace, Name: testcr.OperatorDeploymentName}
+			Expect(args.client.Get(context.TODO(), key, deployment)).To(Succeed())
+			deployment.Spec.Template.Spec.Containers[0].Env = nil
+			Expect(args.client.Update(context.TODO(), deployment)).To(Succeed())
+
+			drift, err := args.reconciler.DryRun(log, args.config, args.version)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drift).To(HaveLen(1))
+			Expect(drift[0].Operation).To(Equal(reconciler.DriftUpdate))
+			Expect(drift[0].Object.GetName()).To(Equal(testcr.OperatorDeploymentName))
+			Expect(drift[0].ChangedPaths).To(ConsistOf("/spec/template/spec/containers/0/env"))
+
+			Expect(args.client.Get(context.TODO(), key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+		})
+
+		It("should report unused resources only when an upgrade is pending", func() {
+			args := createArgs(version)
+			doReconcile(args)
+			setDeploymentsReady(args)
+			doReconcile(args)
+			Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+
+			unusedObj := testcr.ResourceBuilder.CreateDeployment("fake-deployment", testcr.Namespace, "match-key", "match-value", "", int32(1), corev1.PodSpec{}, nil)
+			unusedObj.SetLabels(map[string]string{createVersionLabel: version})
+			Expect(controllerutil.SetControllerReference(args.config, unusedObj, scheme.Scheme)).To(Succeed())
+			Expect(args.client.Create(context.TODO(), unusedObj)).To(Succeed())
+
+			drift, err := args.reconciler.DryRun(log, args.config, version)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drift).To(BeEmpty())
+
+			drift, err = args.reconciler.DryRun(log, args.config, "v1.6.0")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drift).To(HaveLen(1))
+			Expect(drift[0].Operation).To(Equal(reconciler.DriftDelete))
+			Expect(drift[0].Object.GetName()).To(Equal("fake-deployment"))
+		})
+	})
+
+	Describe("Desired resources filter", func() {
+		It("should only be involved when applying resources", func() {
+			args := createArgs(version)
+			args.reconciler.WithDesiredResourcesFilter(func(_ client.Object, resources []client.Object) ([]client.Object, error) {
+				for _, resource := range resources {
+					if deployment, ok := resource.(*appsv1.Deployment); ok {
+						deployment.Spec.Template.Spec.Containers[0].Env = nil
+					}
+				}
+				return resources, nil
+			})
+			doReconcile(args)
+
+			deployment := &appsv1.Deployment{}
+			key := client.ObjectKey{Namespace: testcr.Namespace, Name: testcr.OperatorDeploymentName}
+			Expect(args.client.Get(context.TODO(), key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+
+			drift, err := args.reconciler.DryRun(log, args.config, args.version)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drift).To(HaveLen(1))
+			Expect(drift[0].Object.GetName()).To(Equal(testcr.OperatorDeploymentName))
+			Expect(drift[0].ChangedPaths).To(ConsistOf("/spec/template/spec/containers/0/env"))
+		})
+
+		It("should abort reconciliation when failing", func() {
+			args := createArgs(version)
+			args.reconciler.WithDesiredResourcesFilter(func(_ client.Object, _ []client.Object) ([]client.Object, error) {
+				return nil, fmt.Errorf("filter failed")
+			})
+
+			_, err := args.reconciler.Reconcile(reconcileRequest(args.config.Name), args.version, log)
+			Expect(err).To(MatchError("filter failed"))
+
+			deployment := &appsv1.Deployment{}
+			key := client.ObjectKey{Namespace: testcr.Namespace, Name: testcr.OperatorDeploymentName}
+			Expect(errors.IsNotFound(args.client.Get(context.TODO(), key, deployment))).To(BeTrue())
+		})
+	})
+
 	Describe("Upgrading operator", func() {
 		DescribeTable("should upgrade", func(prevVersion, newVersion string) {
 			args := createArgs(prevVersion)
@@ -306,6 +544,77 @@ var _ = Describe("Reconciler", func() {
 			Entry("decreasing  semver no prefix", "1.10.0", "1.9.5"),
 		)
 
+		DescribeTable("should fail upgrade that does not complete in time", func(rollback bool, expectedPhase sdkapi.Phase, expectedReason string) {
+			args := createArgs("v1.9.5")
+			doReconcile(args)
+
+			setDeploymentsReady(args)
+			Expect(args.config.Status.Phase).Should(Equal(sdkapi.PhaseDeployed))
+
+			timeout := time.Hour
+			handlerCalls := 0
+			args.reconciler.
+				WithUpgradeTimeoutGetter(func(_ client.Object) time.Duration {
+					return timeout
+				}).
+				WithUpgradeFailureHandler(func(_ client.Object, _ logr.Logger) (bool, error) {
+					handlerCalls++
+					return rollback, nil
+				})
+
+			setDeploymentsDegraded(args)
+			args.version = "v1.10.0"
+			doReconcile(args)
+			Expect(args.config.Status.Phase).Should(Equal(sdkapi.PhaseUpgrading))
+			Expect(handlerCalls).To(Equal(0))
+
+			timeout = time.Nanosecond
+			result, err := args.reconciler.Reconcile(reconcileRequest(args.config.Name), args.version, log)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+			args.config, err = getConfig(args.client, args.config)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(handlerCalls).To(Equal(1))
+			Expect(args.config.Status.Phase).Should(Equal(expectedPhase))
+			Expect(args.config.Status.ObservedVersion).Should(Equal("v1.9.5"))
+			Expect(sdk.IsUpgradeFailed(&args.config.Status.Status)).To(BeTrue())
+			Expect(sdk.IsUpgradeRolledBack(&args.config.Status.Status)).To(Equal(rollback))
+			condition := v1.FindStatusCondition(args.config.Status.Conditions, sdk.ConditionUpgradeFailed)
+			Expect(condition.Reason).To(Equal(expectedReason))
+
+			// a failed upgrade is not retried for the same version
+			doReconcile(args)
+			Expect(handlerCalls).To(Equal(1))
+			Expect(args.config.Status.Phase).Should(Equal(expectedPhase))
+		},
+			Entry("without rollback", false, sdkapi.PhaseUpgrading, sdk.UpgradeTimedOutReason),
+			Entry("with rollback", true, sdkapi.PhaseError, sdk.UpgradeRolledBackReason),
+		)
+
+		It("should not complete upgrade while rollout is pending", func() {
+			args := createArgs("v1.9.5")
+			doReconcile(args)
+
+			setDeploymentsReady(args)
+			Expect(args.config.Status.Phase).Should(Equal(sdkapi.PhaseDeployed))
+
+			pending := true
+			args.reconciler.WithRolloutPendingChecker(func(_ client.Object) bool {
+				return pending
+			})
+
+			args.version = "v1.10.0"
+			doReconcile(args)
+			Expect(args.config.Status.Phase).Should(Equal(sdkapi.PhaseUpgrading))
+			Expect(args.config.Status.ObservedVersion).Should(Equal("v1.9.5"))
+
+			pending = false
+			doReconcile(args)
+			Expect(args.config.Status.Phase).Should(Equal(sdkapi.PhaseDeployed))
+			Expect(args.config.Status.ObservedVersion).Should(Equal("v1.10.0"))
+		})
+
 	})
 
 	DescribeTable("Restores objects on upgrade", func(modify modifyResource, tomodify isModifySubject, upgraded isUpgraded) {
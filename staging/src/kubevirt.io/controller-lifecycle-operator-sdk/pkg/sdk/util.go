@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"os"
 	"reflect"
+	"sort"
 	"strings"
 
 	jsondiff "github.com/appscode/jsonpatch"
@@ -156,6 +157,30 @@ func LogJSONDiff(logger logr.Logger, objA, objB interface{}) {
 	logger.Info("DIFF", "obj", objA, "patch", string(pBytes))
 }
 
+// GetJSONDiffPaths returns the sorted JSON pointers of the fields that differ between the two objects
+func GetJSONDiffPaths(objA, objB interface{}) ([]string, error) {
+	aBytes, err := json.Marshal(objA)
+	if err != nil {
+		return nil, err
+	}
+	bBytes, err := json.Marshal(objB)
+	if err != nil {
+		return nil, err
+	}
+	patches, err := jsondiff.CreatePatch(aBytes, bBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(patches))
+	for _, patch := range patches {
+		paths = append(paths, patch.Path)
+	}
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
 func CheckDeploymentReady(deployment *appsv1.Deployment) bool {
 	desiredReplicas := deployment.Spec.Replicas
 	if desiredReplicas == nil {
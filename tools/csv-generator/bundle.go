@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/operator-framework/api/pkg/manifests"
+	csvv1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/api/pkg/validation"
+
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources"
+)
+
+const (
+	bundleManifestsDir = "manifests"
+	bundleMetadataDir  = "metadata"
+
+	bundleAnnotationPrefix = "operators.operatorframework.io.bundle."
+)
+
+// bundleDependency is an entry of metadata/dependencies.yaml
+type bundleDependency struct {
+	Type  string            `json:"type"`
+	Value map[string]string `json:"value"`
+}
+
+// kubeVirtDependency requires the KubeVirt API, the migrations are run by virt-controller
+var kubeVirtDependency = bundleDependency{
+	Type: "olm.gvk",
+	Value: map[string]string{
+		"group":   "kubevirt.io",
+		"version": "v1",
+		"kind":    "KubeVirt",
+	},
+}
+
+// writeBundle writes a registry+v1 OLM bundle holding the CSV and the CRDs it owns to dir,
+// and validates it the same way operator-sdk bundle validate does
+func writeBundle(dir string, csv *csvv1.ClusterServiceVersion) error {
+	for _, subDir := range []string{bundleManifestsDir, bundleMetadataDir} {
+		if err := os.MkdirAll(filepath.Join(dir, subDir), 0755); err != nil {
+			return err
+		}
+	}
+
+	csvBuf := &bytes.Buffer{}
+	if err := marshallObject(csv, csvBuf); err != nil {
+		return err
+	}
+	files := map[string][]byte{
+		filepath.Join(bundleManifestsDir, *packageName+".clusterserviceversion.yaml"):   csvBuf.Bytes(),
+		filepath.Join(bundleManifestsDir, "migrations.kubevirt.io_migcontrollers.yaml"): migControllersCRD,
+	}
+
+	// the storage migration CRDs are owned by the CSV, so OLM requires them in the bundle too.
+	// The operator detects it is installed by OLM and leaves creating and removing them to OLM
+	crds, err := resources.NewCRDs()
+	if err != nil {
+		return err
+	}
+	for _, crd := range crds {
+		crdBuf := &bytes.Buffer{}
+		if err = marshallObject(crd, crdBuf); err != nil {
+			return err
+		}
+		files[filepath.Join(bundleManifestsDir, fmt.Sprintf("%s_%s.yaml", crd.Spec.Group, crd.Spec.Names.Plural))] = crdBuf.Bytes()
+	}
+
+	annotations, err := yaml.Marshal(map[string]map[string]string{"annotations": getBundleAnnotations()})
+	if err != nil {
+		return err
+	}
+	files[filepath.Join(bundleMetadataDir, "annotations.yaml")] = annotations
+
+	dependencies, err := yaml.Marshal(map[string][]bundleDependency{"dependencies": {kubeVirtDependency}})
+	if err != nil {
+		return err
+	}
+	files[filepath.Join(bundleMetadataDir, "dependencies.yaml")] = dependencies
+
+	for name, content := range files {
+		if err = os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			return err
+		}
+	}
+
+	return validateBundle(dir)
+}
+
+func getBundleAnnotations() map[string]string {
+	annotations := map[string]string{
+		bundleAnnotationPrefix + "mediatype.v1": "registry+v1",
+		bundleAnnotationPrefix + "manifests.v1": bundleManifestsDir + "/",
+		bundleAnnotationPrefix + "metadata.v1":  bundleMetadataDir + "/",
+		bundleAnnotationPrefix + "package.v1":   *packageName,
+		bundleAnnotationPrefix + "channels.v1":  *channels,
+	}
+	if *defaultChannel != "" {
+		annotations[bundleAnnotationPrefix+"channel.default.v1"] = *defaultChannel
+	}
+	return annotations
+}
+
+// validateBundle fails on any validation error, warnings are only reported
+func validateBundle(dir string) error {
+	bundle, err := manifests.GetBundleFromDir(dir)
+	if err != nil {
+		return fmt.Errorf("unable to load bundle %s; %w", dir, err)
+	}
+
+	var errs []string
+	for _, result := range validation.DefaultBundleValidators.Validate(bundle.ObjectsToValidate()...) {
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s: %s\n", result.Name, warning.Error())
+		}
+		for _, e := range result.Errors {
+			errs = append(errs, fmt.Sprintf("%s: %s", result.Name, e.Error()))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid bundle %s:\n%s", dir, strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
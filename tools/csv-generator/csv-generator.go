@@ -48,6 +48,15 @@ var (
 		"optional - dumps migration operator related crd manifests to stdout")
 	dumpNetworkPolicies = flag.Bool("dump-network-policies", false,
 		"optional - dumps migration operator related network policies")
+
+	skipRange = flag.String("skip-range", "", "optional - olm.skipRange of the CSV, e.g. '>=0.1.0 <0.2.0'")
+	bundleDir = flag.String("bundle-dir", "",
+		"optional - writes a validated OLM bundle directory instead of printing the CSV to stdout")
+	packageName    = flag.String("package", "kubevirt-migration-operator", "package name of the OLM bundle")
+	channels       = flag.String("channels", "alpha", "comma separated channels of the OLM bundle")
+	defaultChannel = flag.String("default-channel", "", "optional - default channel of the OLM bundle")
+	helmChartDir   = flag.String("helm-chart-dir", "",
+		"optional - writes a helm chart installing the operator without OLM instead of printing the CSV to stdout")
 )
 
 //go:embed assets/migrations.kubevirt.io_migcontrollers.yaml
@@ -64,6 +73,7 @@ func main() {
 	data := operator.ClusterServiceVersionData{
 		CsvVersion:         *csvVersion,
 		ReplacesCsvVersion: *replacesCsvVersion,
+		SkipRange:          *skipRange,
 		Namespace:          *namespace,
 		ImagePullPolicy:    *pullPolicy,
 		IconBase64:         *logoBase64,
@@ -77,10 +87,25 @@ func main() {
 		ClusterRules:    clusterRules,
 	}
 
+	if *helmChartDir != "" {
+		if err = writeHelmChart(*helmChartDir, &data); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	csv, err := operator.NewClusterServiceVersion(&data)
 	if err != nil {
 		panic(err)
 	}
+
+	if *bundleDir != "" {
+		if err = writeBundle(*bundleDir, csv); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	if err = marshallObject(csv, os.Stdout); err != nil {
 		panic(err)
 	}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/kubevirt-migration-operator/pkg/resources/namespaced"
+	operator "kubevirt.io/kubevirt-migration-operator/pkg/resources/operator"
+)
+
+const helmChartName = "kubevirt-migration-operator"
+
+// helmValues are the chart values, the defaults come from the flags of the generator
+const helmValues = `# operatorImage is the image of the operator deployment
+operatorImage: %q
+# controllerImage is the image of the kubevirt migration controller deployed by the operator
+controllerImage: %q
+# pullPolicy of the operator and controller images
+pullPolicy: %q
+# verbosity of the operator and controller logs
+verbosity: %q
+# priorityClassName of the operator pod
+priorityClassName: ""
+
+migController:
+  # create a MigController, the operator deploys the controller once it exists
+  create: true
+  name: migcontroller
+  # spec of the MigController, see the MigController CRD for the fields
+  spec:
+    imagePullPolicy: %q
+`
+
+const helmChart = `apiVersion: v2
+name: %s
+description: Deploys the KubeVirt migration operator on clusters without OLM
+type: application
+version: %s
+appVersion: %q
+`
+
+const helmMigController = `{{- if .Values.migController.create }}
+---
+apiVersion: migrations.kubevirt.io/v1alpha1
+kind: MigController
+metadata:
+  name: {{ .Values.migController.name }}
+  namespace: {{ .Release.Namespace }}
+spec:
+  {{- toYaml .Values.migController.spec | nindent 2 }}
+{{- end }}
+`
+
+// writeHelmChart writes a chart installing the operator from the same factories as the CSV to dir,
+// the objects are rendered with template placeholders that marshallObject leaves unquoted
+func writeHelmChart(dir string, data *operator.ClusterServiceVersionData) error {
+	for _, subDir := range []string{"crds", "templates"} {
+		if err := os.MkdirAll(filepath.Join(dir, subDir), 0755); err != nil {
+			return err
+		}
+	}
+
+	files := map[string][]byte{
+		"Chart.yaml": []byte(fmt.Sprintf(helmChart, helmChartName, data.CsvVersion, data.OperatorVersion)),
+		"values.yaml": []byte(fmt.Sprintf(helmValues, data.OperatorImage, data.ControllerImage,
+			data.ImagePullPolicy, data.Verbosity, data.ImagePullPolicy)),
+		filepath.Join("crds", "migrations.kubevirt.io_migcontrollers.yaml"): migControllersCRD,
+		filepath.Join("templates", "migcontroller.yaml"):                    []byte(helmMigController),
+	}
+
+	objs := operator.NewOperatorResources(&operator.FactoryArgs{
+		NamespacedArgs: namespaced.FactoryArgs{
+			OperatorVersion:        "{{ .Chart.AppVersion | quote }}",
+			ControllerImage:        "{{ .Values.controllerImage | quote }}",
+			DeployClusterResources: "true",
+			Verbosity:              "{{ .Values.verbosity | quote }}",
+			PullPolicy:             "{{ .Values.pullPolicy }}",
+			PriorityClassName:      "{{ .Values.priorityClassName | quote }}",
+			Namespace:              "{{ .Release.Namespace }}",
+		},
+		Image:        "{{ .Values.operatorImage | quote }}",
+		Rules:        data.Rules,
+		ClusterRules: data.ClusterRules,
+	})
+	for _, obj := range objs {
+		buf := &bytes.Buffer{}
+		if err := marshallObject(obj, buf); err != nil {
+			return err
+		}
+		files[filepath.Join("templates", getTemplateName(obj))] = buf.Bytes()
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getTemplateName(obj client.Object) string {
+	return strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind) + ".yaml"
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2025 The KubeVirt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// markerPrefix is the operator-sdk marker declaring the CSV descriptor of a field, e.g.
+// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Paused",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+// path and description default to the JSON name and the doc comment of the field, fields embedded
+// inline have to set both in a marker per field of the embedded type
+const markerPrefix = "+operator-sdk:csv:customresourcedefinitions:"
+
+// descriptorTypes maps the API types to the kind of descriptors their fields are rendered to
+var descriptorTypes = []struct {
+	typeName       string
+	descriptorType string
+}{
+	{typeName: "MigControllerSpec", descriptorType: "spec"},
+	{typeName: "MigControllerStatus", descriptorType: "status"},
+}
+
+type descriptor struct {
+	path         string
+	displayName  string
+	description  string
+	xDescriptors []string
+}
+
+func main() {
+	apiDir := flag.String("apiDir", "api/v1alpha1", "path to the directory with the MigController API types")
+	outputDir := flag.String("outputDir", "pkg/resources/operator/", "path to dir where go file will be generated")
+
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, *apiDir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		panic(fmt.Errorf("error occurred parsing %s, %v", *apiDir, err))
+	}
+
+	structs := map[string]*ast.StructType{}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				if spec, ok := n.(*ast.TypeSpec); ok {
+					if st, ok := spec.Type.(*ast.StructType); ok {
+						structs[spec.Name.Name] = st
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	descriptors := map[string][]descriptor{}
+	var errs []string
+	for _, t := range descriptorTypes {
+		st, ok := structs[t.typeName]
+		if !ok {
+			panic(fmt.Errorf("type %s not found in %s", t.typeName, *apiDir))
+		}
+		for _, field := range st.Fields.List {
+			fieldDescriptors, err := getFieldDescriptors(field, t.descriptorType)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", t.typeName, err))
+				continue
+			}
+			descriptors[t.descriptorType] = append(descriptors[t.descriptorType], fieldDescriptors...)
+		}
+	}
+	if len(errs) > 0 {
+		fmt.Fprintln(os.Stderr, strings.Join(errs, "\n"))
+		os.Exit(1)
+	}
+
+	generateGoFile(*outputDir, descriptors)
+}
+
+// getFieldDescriptors returns the descriptors the markers of the field declare, a field without any fails
+func getFieldDescriptors(field *ast.Field, descriptorType string) ([]descriptor, error) {
+	jsonName, inline := getJSONName(field)
+	if jsonName == "-" {
+		return nil, nil
+	}
+	name := jsonName
+	if inline {
+		name = fmt.Sprintf("%s (inline)", exprString(field.Type))
+	}
+
+	var docLines []string
+	var descriptors []descriptor
+	if field.Doc != nil {
+		for _, comment := range field.Doc.List {
+			line := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+			if !strings.HasPrefix(line, "+") {
+				docLines = append(docLines, line)
+				continue
+			}
+			if !strings.HasPrefix(line, markerPrefix) {
+				continue
+			}
+			args, err := parseMarker(strings.TrimPrefix(line, markerPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", name, err)
+			}
+			if args["type"] != descriptorType {
+				return nil, fmt.Errorf("field %s: marker type %q, expected %q", name, args["type"], descriptorType)
+			}
+			d := descriptor{
+				path:        args["path"],
+				displayName: args["displayName"],
+				description: args["description"],
+			}
+			if xDescriptors, ok := args["xDescriptors"]; ok {
+				d.xDescriptors = strings.Split(xDescriptors, "\n")
+			}
+			descriptors = append(descriptors, d)
+		}
+	}
+
+	if len(descriptors) == 0 {
+		return nil, fmt.Errorf("field %s has no %s descriptor marker", name, markerPrefix)
+	}
+	for i := range descriptors {
+		d := &descriptors[i]
+		if d.path == "" {
+			if inline {
+				return nil, fmt.Errorf("field %s: markers of inline fields need a path", name)
+			}
+			d.path = jsonName
+		}
+		if d.description == "" {
+			d.description = strings.Join(docLines, " ")
+		}
+		if d.displayName == "" || d.description == "" {
+			return nil, fmt.Errorf("field %s: descriptor %s needs a displayName and a description", name, d.path)
+		}
+	}
+
+	return descriptors, nil
+}
+
+func getJSONName(field *ast.Field) (string, bool) {
+	tag := ""
+	if field.Tag != nil {
+		tag, _ = strconv.Unquote(field.Tag.Value)
+	}
+	jsonTag := reflect.StructTag(tag).Get("json")
+	name, opts, _ := strings.Cut(jsonTag, ",")
+	if name == "" && len(field.Names) == 0 {
+		return "", true
+	}
+	if name == "" && len(field.Names) > 0 {
+		name = field.Names[0].Name
+	}
+	return name, opts == "inline"
+}
+
+// parseMarker parses the comma separated key=value arguments of a marker, values are either
+// quoted strings, lists of quoted strings in braces or bare words. List values are joined by newlines
+func parseMarker(marker string) (map[string]string, error) {
+	args := map[string]string{}
+	for _, arg := range splitOutsideQuotes(marker, ',') {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid marker argument %q", arg)
+		}
+		switch {
+		case strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}"):
+			var items []string
+			for _, item := range splitOutsideQuotes(value[1:len(value)-1], ',') {
+				unquoted, err := strconv.Unquote(item)
+				if err != nil {
+					return nil, fmt.Errorf("invalid marker argument %s; %v", key, err)
+				}
+				items = append(items, unquoted)
+			}
+			value = strings.Join(items, "\n")
+		case strings.HasPrefix(value, `"`):
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid marker argument %s; %v", key, err)
+			}
+			value = unquoted
+		}
+		args[key] = value
+	}
+	return args, nil
+}
+
+func splitOutsideQuotes(s string, sep rune) []string {
+	var parts []string
+	var current strings.Builder
+	quoted, depth := false, 0
+	for i, r := range s {
+		switch {
+		case r == '"' && (i == 0 || s[i-1] != '\\'):
+			quoted = !quoted
+		case !quoted && r == '{':
+			depth++
+		case !quoted && r == '}':
+			depth--
+		case !quoted && depth == 0 && r == sep:
+			parts = append(parts, strings.TrimSpace(current.String()))
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts
+}
+
+func exprString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(e.X)
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+func generateGoFile(outputDir string, descriptors map[string][]descriptor) {
+	buf := &bytes.Buffer{}
+	// Label the file as generated so that linters/formatters don't change it.
+	// https://pkg.go.dev/cmd/go#hdr-Generate_Go_files_by_processing_source
+	buf.WriteString("// Code generated by descriptor-generator.go. DO NOT EDIT.\n\n")
+	buf.WriteString("package operator\n\n")
+	buf.WriteString("import csvv1 \"github.com/operator-framework/api/pkg/operators/v1alpha1\"\n\n")
+	writeDescriptors(buf, "migControllerSpecDescriptors", "SpecDescriptor", descriptors["spec"])
+	writeDescriptors(buf, "migControllerStatusDescriptors", "StatusDescriptor", descriptors["status"])
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		panic(fmt.Errorf("failed to format generated descriptors, %v", err))
+	}
+	filePath := filepath.Join(outputDir, "descriptors_generated.go")
+	if err = os.WriteFile(filePath, src, 0644); err != nil {
+		panic(fmt.Errorf("failed to create go file %v, %v", filePath, err))
+	}
+	fmt.Printf("output file: %s\n", filePath)
+}
+
+func writeDescriptors(buf *bytes.Buffer, variable, kind string, descriptors []descriptor) {
+	fmt.Fprintf(buf, "// %s are generated from the descriptor markers of the MigController API\n", variable)
+	fmt.Fprintf(buf, "var %s = []csvv1.%s{\n", variable, kind)
+	for _, d := range descriptors {
+		fmt.Fprintf(buf, "{\nPath: %q,\nDisplayName: %q,\nDescription: %q,\n", d.path, d.displayName, d.description)
+		if len(d.xDescriptors) > 0 {
+			fmt.Fprintf(buf, "XDescriptors: %#v,\n", d.xDescriptors)
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n\n")
+}